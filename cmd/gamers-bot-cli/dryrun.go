@@ -0,0 +1,285 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/gamers-bot/internal/app"
+	"github.com/gamers-bot/internal/bot"
+	"github.com/gamers-bot/internal/bot/discordtest"
+	"github.com/gamers-bot/internal/config"
+	"github.com/gamers-bot/internal/metrics"
+	"github.com/gamers-bot/internal/rabbitmq"
+	"github.com/gamers-bot/internal/rabbitmq/rabbitmqtest"
+	"github.com/gamers-bot/internal/store"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Outcomes of a dry run
+const (
+	outcomeProcessed    = "processed"
+	outcomeDeadLettered = "dead-lettered"
+	outcomeTimedOut     = "timed out"
+)
+
+// dryRunResult is what the handlers did with an event
+type dryRunResult struct {
+	EventType       string                           `json:"event_type"`
+	EventID         string                           `json:"event_id"`
+	Destination     string                           `json:"destination"`
+	Outcome         string                           `json:"outcome"`
+	DeadLetter      *deadLetter                      `json:"dead_letter,omitempty"`
+	Responses       []json.RawMessage                `json:"responses,omitempty"`
+	Messages        []renderedMessage                `json:"messages"`
+	DirectMessages  []renderedMessage                `json:"direct_messages,omitempty"`
+	Moves           []memberMove                     `json:"moves,omitempty"`
+	ScheduledEvents []*discordgo.GuildScheduledEvent `json:"scheduled_events,omitempty"`
+}
+
+// deadLetter describes why an event was dead-lettered
+type deadLetter struct {
+	Queue            string          `json:"queue"`
+	Reason           string          `json:"reason,omitempty"`
+	ValidationReport json.RawMessage `json:"validation_report,omitempty"`
+}
+
+// renderedMessage is a message the bot sent or edited
+type renderedMessage struct {
+	ChannelID  string                       `json:"channel_id,omitempty"`
+	UserID     string                       `json:"user_id,omitempty"`
+	Content    string                       `json:"content,omitempty"`
+	Embeds     []*discordgo.MessageEmbed    `json:"embeds,omitempty"`
+	Components []discordgo.MessageComponent `json:"components,omitempty"`
+	Pinned     bool                         `json:"pinned,omitempty"`
+	Edited     bool                         `json:"edited,omitempty"`
+}
+
+// memberMove is a member moved between voice channels
+type memberMove struct {
+	GuildID   string `json:"guild_id"`
+	UserID    string `json:"user_id"`
+	ChannelID string `json:"channel_id,omitempty"`
+}
+
+// runDryRun runs an event through the real consumer, validation and handlers against an
+// in-memory broker and Discord API, and prints what the bot would have sent
+func runDryRun(ctx context.Context, cfg *config.Config, args []string) error {
+	fs := newFlagSet("dry-run", "(-file path | -template event-type) [flags]")
+	var events eventFlags
+	events.register(fs)
+	var destinations destinationFlags
+	destinations.register(fs)
+	timeout := fs.Duration("timeout", 10*time.Second, "how long to wait for the handlers")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	event, err := events.load()
+	if err != nil {
+		return err
+	}
+	dest, err := destinations.resolve(cfg, eventTypeOf(event))
+	if err != nil {
+		return err
+	}
+
+	// Failures are dead-lettered right away instead of waiting in the delay queues
+	dryRunCfg := *cfg
+	dryRunCfg.RabbitMQRetryMaxAttempts = 0
+	dryRunCfg.RabbitMQHAEnabled = false
+
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+	result, err := dryRun(ctx, &dryRunCfg, event, dest)
+	if err != nil {
+		return err
+	}
+	return printJSON(result)
+}
+
+// dryRun publishes event to dest on an in-memory broker consumed by a bot session, waits until
+// every consumed queue is drained and returns the result
+func dryRun(ctx context.Context, cfg *config.Config, event map[string]interface{}, dest destination) (*dryRunResult, error) {
+	fake := discordtest.New()
+	fake.AddGuild(newDryRunGuild(event))
+	discordBot := bot.NewWithAPI(fake)
+	if cfg.DiscordOrganizerRoleID != "" {
+		discordBot.SetOrganizerRoleID(cfg.DiscordOrganizerRoleID)
+	}
+	if err := discordBot.Connect(); err != nil {
+		return nil, err
+	}
+
+	eventHandlers := app.ApplyMiddlewares(cfg, metrics.New(), app.NewEventHandlers(cfg, store.NewMemoryGameMessageStore()))
+	topology, err := app.LoadTopology(cfg, eventHandlers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load topology: %w", err)
+	}
+	schemas, err := app.NewSchemaRegistry(cfg, eventHandlers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load payload schemas: %w", err)
+	}
+
+	broker := rabbitmqtest.NewBroker()
+	conn, err := broker.Dial(cfg.RabbitMQURL)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	status := rabbitmq.NewStatusTracker()
+	sessionCtx, stopSession := context.WithCancel(ctx)
+	sessionDone := make(chan struct{})
+	var sessionErr error
+	go func() {
+		defer close(sessionDone)
+		sessionErr = app.RunRabbitMQSession(sessionCtx, conn, cfg, discordBot, nil, eventHandlers, topology, schemas, nil, status)
+	}()
+	defer func() {
+		stopSession()
+		<-sessionDone
+	}()
+
+	// waitUntil polls cond until it holds. It fails if ctx is done or the session ends first.
+	waitUntil := func(cond func() bool) error {
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+		for !cond() {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-sessionDone:
+				if sessionErr != nil {
+					return sessionErr
+				}
+				return errors.New("bot session ended")
+			case <-ticker.C:
+			}
+		}
+		return nil
+	}
+
+	if err := waitUntil(func() bool { return status.Status().ConsumersRunning() }); err != nil {
+		return nil, fmt.Errorf("bot session did not start: %w", err)
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event: %w", err)
+	}
+	publisher, err := newConfirmPublisher(conn)
+	if err != nil {
+		return nil, err
+	}
+	defer publisher.Close()
+	if err := publisher.Publish(ctx, dest, newPublishing(event, body)); err != nil {
+		return nil, err
+	}
+
+	queues := []string{cfg.RabbitMQRequestQueue}
+	for _, q := range topology.Queues {
+		queues = append(queues, q.QueueName)
+	}
+	drained := func() bool {
+		for _, q := range queues {
+			if len(broker.Messages(q)) > 0 || broker.Unacked(q) > 0 {
+				return false
+			}
+		}
+		return true
+	}
+
+	result := dryRunResult{
+		EventType:   eventTypeOf(event),
+		EventID:     stringField(event, "event_id"),
+		Destination: dest.String(),
+		Outcome:     outcomeProcessed,
+	}
+	if err := waitUntil(drained); err != nil {
+		if !errors.Is(err, context.DeadlineExceeded) {
+			return nil, err
+		}
+		result.Outcome = outcomeTimedOut
+	}
+
+	for _, q := range queues {
+		dlqName := rabbitmq.DeadLetterQueueName(q)
+		if messages := broker.Messages(dlqName); len(messages) > 0 {
+			result.Outcome = outcomeDeadLettered
+			result.DeadLetter = newDeadLetter(dlqName, messages[0])
+		}
+	}
+	for _, m := range broker.Messages(cfg.RabbitMQResponseQueue) {
+		result.Responses = append(result.Responses, json.RawMessage(m.Body))
+	}
+	result.Messages = renderMessages(fake.Messages())
+	result.DirectMessages = renderMessages(fake.DirectMessages())
+	for _, move := range fake.Moves() {
+		result.Moves = append(result.Moves, memberMove{GuildID: move.GuildID, UserID: move.UserID, ChannelID: move.ChannelID})
+	}
+	result.ScheduledEvents = fake.ScheduledEvents()
+
+	return &result, nil
+}
+
+// newDryRunGuild returns the guild of an event, with a text channel for every channel the
+// event refers to, so lookups in the fake Discord API succeed
+func newDryRunGuild(event map[string]interface{}) *discordgo.Guild {
+	guild := &discordgo.Guild{ID: guildIDOf(event), Name: "Dry Run"}
+	if guild.ID == "" {
+		guild.ID = "0"
+	}
+
+	channelIDs := []string{stringField(event, "discord_text_channel_id")}
+	if payload, ok := event["payload"].(map[string]interface{}); ok {
+		channelIDs = append(channelIDs, stringField(payload, "channel_id"))
+	}
+	for _, id := range channelIDs {
+		if id == "" {
+			continue
+		}
+		guild.Channels = append(guild.Channels, &discordgo.Channel{
+			ID:      id,
+			GuildID: guild.ID,
+			Name:    "dry-run-" + id,
+			Type:    discordgo.ChannelTypeGuildText,
+		})
+	}
+	return guild
+}
+
+// newDeadLetter describes a dead-lettered message from its headers
+func newDeadLetter(queueName string, m rabbitmqtest.Message) *deadLetter {
+	dl := &deadLetter{Queue: queueName}
+	if deaths, ok := m.Headers["x-death"].([]interface{}); ok && len(deaths) > 0 {
+		if death, ok := deaths[0].(amqp.Table); ok {
+			dl.Reason, _ = death["reason"].(string)
+		}
+	}
+	if report, ok := m.Headers[rabbitmq.ValidationReportHeader].(string); ok {
+		dl.Reason = "validation failed"
+		dl.ValidationReport = json.RawMessage(report)
+	}
+	return dl
+}
+
+// renderMessages converts recorded messages for printing
+func renderMessages(messages []discordtest.Message) []renderedMessage {
+	rendered := make([]renderedMessage, 0, len(messages))
+	for _, m := range messages {
+		rendered = append(rendered, renderedMessage{
+			ChannelID:  m.ChannelID,
+			UserID:     m.UserID,
+			Content:    m.Content,
+			Embeds:     m.Embeds,
+			Components: m.Components,
+			Pinned:     m.Pinned,
+			Edited:     m.Edited,
+		})
+	}
+	return rendered
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gamers-bot/internal/config"
+	"github.com/gamers-bot/internal/rabbitmq"
+)
+
+// templates holds one example event per event type, named after the event type
+//
+//go:embed templates/*.json
+var templates embed.FS
+
+// templateNames returns the names of the built-in templates, sorted
+func templateNames() []string {
+	entries, _ := templates.ReadDir("templates")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	slices.Sort(names)
+	return names
+}
+
+// runTemplates lists the built-in templates, or prints the named one
+func runTemplates(_ context.Context, _ *config.Config, args []string) error {
+	fs := newFlagSet("templates", "[event-type]")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		for _, name := range templateNames() {
+			fmt.Println(name)
+		}
+		return nil
+	}
+
+	data, err := templates.ReadFile(path.Join("templates", fs.Arg(0)+".json"))
+	if err != nil {
+		return fmt.Errorf("no template for event type %s", fs.Arg(0))
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
+
+// eventFlags select the event to send and override fields of it
+type eventFlags struct {
+	file     string
+	template string
+	guild    string
+	channel  string
+	sets     setFlags
+}
+
+// register adds the event flags to fs
+func (f *eventFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.file, "file", "", "read the event from a JSON file (- for stdin)")
+	fs.StringVar(&f.template, "template", "", "use the built-in template of an event type (see the templates command)")
+	fs.StringVar(&f.guild, "guild", "", "override the Discord guild ID")
+	fs.StringVar(&f.channel, "channel", "", "override the Discord text channel ID")
+	fs.Var(&f.sets, "set", "set a field, as path.to.field=value; values of non-string fields are decoded as JSON (repeatable)")
+}
+
+// load reads the event and applies the overrides. Missing event_id, correlation_id (legacy
+// events) and timestamp fields are filled in, so templates can be sent repeatedly.
+func (f *eventFlags) load() (map[string]interface{}, error) {
+	var (
+		data []byte
+		err  error
+	)
+	switch {
+	case f.file != "" && f.template != "":
+		return nil, errors.New("-file and -template are mutually exclusive")
+	case f.file == "-":
+		data, err = io.ReadAll(os.Stdin)
+	case f.file != "":
+		data, err = os.ReadFile(f.file)
+	case f.template != "":
+		data, err = templates.ReadFile(path.Join("templates", f.template+".json"))
+		if err != nil {
+			return nil, fmt.Errorf("no template for event type %s", f.template)
+		}
+	default:
+		return nil, errors.New("either -file or -template is required")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event: %w", err)
+	}
+
+	event, err := decodeJSONObject(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse event: %w", err)
+	}
+
+	if f.guild != "" {
+		if _, ok := event["guild_id"]; ok {
+			event["guild_id"] = f.guild
+		} else {
+			event["discord_guild_id"] = f.guild
+		}
+	}
+	if f.channel != "" {
+		if payload, ok := event["payload"].(map[string]interface{}); ok {
+			payload["channel_id"] = f.channel
+		} else {
+			event["discord_text_channel_id"] = f.channel
+		}
+	}
+	for _, set := range f.sets {
+		if err := setField(event, set.path, set.value); err != nil {
+			return nil, err
+		}
+	}
+
+	eventType := eventTypeOf(event)
+	if eventType == "" {
+		return nil, errors.New("event has no event_type")
+	}
+	if stringField(event, "event_id") == "" {
+		event["event_id"] = rabbitmq.NewEventID()
+	}
+	if isLegacyEvent(eventType) {
+		if stringField(event, "correlation_id") == "" {
+			event["correlation_id"] = rabbitmq.NewEventID()
+		}
+	} else if _, ok := event["timestamp"]; !ok {
+		event["timestamp"] = time.Now().UTC().Format(time.RFC3339)
+	}
+	return event, nil
+}
+
+// setFlag is one -set override
+type setFlag struct {
+	path  string
+	value string
+}
+
+// setFlags collects repeated -set flags
+type setFlags []setFlag
+
+// String implements flag.Value
+func (s *setFlags) String() string {
+	parts := make([]string, len(*s))
+	for i, set := range *s {
+		parts[i] = set.path + "=" + set.value
+	}
+	return strings.Join(parts, ",")
+}
+
+// Set implements flag.Value
+func (s *setFlags) Set(value string) error {
+	fieldPath, raw, ok := strings.Cut(value, "=")
+	if !ok || fieldPath == "" {
+		return fmt.Errorf("expected path=value, got %q", value)
+	}
+	*s = append(*s, setFlag{path: fieldPath, value: raw})
+	return nil
+}
+
+// setField sets the field at a dot-separated path, creating missing objects on the way.
+// The value replaces a string field as is and is decoded as JSON otherwise, falling back to a
+// string, so Discord IDs stay strings while numbers, booleans and arrays keep their type.
+func setField(event map[string]interface{}, fieldPath, raw string) error {
+	keys := strings.Split(fieldPath, ".")
+	obj := event
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := obj[key].(map[string]interface{})
+		if !ok {
+			if _, exists := obj[key]; exists {
+				return fmt.Errorf("cannot set %s: %s is not an object", fieldPath, key)
+			}
+			next = make(map[string]interface{})
+			obj[key] = next
+		}
+		obj = next
+	}
+
+	key := keys[len(keys)-1]
+	if _, isString := obj[key].(string); isString {
+		obj[key] = raw
+		return nil
+	}
+
+	var value interface{}
+	decoder := json.NewDecoder(strings.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		value = raw
+	}
+	obj[key] = value
+	return nil
+}
+
+// decodeJSONObject decodes a JSON object, keeping numbers exact
+func decodeJSONObject(data []byte) (map[string]interface{}, error) {
+	var obj map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&obj); err != nil {
+		return nil, err
+	}
+	if obj == nil {
+		return nil, errors.New("expected a JSON object")
+	}
+	return obj, nil
+}
+
+// stringField returns a string field of an event, or "" if it is missing or not a string
+func stringField(event map[string]interface{}, key string) string {
+	s, _ := event[key].(string)
+	return s
+}
+
+// eventTypeOf returns the event type of an event
+func eventTypeOf(event map[string]interface{}) string {
+	return stringField(event, "event_type")
+}
+
+// guildIDOf returns the guild ID of an event in either the legacy or the WAS format
+func guildIDOf(event map[string]interface{}) string {
+	if guildID := stringField(event, "discord_guild_id"); guildID != "" {
+		return guildID
+	}
+	return stringField(event, "guild_id")
+}
+
+// isLegacyEvent reports whether an event type uses the legacy request/response format
+func isLegacyEvent(eventType string) bool {
+	return eventType == strings.ToUpper(eventType)
+}
+
+// destinationFlags select where an event is published
+type destinationFlags struct {
+	queue      string
+	exchange   string
+	routingKey string
+}
+
+// register adds the destination flags to fs
+func (f *destinationFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.queue, "queue", "", "publish straight to a queue through the default exchange")
+	fs.StringVar(&f.exchange, "exchange", "", "publish to an exchange (default RABBITMQ_EXCHANGE)")
+	fs.StringVar(&f.routingKey, "routing-key", "", "routing key for -exchange (default the event type)")
+}
+
+// destination is an exchange and routing key to publish to
+type destination struct {
+	Exchange   string
+	RoutingKey string
+}
+
+// String describes the destination for output
+func (d destination) String() string {
+	if d.Exchange == "" {
+		return "queue " + d.RoutingKey
+	}
+	return fmt.Sprintf("exchange %s with routing key %s", d.Exchange, d.RoutingKey)
+}
+
+// resolve returns the destination of an event. Without flags, contest and game events go to
+// RABBITMQ_EXCHANGE with the event type as routing key, as the backend publishes them, and
+// every other event goes to the request queue.
+func (f *destinationFlags) resolve(cfg *config.Config, eventType string) (destination, error) {
+	if f.queue != "" {
+		if f.exchange != "" || f.routingKey != "" {
+			return destination{}, errors.New("-queue cannot be combined with -exchange or -routing-key")
+		}
+		return destination{RoutingKey: f.queue}, nil
+	}
+
+	if f.exchange != "" || f.routingKey != "" {
+		dest := destination{Exchange: f.exchange, RoutingKey: f.routingKey}
+		if dest.Exchange == "" {
+			dest.Exchange = cfg.RabbitMQExchange
+		}
+		if dest.RoutingKey == "" {
+			dest.RoutingKey = eventType
+		}
+		return dest, nil
+	}
+
+	if strings.HasPrefix(eventType, "contest.") || strings.HasPrefix(eventType, "game.") {
+		return destination{Exchange: cfg.RabbitMQExchange, RoutingKey: eventType}, nil
+	}
+	return destination{RoutingKey: cfg.RabbitMQRequestQueue}, nil
+}
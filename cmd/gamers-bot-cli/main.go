@@ -0,0 +1,103 @@
+// Command gamers-bot-cli publishes test events to RabbitMQ, inspects and replays dead-lettered
+// messages, and renders events through the bot's handlers without Discord or RabbitMQ.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/charmbracelet/log"
+	"github.com/gamers-bot/internal/config"
+)
+
+// command is a gamers-bot-cli subcommand
+type command struct {
+	summary string
+	run     func(ctx context.Context, cfg *config.Config, args []string) error
+}
+
+var commands = map[string]command{
+	"publish":   {"Publish an event from a file or a built-in template", runPublish},
+	"replay":    {"Move dead-lettered messages back to their source queue", runReplay},
+	"peek":      {"Print the messages of a queue without removing them", runPeek},
+	"dry-run":   {"Render an event through the handlers without Discord or RabbitMQ", runDryRun},
+	"templates": {"List the built-in event templates or print one", runTemplates},
+}
+
+// commandOrder is the order of the commands in the usage text
+var commandOrder = []string{"publish", "replay", "peek", "dry-run", "templates"}
+
+func main() {
+	verbose := flag.Bool("v", false, "log handler and RabbitMQ activity to stderr")
+	flag.Usage = usage
+	flag.Parse()
+
+	level := log.WarnLevel
+	if *verbose {
+		level = log.InfoLevel
+	}
+	slog.SetDefault(slog.New(log.NewWithOptions(os.Stderr, log.Options{
+		ReportTimestamp: true,
+		Level:           level,
+	})))
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+
+	// The CLI does not connect to Discord, so the Discord settings need not be valid
+	cfg, err := config.Parse()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to load configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := cmd.run(ctx, cfg, flag.Args()[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		stop()
+		os.Exit(1)
+	}
+}
+
+// usage prints the command overview
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintln(out, "Usage: gamers-bot-cli [-v] <command> [flags]")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Commands:")
+	for _, name := range commandOrder {
+		fmt.Fprintf(out, "  %-10s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Run gamers-bot-cli <command> -h for the flags of a command.")
+	fmt.Fprintln(out, "RabbitMQ settings are read from the environment and env/.env like the bot.")
+}
+
+// newFlagSet creates the flag set of a command
+func newFlagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: gamers-bot-cli %s %s\n\nFlags:\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gamers-bot/internal/config"
+	"github.com/gamers-bot/internal/rabbitmq"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// publishTimeout bounds the wait for a broker confirm
+const publishTimeout = 10 * time.Second
+
+// runPublish publishes an event to RabbitMQ and waits for the broker to confirm it
+func runPublish(ctx context.Context, cfg *config.Config, args []string) error {
+	fs := newFlagSet("publish", "(-file path | -template event-type) [flags]")
+	var events eventFlags
+	events.register(fs)
+	var destinations destinationFlags
+	destinations.register(fs)
+	printOnly := fs.Bool("print", false, "print the event instead of publishing it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	event, err := events.load()
+	if err != nil {
+		return err
+	}
+	if *printOnly {
+		return printJSON(event)
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	dest, err := destinations.resolve(cfg, eventTypeOf(event))
+	if err != nil {
+		return err
+	}
+
+	conn, err := dial(cfg)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	publisher, err := newConfirmPublisher(conn)
+	if err != nil {
+		return err
+	}
+	defer publisher.Close()
+
+	if err := publisher.Publish(ctx, dest, newPublishing(event, body)); err != nil {
+		return err
+	}
+
+	fmt.Printf("Published %s %s to %s\n", eventTypeOf(event), stringField(event, "event_id"), dest)
+	return nil
+}
+
+// dial connects to the configured RabbitMQ broker
+func dial(cfg *config.Config) (rabbitmq.Connection, error) {
+	if !cfg.RabbitMQEnabled() {
+		return nil, errors.New("RabbitMQ is not configured; set RABBITMQ_URL or RABBITMQ_HOST")
+	}
+	conn, err := rabbitmq.Dial(cfg.RabbitMQURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+	}
+	return conn, nil
+}
+
+// newPublishing builds the AMQP message of an event, with the properties the backend sets
+func newPublishing(event map[string]interface{}, body []byte) amqp.Publishing {
+	return amqp.Publishing{
+		Headers:       amqp.Table{"event_type": eventTypeOf(event)},
+		ContentType:   "application/json",
+		DeliveryMode:  amqp.Persistent,
+		MessageId:     stringField(event, "event_id"),
+		CorrelationId: stringField(event, "correlation_id"),
+		Timestamp:     time.Now(),
+		Body:          body,
+	}
+}
+
+// confirmPublisher publishes mandatory messages on a channel in confirm mode, so every
+// publish is known to have reached a queue
+type confirmPublisher struct {
+	channel rabbitmq.Channel
+	returns <-chan amqp.Return
+}
+
+// newConfirmPublisher opens a channel in confirm mode on conn
+func newConfirmPublisher(conn rabbitmq.Connection) (*confirmPublisher, error) {
+	channel, err := conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open channel: %w", err)
+	}
+	if err := channel.Confirm(false); err != nil {
+		channel.Close()
+		return nil, fmt.Errorf("failed to enable confirm mode: %w", err)
+	}
+	return &confirmPublisher{
+		channel: channel,
+		returns: channel.NotifyReturn(make(chan amqp.Return, 16)),
+	}, nil
+}
+
+// Publish publishes msg to dest and waits for the confirm. It fails if no queue is bound to
+// dest, since the broker returns unroutable mandatory messages before confirming them.
+func (p *confirmPublisher) Publish(ctx context.Context, dest destination, msg amqp.Publishing) error {
+	ctx, cancel := context.WithTimeout(ctx, publishTimeout)
+	defer cancel()
+
+	confirm, err := p.channel.PublishWithDeferredConfirmWithContext(
+		ctx,
+		dest.Exchange,   // exchange
+		dest.RoutingKey, // routing key
+		true,            // mandatory
+		false,           // immediate
+		msg,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", dest, err)
+	}
+
+	acked, err := confirm.WaitContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to wait for the confirm from %s: %w", dest, err)
+	}
+
+	select {
+	case ret := <-p.returns:
+		return fmt.Errorf("message to %s was not routed to any queue: %s", dest, ret.ReplyText)
+	default:
+	}
+	if !acked {
+		return fmt.Errorf("broker rejected the message to %s", dest)
+	}
+	return nil
+}
+
+// Close closes the channel
+func (p *confirmPublisher) Close() error {
+	return p.channel.Close()
+}
+
+// printJSON prints v as indented JSON to stdout
+func printJSON(v interface{}) error {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal output: %w", err)
+	}
+	fmt.Println(string(out))
+	return nil
+}
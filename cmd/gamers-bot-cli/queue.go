@@ -0,0 +1,272 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gamers-bot/internal/config"
+	"github.com/gamers-bot/internal/rabbitmq"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// fetchTimeout bounds the wait for the deliveries of a queue
+const fetchTimeout = 5 * time.Second
+
+// runPeek prints the messages at the head of a queue. They are consumed without being
+// acknowledged and return to the queue when the channel closes, marked as redelivered, so
+// peek is meant for dead-letter queues rather than queues the bot is consuming.
+func runPeek(ctx context.Context, cfg *config.Config, args []string) error {
+	fs := newFlagSet("peek", "-queue name [flags]")
+	queueName := fs.String("queue", "", "queue to inspect, e.g. discord.commands.dlq")
+	limit := fs.Int("limit", 10, "maximum number of messages to print")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *queueName == "" {
+		return errors.New("-queue is required")
+	}
+
+	conn, err := dial(cfg)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return fmt.Errorf("failed to open channel: %w", err)
+	}
+	defer ch.Close()
+
+	deliveries, err := fetch(ctx, ch, *queueName, *limit)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	for _, d := range deliveries {
+		if err := encoder.Encode(newPeekedMessage(d)); err != nil {
+			return fmt.Errorf("failed to write message: %w", err)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "%d message(s) from %s\n", len(deliveries), *queueName)
+	return nil
+}
+
+// runReplay moves dead-lettered messages matching the filters from the DLQ of a queue back to
+// the queue. Messages that do not match stay in the DLQ.
+func runReplay(ctx context.Context, cfg *config.Config, args []string) error {
+	fs := newFlagSet("replay", "-queue name [flags]")
+	queueName := fs.String("queue", "", "source queue whose DLQ is replayed, e.g. discord.commands")
+	eventType := fs.String("event-type", "", "only replay messages of this event type")
+	guildID := fs.String("guild", "", "only replay messages of this Discord guild")
+	limit := fs.Int("limit", 0, "maximum number of messages to replay (0 for all)")
+	list := fs.Bool("list", false, "print the matching messages instead of replaying them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *queueName == "" {
+		return errors.New("-queue is required")
+	}
+	dlqName := rabbitmq.DeadLetterQueueName(*queueName)
+
+	conn, err := dial(cfg)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return fmt.Errorf("failed to open channel: %w", err)
+	}
+	// Closing the channel returns the messages that were not replayed to the DLQ
+	defer ch.Close()
+
+	publisher, err := newConfirmPublisher(conn)
+	if err != nil {
+		return err
+	}
+	defer publisher.Close()
+
+	deliveries, err := fetch(ctx, ch, dlqName, math.MaxInt)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	dest := destination{RoutingKey: *queueName}
+	matched := 0
+	for _, d := range deliveries {
+		if *limit > 0 && matched >= *limit {
+			break
+		}
+		body, _ := decodeJSONObject(d.Body)
+		if *eventType != "" && deliveryEventType(d, body) != *eventType {
+			continue
+		}
+		if *guildID != "" && guildIDOf(body) != *guildID {
+			continue
+		}
+		matched++
+
+		if *list {
+			if err := encoder.Encode(newPeekedMessage(d)); err != nil {
+				return fmt.Errorf("failed to write message: %w", err)
+			}
+			continue
+		}
+
+		if err := publisher.Publish(ctx, dest, replayPublishing(d)); err != nil {
+			return fmt.Errorf("replayed %d message(s) before failing: %w", matched-1, err)
+		}
+		if err := d.Ack(false); err != nil {
+			return fmt.Errorf("failed to remove message %s from %s: %w", d.MessageId, dlqName, err)
+		}
+		fmt.Printf("Replayed %s %s\n", deliveryEventType(d, body), d.MessageId)
+	}
+
+	if *list {
+		fmt.Fprintf(os.Stderr, "%d of %d message(s) in %s match\n", matched, len(deliveries), dlqName)
+	} else {
+		fmt.Fprintf(os.Stderr, "Replayed %d of %d message(s) from %s to %s\n", matched, len(deliveries), dlqName, *queueName)
+	}
+	return nil
+}
+
+// fetch consumes up to limit messages from a queue without acknowledging them. The messages
+// return to the queue when ch closes, unless they are acknowledged first.
+func fetch(ctx context.Context, ch rabbitmq.Channel, queueName string, limit int) ([]amqp.Delivery, error) {
+	q, err := ch.QueueDeclarePassive(queueName, true, false, false, false, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect queue %s: %w", queueName, err)
+	}
+	n := min(limit, q.Messages)
+	if n <= 0 {
+		return nil, nil
+	}
+
+	if err := ch.Qos(min(n, math.MaxUint16), 0, false); err != nil {
+		return nil, fmt.Errorf("failed to set QoS: %w", err)
+	}
+	deliveries, err := ch.Consume(
+		queueName, // queue
+		"",        // consumer
+		false,     // auto-ack
+		false,     // exclusive
+		false,     // no-local
+		false,     // no-wait
+		nil,       // args
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume from %s: %w", queueName, err)
+	}
+
+	timer := time.NewTimer(fetchTimeout)
+	defer timer.Stop()
+
+	fetched := make([]amqp.Delivery, 0, n)
+	for len(fetched) < n {
+		select {
+		case d, ok := <-deliveries:
+			if !ok {
+				return nil, fmt.Errorf("consumer of %s was closed", queueName)
+			}
+			fetched = append(fetched, d)
+		case <-timer.C:
+			// Messages consumed by someone else in the meantime
+			return fetched, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return fetched, nil
+}
+
+// deliveryEventType returns the event type of a delivery from its header or its body
+func deliveryEventType(d amqp.Delivery, body map[string]interface{}) string {
+	if eventType, ok := d.Headers["event_type"].(string); ok && eventType != "" {
+		return eventType
+	}
+	return eventTypeOf(body)
+}
+
+// replayPublishing copies a dead-lettered delivery for republishing. The dead-letter, retry
+// and validation headers are dropped so the bot treats it as a fresh delivery.
+func replayPublishing(d amqp.Delivery) amqp.Publishing {
+	headers := amqp.Table{}
+	for k, v := range d.Headers {
+		switch {
+		case k == "x-death",
+			k == rabbitmq.RetryCountHeader,
+			k == rabbitmq.ValidationReportHeader,
+			strings.HasPrefix(k, "x-first-death-"),
+			strings.HasPrefix(k, "x-last-death-"):
+			continue
+		}
+		headers[k] = v
+	}
+
+	return amqp.Publishing{
+		Headers:         headers,
+		ContentType:     d.ContentType,
+		ContentEncoding: d.ContentEncoding,
+		DeliveryMode:    amqp.Persistent,
+		CorrelationId:   d.CorrelationId,
+		ReplyTo:         d.ReplyTo,
+		MessageId:       d.MessageId,
+		Timestamp:       d.Timestamp,
+		Type:            d.Type,
+		AppId:           d.AppId,
+		Body:            d.Body,
+	}
+}
+
+// peekedMessage is a delivery as printed by peek and replay -list
+type peekedMessage struct {
+	Exchange      string                 `json:"exchange"`
+	RoutingKey    string                 `json:"routing_key"`
+	Redelivered   bool                   `json:"redelivered"`
+	MessageID     string                 `json:"message_id,omitempty"`
+	CorrelationID string                 `json:"correlation_id,omitempty"`
+	Timestamp     *time.Time             `json:"timestamp,omitempty"`
+	Headers       map[string]interface{} `json:"headers,omitempty"`
+	Body          interface{}            `json:"body"`
+}
+
+// newPeekedMessage converts a delivery for printing. A JSON body is embedded as is, any other
+// body as a string.
+func newPeekedMessage(d amqp.Delivery) peekedMessage {
+	m := peekedMessage{
+		Exchange:      d.Exchange,
+		RoutingKey:    d.RoutingKey,
+		Redelivered:   d.Redelivered,
+		MessageID:     d.MessageId,
+		CorrelationID: d.CorrelationId,
+		Headers:       d.Headers,
+		Body:          string(d.Body),
+	}
+	if !d.Timestamp.IsZero() {
+		m.Timestamp = &d.Timestamp
+	}
+	if json.Valid(d.Body) {
+		m.Body = json.RawMessage(d.Body)
+	}
+
+	// The validation report is a JSON string; embed it as an object
+	if report, ok := d.Headers[rabbitmq.ValidationReportHeader].(string); ok && json.Valid([]byte(report)) {
+		headers := make(map[string]interface{}, len(d.Headers))
+		for k, v := range d.Headers {
+			headers[k] = v
+		}
+		headers[rabbitmq.ValidationReportHeader] = json.RawMessage(report)
+		m.Headers = headers
+	}
+	return m
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gamers-bot/internal/rabbitmq"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestReplayPublishingDropsDeadLetterHeaders(t *testing.T) {
+	timestamp := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	d := amqp.Delivery{
+		Headers: amqp.Table{
+			"event_type":                    "game.finished",
+			"schema_version":                int32(2),
+			"x-death":                       []interface{}{amqp.Table{"queue": "bot.game.notifications", "reason": "rejected", "count": int64(1)}},
+			"x-first-death-queue":           "bot.game.notifications",
+			"x-first-death-reason":          "rejected",
+			"x-last-death-exchange":         "gamers.dlx",
+			rabbitmq.RetryCountHeader:       int32(3),
+			rabbitmq.ValidationReportHeader: `{"violations":[]}`,
+		},
+		ContentType:     "application/json",
+		ContentEncoding: "utf-8",
+		DeliveryMode:    amqp.Transient,
+		CorrelationId:   "correlation-1",
+		ReplyTo:         "discord.responses",
+		MessageId:       "event-1",
+		Timestamp:       timestamp,
+		Type:            "game.finished",
+		AppId:           "gamers-backend",
+		Body:            []byte(`{"game_id":7}`),
+	}
+
+	got := replayPublishing(d)
+
+	want := amqp.Publishing{
+		Headers:         amqp.Table{"event_type": "game.finished", "schema_version": int32(2)},
+		ContentType:     "application/json",
+		ContentEncoding: "utf-8",
+		DeliveryMode:    amqp.Persistent,
+		CorrelationId:   "correlation-1",
+		ReplyTo:         "discord.responses",
+		MessageId:       "event-1",
+		Timestamp:       timestamp,
+		Type:            "game.finished",
+		AppId:           "gamers-backend",
+		Body:            []byte(`{"game_id":7}`),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("replayPublishing =\n%+v\nwant\n%+v", got, want)
+	}
+	if len(d.Headers) != 8 {
+		t.Errorf("delivery headers modified: %v", d.Headers)
+	}
+}
@@ -2,7 +2,7 @@ package main
 
 import (
 	"context"
-	"errors"
+	"io"
 	"log/slog"
 	"os"
 	"os/signal"
@@ -10,11 +10,13 @@ import (
 	"time"
 
 	"github.com/charmbracelet/log"
+	"github.com/gamers-bot/internal/app"
 	"github.com/gamers-bot/internal/bot"
 	"github.com/gamers-bot/internal/config"
-	"github.com/gamers-bot/internal/handlers"
+	"github.com/gamers-bot/internal/metrics"
 	"github.com/gamers-bot/internal/rabbitmq"
-	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/gamers-bot/internal/server"
+	"github.com/gamers-bot/internal/store"
 )
 
 func main() {
@@ -37,12 +39,72 @@ func main() {
 
 	slog.Info("Configuration loaded successfully")
 
+	// Initialize dedup store (shared across RabbitMQ reconnects)
+	dedupStore, err := newDedupStore(cfg)
+	if err != nil {
+		slog.Error("Failed to create dedup store", "error", err)
+		os.Exit(1)
+	}
+	// Closed once the RabbitMQ session has stopped using them, see app.CloseStoresAfter
+	var stores []io.Closer
+	if dedupStore != nil {
+		stores = append(stores, dedupStore)
+	}
+
+	// Initialize game status message store (game_id -> status message)
+	gameMessages, err := store.NewBoltGameMessageStore(cfg.GameMessageStorePath, cfg.GameMessageRetention)
+	if err != nil {
+		slog.Error("Failed to create game message store", "error", err)
+		os.Exit(1)
+	}
+	stores = append(stores, gameMessages)
+
+	// Create event handlers wrapped in their middlewares and load the queue topology;
+	// an invalid topology stops startup
+	botMetrics := metrics.New()
+	eventHandlers := app.ApplyMiddlewares(cfg, botMetrics, app.NewEventHandlers(cfg, gameMessages))
+	topology, err := app.LoadTopology(cfg, eventHandlers)
+	if err != nil {
+		slog.Error("Failed to load RabbitMQ topology", "error", err)
+		os.Exit(1)
+	}
+
+	// Load the payload schemas embedded in the binary
+	schemas, err := app.NewSchemaRegistry(cfg, eventHandlers)
+	if err != nil {
+		slog.Error("Failed to load payload schemas", "error", err)
+		os.Exit(1)
+	}
+
 	// Initialize Discord bot
 	discordBot, err := bot.New(cfg.DiscordToken)
 	if err != nil {
 		slog.Error("Failed to create Discord bot", "error", err)
 		os.Exit(1)
 	}
+	discordBot.SetOrganizerRoleID(cfg.DiscordOrganizerRoleID)
+	discordBot.SetMetrics(botMetrics)
+	if cfg.BotMode == bot.ModeShadow {
+		discordBot.SetShadowMode(cfg.BotShadowChannelID)
+	}
+
+	// Track consumer state for the health endpoints, across RabbitMQ reconnects
+	var rabbitMQStatus *rabbitmq.StatusTracker
+	if cfg.RabbitMQEnabled() {
+		rabbitMQStatus = rabbitmq.NewStatusTracker()
+	}
+
+	// Serve /metrics and the health endpoints
+	var httpServer *server.Server
+	if cfg.HTTPEnabled {
+		httpServer = server.New(cfg.HTTPAddr)
+		httpServer.Handle("GET /metrics", botMetrics.Registry.Handler())
+		httpServer.HandleHealth(newStatusFunc(discordBot, rabbitMQStatus))
+		if err := httpServer.Start(); err != nil {
+			slog.Error("Failed to start HTTP server", "error", err)
+			os.Exit(1)
+		}
+	}
 
 	// Connect to Discord
 	if err := discordBot.Connect(); err != nil {
@@ -63,134 +125,38 @@ func main() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
-	// Try to connect to RabbitMQ (non-blocking) - only if enabled
+	// Keep a RabbitMQ connection alive in the background - only if enabled
+	var supervisor *rabbitmq.Supervisor
 	if cfg.RabbitMQEnabled() {
-		go func() {
-			for {
-				select {
-				case <-ctx.Done():
-					return
-				default:
-					slog.Info("Attempting to connect to RabbitMQ", "url", cfg.RabbitMQURL)
-					conn, err := amqp.Dial(cfg.RabbitMQURL)
-					if err != nil {
-						slog.Warn("Failed to connect to RabbitMQ, will retry in 10 seconds", "error", err)
-						discordBot.NotifyRabbitMQStatus(false, err)
-						time.Sleep(10 * time.Second)
-						continue
+		botMetrics.SetRabbitMQConnected(false)
+		connectedBefore := false
+		supervisor = rabbitmq.NewSupervisor(
+			rabbitmq.SupervisorConfig{
+				URL:        cfg.RabbitMQURL,
+				MinBackoff: cfg.RabbitMQReconnectMinBackoff,
+				MaxBackoff: cfg.RabbitMQReconnectMaxBackoff,
+			},
+			func(ctx context.Context, conn rabbitmq.Connection) error {
+				return app.RunRabbitMQSession(ctx, conn, cfg, discordBot, dedupStore, eventHandlers, topology, schemas, botMetrics, rabbitMQStatus)
+			},
+			func(state rabbitmq.ConnectionState, err error) {
+				switch state {
+				case rabbitmq.StateConnected:
+					if connectedBefore {
+						botMetrics.RabbitMQReconnected()
 					}
-
-					slog.Info("Connected to RabbitMQ")
+					connectedBefore = true
+					botMetrics.SetRabbitMQConnected(true)
+					rabbitMQStatus.SetConnected(true, nil)
 					discordBot.NotifyRabbitMQStatus(true, nil)
-
-					// Initialize publisher (for legacy queue responses)
-					publisher, err := rabbitmq.NewPublisher(conn, cfg.RabbitMQResponseQueue)
-					if err != nil {
-						slog.Error("Failed to create publisher", "error", err)
-						conn.Close()
-						time.Sleep(10 * time.Second)
-						continue
-					}
-
-					slog.Info("Publisher initialized", "queue", cfg.RabbitMQResponseQueue)
-
-					// Initialize ConsumerManager
-					manager := rabbitmq.NewConsumerManager(
-						conn,
-						cfg.RabbitMQExchange,
-						cfg.RabbitMQPrefetchCount,
-						discordBot,
-						publisher,
-					)
-
-					// Setup new queue topology (gamers.events exchange + 4 queues)
-					if err := manager.SetupTopology(); err != nil {
-						slog.Error("Failed to setup topology", "error", err)
-						publisher.Close()
-						conn.Close()
-						time.Sleep(10 * time.Second)
-						continue
-					}
-
-					// Setup legacy queue (discord.commands bound to legacy exchanges)
-					legacyBindings := []rabbitmq.LegacyBinding{
-						{Exchange: cfg.RabbitMQTeamExchange, RoutingKey: cfg.RabbitMQTeamRoutingKey},
-					}
-					if err := manager.SetupLegacyQueue(cfg.RabbitMQRequestQueue, legacyBindings); err != nil {
-						slog.Error("Failed to setup legacy queue", "error", err)
-						publisher.Close()
-						conn.Close()
-						time.Sleep(10 * time.Second)
-						continue
-					}
-
-					// Register legacy handlers (request/response pattern)
-					manager.RegisterHandler(rabbitmq.EventSendMessage, handlers.NewMessageHandler())
-					manager.RegisterHandler(rabbitmq.EventMoveMembers, handlers.NewVoiceHandler())
-					manager.RegisterHandler(rabbitmq.EventGetVoiceChannels, handlers.NewVoiceChannelHandler())
-					manager.RegisterHandler(rabbitmq.EventGetTextChannels, handlers.NewTextChannelHandler())
-					manager.RegisterHandler(rabbitmq.EventSendContestInvitation, handlers.NewContestInvitationHandler())
-
-					// Register application event handlers
-					manager.RegisterHandler(rabbitmq.EventApplicationRequested, handlers.NewApplicationRequestedHandler())
-					manager.RegisterHandler(rabbitmq.EventApplicationAccepted, handlers.NewApplicationAcceptedHandler())
-					manager.RegisterHandler(rabbitmq.EventApplicationRejected, handlers.NewApplicationRejectedHandler())
-					manager.RegisterHandler(rabbitmq.EventApplicationCancelled, handlers.NewApplicationCancelledHandler())
-					manager.RegisterHandler(rabbitmq.EventMemberWithdrawn, handlers.NewMemberWithdrawnHandler())
-
-					// Register team event handlers
-					manager.RegisterHandler(rabbitmq.EventTeamInviteSent, handlers.NewTeamInviteSentHandler())
-					manager.RegisterHandler(rabbitmq.EventTeamInviteAccepted, handlers.NewTeamInviteAcceptedHandler())
-					manager.RegisterHandler(rabbitmq.EventTeamInviteRejected, handlers.NewTeamInviteRejectedHandler())
-					manager.RegisterHandler(rabbitmq.EventTeamMemberJoined, handlers.NewTeamMemberJoinedHandler())
-					manager.RegisterHandler(rabbitmq.EventTeamMemberLeft, handlers.NewTeamMemberLeftHandler())
-					manager.RegisterHandler(rabbitmq.EventTeamMemberKicked, handlers.NewTeamMemberKickedHandler())
-					manager.RegisterHandler(rabbitmq.EventTeamLeadershipTransferred, handlers.NewTeamLeadershipTransferredHandler())
-					manager.RegisterHandler(rabbitmq.EventTeamFinalized, handlers.NewTeamFinalizedHandler())
-					manager.RegisterHandler(rabbitmq.EventTeamDeleted, handlers.NewTeamDeletedHandler())
-
-					// Register contest event handlers
-					manager.RegisterHandler(rabbitmq.EventContestCreated, handlers.NewContestCreatedHandler())
-
-					// Register game event handlers
-					manager.RegisterHandler(rabbitmq.EventGameScheduled, handlers.NewGameScheduledHandler())
-					manager.RegisterHandler(rabbitmq.EventGameActivated, handlers.NewGameActivatedHandler())
-					manager.RegisterHandler(rabbitmq.EventGameMatchDetecting, handlers.NewGameMatchDetectingHandler())
-					manager.RegisterHandler(rabbitmq.EventGameMatchDetected, handlers.NewGameMatchDetectedHandler())
-					manager.RegisterHandler(rabbitmq.EventGameMatchFailed, handlers.NewGameMatchFailedHandler())
-					manager.RegisterHandler(rabbitmq.EventGameFinished, handlers.NewGameFinishedHandler())
-
-					// Register contest teams ready handler
-					manager.RegisterHandler(rabbitmq.EventContestTeamsReady, handlers.NewContestTeamsReadyHandler())
-
-					slog.Info("All handlers registered")
-
-					// Start all consumers (blocking)
-					slog.Info("Starting ConsumerManager",
-						"exchange", cfg.RabbitMQExchange,
-						"legacy_queue", cfg.RabbitMQRequestQueue,
-					)
-					if err := manager.Start(ctx, cfg.RabbitMQRequestQueue); err != nil && !errors.Is(err, context.Canceled) {
-						slog.Error("ConsumerManager error", "error", err)
-					}
-
-					// Cleanup
-					manager.Close()
-					publisher.Close()
-					conn.Close()
-
-					// If context is cancelled, exit the goroutine
-					if ctx.Err() != nil {
-						return
-					}
-
-					// Otherwise, reconnect after delay
-					slog.Info("RabbitMQ connection lost, reconnecting in 10 seconds...")
-					discordBot.NotifyRabbitMQStatus(false, errors.New("connection lost"))
-					time.Sleep(10 * time.Second)
+				case rabbitmq.StateDisconnected:
+					botMetrics.SetRabbitMQConnected(false)
+					rabbitMQStatus.SetConnected(false, err)
+					discordBot.NotifyRabbitMQStatus(false, err)
 				}
-			}
-		}()
+			},
+		)
+		go supervisor.Run(ctx)
 	} else {
 		slog.Info("RabbitMQ not configured, running Discord bot only")
 	}
@@ -200,8 +166,60 @@ func main() {
 	slog.Info("Received shutdown signal, gracefully shutting down...")
 	cancel()
 
-	// Give some time for graceful shutdown
-	time.Sleep(2 * time.Second)
+	// Wait for consumers to drain and the RabbitMQ session to close its channels and connection
+	// before closing the stores. The Discord session is closed afterwards, so in-flight handlers
+	// can still use it.
+	stopped := make(chan struct{})
+	var sessionDone <-chan struct{} = stopped
+	if supervisor != nil {
+		sessionDone = supervisor.Done()
+	} else {
+		close(stopped) // no session, nothing to wait for
+	}
+	app.CloseStoresAfter(sessionDone, cfg.ShutdownTimeout, stores...)
+
+	if httpServer != nil {
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			slog.Warn("Failed to shut down HTTP server", "error", err)
+		}
+		cancelShutdown()
+	}
 
 	slog.Info("GAMERS Discord Bot stopped")
 }
+
+// newStatusFunc returns the status reported by the health endpoints, see server.NewStatus
+func newStatusFunc(discordBot *bot.DiscordBot, rabbitMQStatus *rabbitmq.StatusTracker) func() server.Status {
+	return func() server.Status {
+		discord := server.DiscordStatus{
+			Ready:            discordBot.Ready(),
+			GatewayLatencyMS: discordBot.GatewayLatency().Milliseconds(),
+			Guilds:           discordBot.Guilds(),
+		}
+
+		var rmq *server.RabbitMQStatus
+		if rabbitMQStatus != nil {
+			rmq = &server.RabbitMQStatus{
+				Status:      rabbitMQStatus.Status(),
+				ReplicaRole: discordBot.ReplicaRole(),
+			}
+		}
+		return server.NewStatus(discord, rmq)
+	}
+}
+
+// newDedupStore creates the dedup store selected by DEDUP_BACKEND, or nil if disabled
+func newDedupStore(cfg *config.Config) (store.DedupStore, error) {
+	switch cfg.DedupBackend {
+	case "file":
+		slog.Info("Using file-backed dedup store", "path", cfg.DedupPath, "retention", cfg.DedupRetention)
+		return store.NewBoltDedupStore(cfg.DedupPath, cfg.DedupRetention)
+	case "memory":
+		slog.Info("Using in-memory dedup store", "capacity", cfg.DedupCapacity, "retention", cfg.DedupRetention)
+		return store.NewMemoryDedupStore(cfg.DedupCapacity, cfg.DedupRetention), nil
+	default:
+		slog.Info("Duplicate event detection disabled")
+		return nil, nil
+	}
+}
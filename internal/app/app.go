@@ -0,0 +1,273 @@
+// Package app wires the event handlers, queue topology, payload schemas and RabbitMQ session
+// shared by the bot and gamers-bot-cli
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/gamers-bot/internal/bot"
+	"github.com/gamers-bot/internal/config"
+	"github.com/gamers-bot/internal/handlers"
+	"github.com/gamers-bot/internal/metrics"
+	"github.com/gamers-bot/internal/rabbitmq"
+	"github.com/gamers-bot/internal/schema"
+	"github.com/gamers-bot/internal/store"
+)
+
+// RunRabbitMQSession sets up publishers, topology and handlers on conn and consumes until
+// ctx is cancelled or the connection fails. Everything is torn down before it returns.
+func RunRabbitMQSession(ctx context.Context, conn rabbitmq.Connection, cfg *config.Config, discordBot *bot.DiscordBot, dedupStore store.DedupStore, eventHandlers map[rabbitmq.EventType]handlers.Handler, topology *rabbitmq.Topology, schemas *schema.Registry, botMetrics *metrics.Metrics, rabbitMQStatus *rabbitmq.StatusTracker) error {
+	// Initialize publisher (for legacy queue responses)
+	publisher, err := rabbitmq.NewPublisher(conn, cfg.RabbitMQResponseQueue, cfg.RabbitMQHAEnabled)
+	if err != nil {
+		return fmt.Errorf("failed to create publisher: %w", err)
+	}
+	defer publisher.Close()
+
+	slog.Info("Publisher initialized", "queue", cfg.RabbitMQResponseQueue)
+
+	// Initialize event publisher (for bot-originated events)
+	eventPublisher, err := rabbitmq.NewEventPublisher(conn, cfg.RabbitMQEventsExchange)
+	if err != nil {
+		return fmt.Errorf("failed to create event publisher: %w", err)
+	}
+	defer eventPublisher.Close()
+
+	slog.Info("Event publisher initialized", "exchange", cfg.RabbitMQEventsExchange)
+
+	// Initialize ConsumerManager
+	manager := rabbitmq.NewConsumerManager(
+		conn,
+		cfg.RabbitMQExchange,
+		cfg.RabbitMQPrefetchCount,
+		topology,
+		discordBot,
+		publisher,
+	)
+	defer manager.Close()
+
+	manager.SetRetryPolicy(rabbitmq.RetryPolicy{
+		DeadLetterExchange: cfg.RabbitMQDeadLetterExchange,
+		MaxAttempts:        cfg.RabbitMQRetryMaxAttempts,
+		BaseDelay:          cfg.RabbitMQRetryBaseDelay,
+	})
+	manager.SetShutdownGracePeriod(cfg.ShutdownGracePeriod)
+	if cfg.RabbitMQHAEnabled {
+		manager.SetHighAvailability(true, func(role rabbitmq.ReplicaRole) {
+			discordBot.SetReplicaRole(string(role))
+		})
+		defer discordBot.SetReplicaRole("")
+	}
+	manager.SetWorkers(cfg.RabbitMQWorkers)
+	for queueName, workers := range cfg.RabbitMQQueueWorkers {
+		manager.SetQueueWorkers(queueName, workers)
+	}
+	if dedupStore != nil {
+		manager.SetDedupStore(dedupStore)
+	}
+	if schemas != nil {
+		manager.SetSchemaRegistry(schemas)
+	}
+	manager.SetMetrics(botMetrics)
+	manager.SetStatusTracker(rabbitMQStatus)
+
+	// Setup notification queue topology (gamers.events exchange + topology queues)
+	if err := manager.SetupTopology(); err != nil {
+		return fmt.Errorf("failed to setup topology: %w", err)
+	}
+
+	// Setup legacy queue (discord.commands bound to legacy exchanges)
+	legacyBindings := []rabbitmq.LegacyBinding{
+		{Exchange: cfg.RabbitMQTeamExchange, RoutingKey: cfg.RabbitMQTeamRoutingKey},
+	}
+	if err := manager.SetupLegacyQueue(cfg.RabbitMQRequestQueue, legacyBindings); err != nil {
+		return fmt.Errorf("failed to setup legacy queue: %w", err)
+	}
+
+	for eventType, handler := range eventHandlers {
+		manager.RegisterHandler(eventType, handler)
+	}
+
+	slog.Info("All handlers registered")
+
+	// Button clicks report back to the web app through the event publisher
+	discordBot.SetEventPublisher(eventPublisher)
+	defer discordBot.SetEventPublisher(nil)
+
+	// Start all consumers (blocking)
+	slog.Info("Starting ConsumerManager",
+		"exchange", cfg.RabbitMQExchange,
+		"legacy_queue", cfg.RabbitMQRequestQueue,
+	)
+	err = manager.Start(ctx, cfg.RabbitMQRequestQueue)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		return fmt.Errorf("consumer manager stopped: %w", err)
+	}
+	return nil
+}
+
+// NewEventHandlers creates the handlers for every supported event type.
+// They are created once and registered again on every RabbitMQ reconnect.
+func NewEventHandlers(cfg *config.Config, gameMessages store.GameMessageStore) map[rabbitmq.EventType]handlers.Handler {
+	return map[rabbitmq.EventType]handlers.Handler{
+		// Legacy handlers (request/response pattern)
+		rabbitmq.EventSendMessage:           handlers.NewMessageHandler(),
+		rabbitmq.EventMoveMembers:           handlers.NewVoiceHandler(),
+		rabbitmq.EventGetVoiceChannels:      handlers.NewVoiceChannelHandler(),
+		rabbitmq.EventGetTextChannels:       handlers.NewTextChannelHandler(),
+		rabbitmq.EventSendContestInvitation: handlers.NewContestInvitationHandler(),
+
+		// Application event handlers
+		rabbitmq.EventApplicationRequested: handlers.NewApplicationRequestedHandler(),
+		rabbitmq.EventApplicationAccepted:  handlers.NewApplicationAcceptedHandler(),
+		rabbitmq.EventApplicationRejected:  handlers.NewApplicationRejectedHandler(),
+		rabbitmq.EventApplicationCancelled: handlers.NewApplicationCancelledHandler(),
+		rabbitmq.EventMemberWithdrawn:      handlers.NewMemberWithdrawnHandler(),
+
+		// Team event handlers
+		rabbitmq.EventTeamInviteSent:            handlers.NewTeamInviteSentHandler(),
+		rabbitmq.EventTeamInviteAccepted:        handlers.NewTeamInviteAcceptedHandler(),
+		rabbitmq.EventTeamInviteRejected:        handlers.NewTeamInviteRejectedHandler(),
+		rabbitmq.EventTeamMemberJoined:          handlers.NewTeamMemberJoinedHandler(),
+		rabbitmq.EventTeamMemberLeft:            handlers.NewTeamMemberLeftHandler(),
+		rabbitmq.EventTeamMemberKicked:          handlers.NewTeamMemberKickedHandler(),
+		rabbitmq.EventTeamLeadershipTransferred: handlers.NewTeamLeadershipTransferredHandler(),
+		rabbitmq.EventTeamFinalized:             handlers.NewTeamFinalizedHandler(),
+		rabbitmq.EventTeamDeleted:               handlers.NewTeamDeletedHandler(),
+
+		// Contest event handlers
+		rabbitmq.EventContestCreated: handlers.NewContestCreatedHandler(
+			cfg.WebAppURL,
+			cfg.ContestPinAnnouncement,
+			cfg.ContestCreateScheduledEvent,
+		),
+
+		// Game event handlers
+		rabbitmq.EventGameScheduled:      handlers.NewGameScheduledHandler(gameMessages),
+		rabbitmq.EventGameActivated:      handlers.NewGameActivatedHandler(gameMessages),
+		rabbitmq.EventGameMatchDetecting: handlers.NewGameMatchDetectingHandler(gameMessages),
+		rabbitmq.EventGameMatchDetected:  handlers.NewGameMatchDetectedHandler(gameMessages),
+		rabbitmq.EventGameMatchFailed:    handlers.NewGameMatchFailedHandler(gameMessages),
+		rabbitmq.EventGameFinished:       handlers.NewGameFinishedHandler(gameMessages),
+
+		// Contest teams ready handler
+		rabbitmq.EventContestTeamsReady: handlers.NewContestTeamsReadyHandler(),
+	}
+}
+
+// ApplyMiddlewares wraps every handler in the middleware chain of its event type
+func ApplyMiddlewares(cfg *config.Config, recorder handlers.MetricsRecorder, eventHandlers map[rabbitmq.EventType]handlers.Handler) map[rabbitmq.EventType]handlers.Handler {
+	for eventType := range cfg.HandlerTimeouts {
+		if _, ok := eventHandlers[rabbitmq.EventType(eventType)]; !ok {
+			slog.Warn("HANDLER_TIMEOUTS refers to an event type without a handler", "event_type", eventType)
+		}
+	}
+	for eventType := range cfg.HandlerMiddlewares {
+		if _, ok := eventHandlers[rabbitmq.EventType(eventType)]; !ok {
+			slog.Warn("HANDLER_MIDDLEWARES refers to an event type without a handler", "event_type", eventType)
+		}
+	}
+
+	wrapped := make(map[rabbitmq.EventType]handlers.Handler, len(eventHandlers))
+	for eventType, handler := range eventHandlers {
+		wrapped[eventType] = handlers.NewChain(handler, handlerMiddlewares(cfg, recorder, eventType)...)
+	}
+	return wrapped
+}
+
+// handlerMiddlewares returns the middlewares of an event type, outermost first, as configured by
+// HANDLER_MIDDLEWARES. By default Recover is innermost so the logs and metrics of a panicking
+// handler see its error.
+func handlerMiddlewares(cfg *config.Config, recorder handlers.MetricsRecorder, eventType rabbitmq.EventType) []handlers.Middleware {
+	names := cfg.HandlerMiddlewaresFor(string(eventType))
+	middlewares := make([]handlers.Middleware, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case "logging":
+			middlewares = append(middlewares, handlers.Logging())
+		case "metrics":
+			middlewares = append(middlewares, handlers.Metrics(recorder))
+		case "timeout":
+			middlewares = append(middlewares, handlers.Timeout(cfg.HandlerTimeoutFor(string(eventType))))
+		case "recover":
+			middlewares = append(middlewares, handlers.Recover())
+		}
+	}
+	return middlewares
+}
+
+// LoadTopology loads the topology file if configured, or the built-in topology otherwise,
+// and checks that every handler it refers to exists
+func LoadTopology(cfg *config.Config, eventHandlers map[rabbitmq.EventType]handlers.Handler) (*rabbitmq.Topology, error) {
+	topology := rabbitmq.DefaultTopology(cfg.RabbitMQRoutingKey)
+	if cfg.RabbitMQTopologyFile != "" {
+		var err error
+		topology, err = rabbitmq.LoadTopology(cfg.RabbitMQTopologyFile)
+		if err != nil {
+			return nil, err
+		}
+		slog.Info("Loaded RabbitMQ topology", "path", cfg.RabbitMQTopologyFile, "queues", len(topology.Queues))
+	}
+
+	registered := make(map[rabbitmq.EventType]bool, len(eventHandlers))
+	for eventType := range eventHandlers {
+		registered[eventType] = true
+	}
+	if err := topology.ValidateHandlers(registered); err != nil {
+		return nil, err
+	}
+	return topology, nil
+}
+
+// NewSchemaRegistry loads the embedded payload schemas, or returns nil if validation is disabled.
+// Every handled event type must have a schema.
+func NewSchemaRegistry(cfg *config.Config, eventHandlers map[rabbitmq.EventType]handlers.Handler) (*schema.Registry, error) {
+	if !cfg.SchemaValidationEnabled {
+		slog.Warn("Payload schema validation is disabled")
+		return nil, nil
+	}
+
+	registry, err := schema.NewRegistry()
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []error
+	for eventType := range eventHandlers {
+		if !registry.Has(string(eventType)) {
+			missing = append(missing, fmt.Errorf("no schema for event type %s", eventType))
+		}
+	}
+	if err := errors.Join(missing...); err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
+
+// CloseStoresAfter waits up to timeout for done, which is closed once the RabbitMQ session has
+// stopped, and then closes the stores its handlers write to. Handlers still running after the
+// timeout may be writing to the stores, so they are left open for the process exit to release.
+// It reports whether the session stopped in time.
+func CloseStoresAfter(done <-chan struct{}, timeout time.Duration, stores ...io.Closer) bool {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+	case <-timer.C:
+		slog.Warn("RabbitMQ did not shut down in time, leaving stores open", "timeout", timeout)
+		return false
+	}
+
+	for _, s := range stores {
+		if err := s.Close(); err != nil {
+			slog.Warn("Failed to close store", "error", err)
+		}
+	}
+	return true
+}
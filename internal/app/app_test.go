@@ -0,0 +1,67 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gamers-bot/internal/bot"
+	"github.com/gamers-bot/internal/config"
+	"github.com/gamers-bot/internal/handlers"
+	"github.com/gamers-bot/internal/rabbitmq"
+)
+
+// slowHandler fails with a transient error after a delay, ignoring its context
+type slowHandler struct{}
+
+func (slowHandler) Handle(ctx context.Context, _ *bot.DiscordBot, _ string, _ map[string]interface{}) (map[string]interface{}, error) {
+	time.Sleep(30 * time.Millisecond)
+	return nil, bot.NewError(bot.ErrorKindTransient, bot.ErrCodeDiscordUnavailable, errors.New("discord is down"))
+}
+
+// recorder records the event types of observed handler calls
+type recorder struct {
+	mu         sync.Mutex
+	eventTypes []string
+}
+
+func (r *recorder) ObserveHandler(eventType, outcome string, duration time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.eventTypes = append(r.eventTypes, eventType)
+}
+
+func TestApplyMiddlewaresUsesPerEventTypeChains(t *testing.T) {
+	const (
+		defaultEvent = rabbitmq.EventType("default.event")
+		customEvent  = rabbitmq.EventType("custom.event")
+	)
+	cfg := &config.Config{
+		HandlerTimeout:     10 * time.Millisecond,
+		HandlerMiddlewares: map[string][]string{string(customEvent): {"recover"}},
+	}
+	rec := &recorder{}
+	wrapped := ApplyMiddlewares(cfg, rec, map[rabbitmq.EventType]handlers.Handler{
+		defaultEvent: slowHandler{},
+		customEvent:  slowHandler{},
+	})
+
+	for _, eventType := range []rabbitmq.EventType{defaultEvent, customEvent} {
+		ctx := handlers.WithEventInfo(context.Background(), handlers.EventInfo{EventType: string(eventType)})
+		_, err := wrapped[eventType].Handle(ctx, nil, "", nil)
+
+		wantCode := bot.ErrCodeTimeout // the default chain has a deadline
+		if eventType == customEvent {
+			wantCode = bot.ErrCodeDiscordUnavailable // the custom chain has none
+		}
+		if _, code := bot.Classify(err); code != wantCode {
+			t.Errorf("%s: error code = %s, want %s", eventType, code, wantCode)
+		}
+	}
+
+	if len(rec.eventTypes) != 1 || rec.eventTypes[0] != string(defaultEvent) {
+		t.Errorf("metrics recorded %v, want only %s", rec.eventTypes, defaultEvent)
+	}
+}
@@ -0,0 +1,157 @@
+package app_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gamers-bot/internal/app"
+	"github.com/gamers-bot/internal/bot"
+	"github.com/gamers-bot/internal/bot/discordtest"
+	"github.com/gamers-bot/internal/config"
+	"github.com/gamers-bot/internal/metrics"
+	"github.com/gamers-bot/internal/rabbitmq"
+	"github.com/gamers-bot/internal/rabbitmq/rabbitmqtest"
+	"github.com/gamers-bot/internal/store"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// storeWriter blocks until released, ignoring its context, and then writes to a game message store
+type storeWriter struct {
+	store   store.GameMessageStore
+	started chan struct{}
+	release chan struct{}
+	written chan error
+}
+
+func newStoreWriter(s store.GameMessageStore) *storeWriter {
+	return &storeWriter{
+		store:   s,
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+		written: make(chan error, 1),
+	}
+}
+
+func (w *storeWriter) Handle(_ context.Context, _ *bot.DiscordBot, _ string, _ map[string]interface{}) (map[string]interface{}, error) {
+	close(w.started)
+	<-w.release
+	w.written <- w.store.Put(store.GameMessage{GameID: 1, ChannelID: testChannelID, MessageID: "message-1"})
+	return map[string]interface{}{}, nil
+}
+
+// startSupervisor runs a supervised session on an in-memory broker whose SEND_MESSAGE handler is
+// handler, publishes a SEND_MESSAGE request and waits for the handler to start. The returned cancel
+// function stops the supervisor.
+func startSupervisor(t *testing.T, cfg *config.Config, handler *storeWriter) (*rabbitmq.Supervisor, *rabbitmqtest.Broker, context.CancelFunc) {
+	t.Helper()
+	discordBot := bot.NewWithAPI(discordtest.New())
+	if err := discordBot.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+
+	eventHandlers := app.NewEventHandlers(cfg, store.NewMemoryGameMessageStore())
+	eventHandlers[rabbitmq.EventSendMessage] = handler
+	topology, err := app.LoadTopology(cfg, eventHandlers)
+	if err != nil {
+		t.Fatalf("LoadTopology: %v", err)
+	}
+
+	broker := rabbitmqtest.NewBroker()
+	status := rabbitmq.NewStatusTracker()
+	supervisor := rabbitmq.NewSupervisor(
+		rabbitmq.SupervisorConfig{URL: cfg.RabbitMQURL, Dial: broker.Dial},
+		func(ctx context.Context, conn rabbitmq.Connection) error {
+			return app.RunRabbitMQSession(ctx, conn, cfg, discordBot, nil, eventHandlers, topology, nil, metrics.New(), status)
+		},
+		nil,
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	go supervisor.Run(ctx)
+	t.Cleanup(cancel)
+
+	waitFor(t, "consumers to start", func() bool { return status.Status().ConsumersRunning() })
+	body := fmt.Sprintf(`{"event_type":%q,"guild_id":%q,"channel_id":%q,"content":"hi"}`, rabbitmq.EventSendMessage, testGuildID, testChannelID)
+	err = broker.Publish("", cfg.RabbitMQRequestQueue, amqp.Publishing{
+		Headers:       amqp.Table{"event_type": string(rabbitmq.EventSendMessage)},
+		ContentType:   "application/json",
+		CorrelationId: "request-1",
+		Body:          []byte(body),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-handler.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler did not start")
+	}
+	return supervisor, broker, cancel
+}
+
+func newBoltGameMessageStore(t *testing.T) *store.BoltGameMessageStore {
+	t.Helper()
+	s, err := store.NewBoltGameMessageStore(filepath.Join(t.TempDir(), "games.db"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestCloseStoresAfterDrainedSession(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.SchemaValidationEnabled = false
+	gameMessages := newBoltGameMessageStore(t)
+	handler := newStoreWriter(gameMessages)
+	supervisor, broker, stop := startSupervisor(t, cfg, handler)
+
+	stop()
+	closed := make(chan bool, 1)
+	go func() { closed <- app.CloseStoresAfter(supervisor.Done(), 5*time.Second, gameMessages) }()
+
+	select {
+	case <-closed:
+		t.Fatal("stores closed while a handler is still running")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(handler.release)
+
+	if !<-closed {
+		t.Fatal("CloseStoresAfter reported a timeout, want the session to drain")
+	}
+	if err := <-handler.written; err != nil {
+		t.Errorf("in-flight handler write: %v", err)
+	}
+	if n := broker.Unacked(cfg.RabbitMQRequestQueue) + len(broker.Messages(cfg.RabbitMQRequestQueue)); n != 0 {
+		t.Errorf("%d requests left unsettled, want the in-flight request acked", n)
+	}
+	if _, _, err := gameMessages.Get(1); err == nil {
+		t.Error("game message store still open after the session drained")
+	}
+}
+
+func TestCloseStoresAfterLeavesStoresOpenPastTimeout(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.SchemaValidationEnabled = false
+	cfg.ShutdownGracePeriod = 10 * time.Millisecond
+	gameMessages := newBoltGameMessageStore(t)
+	handler := newStoreWriter(gameMessages)
+	supervisor, _, stop := startSupervisor(t, cfg, handler)
+
+	stop()
+	// The handler ignores its context, so the session outlives the grace period and the timeout
+	if app.CloseStoresAfter(supervisor.Done(), 100*time.Millisecond, gameMessages) {
+		t.Fatal("CloseStoresAfter reported a drained session while a handler is still running")
+	}
+
+	close(handler.release)
+	if err := <-handler.written; err != nil {
+		t.Errorf("late handler write after the timeout: %v", err)
+	}
+	<-supervisor.Done()
+	if err := gameMessages.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+package bot
+
+import (
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// DiscordAPI is the subset of the Discord session used by the bot. It is implemented by the
+// gateway session and by the in-memory fake in package discordtest.
+type DiscordAPI interface {
+	// State returns the cached guild state; State().User is the bot user
+	State() *discordgo.State
+	// HeartbeatLatency returns the latency of the last gateway heartbeat
+	HeartbeatLatency() time.Duration
+
+	ChannelMessageSend(channelID, content string, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageSendEmbed(channelID string, embed *discordgo.MessageEmbed, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageEditEmbed(channelID, messageID string, embed *discordgo.MessageEmbed, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessagePin(channelID, messageID string, options ...discordgo.RequestOption) error
+	UserChannelCreate(recipientID string, options ...discordgo.RequestOption) (*discordgo.Channel, error)
+
+	Guild(guildID string, options ...discordgo.RequestOption) (*discordgo.Guild, error)
+	GuildChannels(guildID string, options ...discordgo.RequestOption) ([]*discordgo.Channel, error)
+	GuildMemberMove(guildID, userID string, channelID *string, options ...discordgo.RequestOption) error
+	GuildScheduledEventCreate(guildID string, event *discordgo.GuildScheduledEventParams, options ...discordgo.RequestOption) (*discordgo.GuildScheduledEvent, error)
+
+	ApplicationCommandCreate(appID, guildID string, cmd *discordgo.ApplicationCommand, options ...discordgo.RequestOption) (*discordgo.ApplicationCommand, error)
+	InteractionRespond(interaction *discordgo.Interaction, resp *discordgo.InteractionResponse, options ...discordgo.RequestOption) error
+}
+
+// sessionAPI adapts a gateway session to DiscordAPI
+type sessionAPI struct {
+	*discordgo.Session
+}
+
+// State implements DiscordAPI
+func (s sessionAPI) State() *discordgo.State {
+	return s.Session.State
+}
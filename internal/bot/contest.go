@@ -0,0 +1,159 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/gamers-bot/internal/models"
+)
+
+// colorContest is the embed color for contest announcements
+const colorContest = 0xE67E22 // orange
+
+// defaultContestEventDuration is used for scheduled events when the contest has no end date
+const defaultContestEventDuration = 3 * time.Hour
+
+// ContestAnnouncement contains the contest information rendered into an announcement.
+// Zero-valued fields are omitted from the embed.
+type ContestAnnouncement struct {
+	ContestID   int64
+	Title       string
+	Description string
+	GameType    string
+	StartDate   time.Time
+	EndDate     time.Time
+	MaxTeams    int
+	ApplyURL    string
+}
+
+// SendContestAnnouncement posts a contest announcement embed with an "Apply" link button
+func (b *DiscordBot) SendContestAnnouncement(ctx context.Context, channelID string, contest *ContestAnnouncement) (*models.ContestAnnouncementResult, error) {
+	send := &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{buildContestEmbed(contest)},
+	}
+
+	if contest.ApplyURL != "" {
+		send.Components = []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.Button{
+						Label: "参加申請",
+						Style: discordgo.LinkButton,
+						URL:   contest.ApplyURL,
+						Emoji: &discordgo.ComponentEmoji{Name: "🎮"},
+					},
+				},
+			},
+		}
+	}
+
+	message, err := b.api.ChannelMessageSendComplex(channelID, send, discordgo.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to send contest announcement: %w", err)
+	}
+
+	return &models.ContestAnnouncementResult{
+		MessageID: message.ID,
+		ChannelID: message.ChannelID,
+		Timestamp: message.Timestamp.Format("2006-01-02T15:04:05Z"),
+	}, nil
+}
+
+// PinMessage pins a message in a channel
+func (b *DiscordBot) PinMessage(ctx context.Context, channelID, messageID string) error {
+	if err := b.api.ChannelMessagePin(channelID, messageID, discordgo.WithContext(ctx)); err != nil {
+		return fmt.Errorf("failed to pin message: %w", err)
+	}
+	return nil
+}
+
+// CreateContestScheduledEvent creates a guild scheduled event for the contest start and returns its ID.
+// The event is external, located at the contest's apply URL.
+func (b *DiscordBot) CreateContestScheduledEvent(ctx context.Context, guildID string, contest *ContestAnnouncement) (string, error) {
+	if contest.StartDate.IsZero() {
+		return "", NewValidationError("contest start date is required for a scheduled event")
+	}
+
+	start := contest.StartDate
+	end := contest.EndDate
+	if end.IsZero() || !end.After(start) {
+		end = start.Add(defaultContestEventDuration)
+	}
+
+	location := contest.ApplyURL
+	if location == "" {
+		location = "GAMERS"
+	}
+
+	event, err := b.api.GuildScheduledEventCreate(guildID, &discordgo.GuildScheduledEventParams{
+		Name:               truncate(contest.Title, 100),
+		Description:        truncate(contest.Description, 1000),
+		ScheduledStartTime: &start,
+		ScheduledEndTime:   &end,
+		PrivacyLevel:       discordgo.GuildScheduledEventPrivacyLevelGuildOnly,
+		EntityType:         discordgo.GuildScheduledEventEntityTypeExternal,
+		EntityMetadata:     &discordgo.GuildScheduledEventEntityMetadata{Location: location},
+	}, discordgo.WithContext(ctx))
+	if err != nil {
+		return "", fmt.Errorf("failed to create scheduled event: %w", err)
+	}
+
+	return event.ID, nil
+}
+
+// buildContestEmbed renders a contest announcement as a Discord embed
+func buildContestEmbed(contest *ContestAnnouncement) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("[大会開催] %s", contest.Title),
+		Description: contest.Description,
+		URL:         contest.ApplyURL,
+		Color:       colorContest,
+		Footer:      &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("Contest #%d", contest.ContestID)},
+		Timestamp:   time.Now().Format(time.RFC3339),
+	}
+
+	if contest.GameType != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "ゲーム",
+			Value:  contest.GameType,
+			Inline: true,
+		})
+	}
+
+	if contest.MaxTeams > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "最大チーム数",
+			Value:  fmt.Sprintf("%dチーム", contest.MaxTeams),
+			Inline: true,
+		})
+	}
+
+	if !contest.StartDate.IsZero() {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "開始日時",
+			Value:  fmt.Sprintf("<t:%d:F> (<t:%d:R>)", contest.StartDate.Unix(), contest.StartDate.Unix()),
+			Inline: false,
+		})
+	}
+
+	if !contest.EndDate.IsZero() {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "終了日時",
+			Value:  fmt.Sprintf("<t:%d:F>", contest.EndDate.Unix()),
+			Inline: false,
+		})
+	}
+
+	return embed
+}
+
+// truncate shortens s to at most max runes
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
@@ -1,8 +1,11 @@
 package bot
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
+	"sync"
+	"sync/atomic"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/gamers-bot/internal/models"
@@ -10,10 +13,19 @@ import (
 
 // DiscordBot wraps the Discord session and provides helper methods
 type DiscordBot struct {
-	Session              *discordgo.Session
+	Session              *discordgo.Session // gateway session; nil for bots created with NewWithAPI
+	api                  DiscordAPI
 	ready                chan struct{}
-	rabbitMQConnected    bool
 	statusNotificationCh chan string
+	organizerRoleID      string
+
+	readyOnce         sync.Once
+	gatewayUp         atomic.Bool // true between Ready/Resumed and the next disconnect
+	rabbitMQConnected atomic.Bool // set by the RabbitMQ supervisor, read by /status
+
+	mu             sync.RWMutex
+	eventPublisher EventPublisher // nil while RabbitMQ is disconnected
+	replicaRole    string         // "active" or "standby" in HA mode, empty otherwise
 }
 
 // New creates a new Discord bot instance
@@ -23,16 +35,14 @@ func New(token string) (*DiscordBot, error) {
 		return nil, fmt.Errorf("failed to create Discord session: %w", err)
 	}
 
-	bot := &DiscordBot{
-		Session:              session,
-		ready:                make(chan struct{}),
-		rabbitMQConnected:    false,
-		statusNotificationCh: make(chan string, 10),
-	}
+	bot := newBot(sessionAPI{session})
+	bot.Session = session
 
 	// Register event handlers
 	session.AddHandler(bot.onReady)
 	session.AddHandler(bot.onInteractionCreate)
+	session.AddHandler(func(_ *discordgo.Session, _ *discordgo.Resumed) { bot.gatewayUp.Store(true) })
+	session.AddHandler(func(_ *discordgo.Session, _ *discordgo.Disconnect) { bot.gatewayUp.Store(false) })
 
 	// Set intents
 	session.Identify.Intents = discordgo.IntentsGuilds |
@@ -42,8 +52,37 @@ func New(token string) (*DiscordBot, error) {
 	return bot, nil
 }
 
+// NewWithAPI creates a bot that calls api instead of opening a gateway session, e.g. with the
+// in-memory fake of package discordtest. Connect marks it ready straight away.
+func NewWithAPI(api DiscordAPI) *DiscordBot {
+	return newBot(api)
+}
+
+// newBot creates a bot calling api
+func newBot(api DiscordAPI) *DiscordBot {
+	return &DiscordBot{
+		api:                  api,
+		ready:                make(chan struct{}),
+		statusNotificationCh: make(chan string, 10),
+	}
+}
+
+// SetOrganizerRoleID sets the role allowed to approve or reject contest applications from Discord.
+// Without a role, nobody can use the application buttons.
+func (b *DiscordBot) SetOrganizerRoleID(roleID string) {
+	b.organizerRoleID = roleID
+	if roleID == "" {
+		slog.Warn("No organizer role configured, application buttons are disabled for everyone")
+	}
+}
+
 // Connect establishes connection to Discord
 func (b *DiscordBot) Connect() error {
+	if b.Session == nil {
+		b.gatewayUp.Store(true)
+		b.readyOnce.Do(func() { close(b.ready) })
+		return nil
+	}
 	if err := b.Session.Open(); err != nil {
 		return fmt.Errorf("failed to open Discord session: %w", err)
 	}
@@ -55,28 +94,72 @@ func (b *DiscordBot) WaitUntilReady() {
 	<-b.ready
 }
 
+// Ready reports whether the gateway connection is up and the bot has received its Ready event
+func (b *DiscordBot) Ready() bool {
+	return b.gatewayUp.Load()
+}
+
+// GuildSummary identifies a guild the bot is connected to
+type GuildSummary struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Guilds returns the guilds the bot is connected to
+func (b *DiscordBot) Guilds() []GuildSummary {
+	state := b.api.State()
+	state.RLock()
+	defer state.RUnlock()
+
+	guilds := make([]GuildSummary, 0, len(state.Guilds))
+	for _, g := range state.Guilds {
+		guilds = append(guilds, GuildSummary{ID: g.ID, Name: g.Name})
+	}
+	return guilds
+}
+
 // Close closes the Discord session
 func (b *DiscordBot) Close() error {
+	if b.Session == nil {
+		return nil
+	}
 	return b.Session.Close()
 }
 
 // onReady is called when the bot is ready
 func (b *DiscordBot) onReady(s *discordgo.Session, event *discordgo.Ready) {
 	slog.Info("Discord bot is ready", "user", event.User.Username)
+	b.gatewayUp.Store(true)
 
 	// Register slash commands
 	if err := b.RegisterCommands(); err != nil {
 		slog.Error("Failed to register commands", "error", err)
 	}
 
-	close(b.ready)
+	// Ready is sent again after every new gateway session
+	b.readyOnce.Do(func() { close(b.ready) })
+}
+
+// onInteractionCreate handles slash command, message component and modal interactions
+func (b *DiscordBot) onInteractionCreate(_ *discordgo.Session, i *discordgo.InteractionCreate) {
+	b.HandleInteraction(i)
 }
 
-// onInteractionCreate handles slash command interactions
-func (b *DiscordBot) onInteractionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	if i.Type != discordgo.InteractionApplicationCommand {
-		return
+// HandleInteraction dispatches an interaction as if it was received from the gateway. Bots created
+// with NewWithAPI have no gateway, so interactions are delivered to them through it.
+func (b *DiscordBot) HandleInteraction(i *discordgo.InteractionCreate) {
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		b.handleApplicationCommand(b.api, i)
+	case discordgo.InteractionMessageComponent:
+		b.handleComponentInteraction(b.api, i)
+	case discordgo.InteractionModalSubmit:
+		b.handleModalSubmit(b.api, i)
 	}
+}
+
+// handleApplicationCommand dispatches slash commands by name
+func (b *DiscordBot) handleApplicationCommand(s DiscordAPI, i *discordgo.InteractionCreate) {
 
 	switch i.ApplicationCommandData().Name {
 	case "author":
@@ -95,7 +178,7 @@ func (b *DiscordBot) onInteractionCreate(s *discordgo.Session, i *discordgo.Inte
 }
 
 // handleAuthorCommand responds with "SONU"
-func (b *DiscordBot) handleAuthorCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (b *DiscordBot) handleAuthorCommand(s DiscordAPI, i *discordgo.InteractionCreate) {
 	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
@@ -107,7 +190,7 @@ func (b *DiscordBot) handleAuthorCommand(s *discordgo.Session, i *discordgo.Inte
 	}
 }
 
-func (b *DiscordBot) handleDamepoCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (b *DiscordBot) handleDamepoCommand(s DiscordAPI, i *discordgo.InteractionCreate) {
 	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
@@ -119,7 +202,7 @@ func (b *DiscordBot) handleDamepoCommand(s *discordgo.Session, i *discordgo.Inte
 	}
 }
 
-func (b *DiscordBot) handleArunoCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (b *DiscordBot) handleArunoCommand(s DiscordAPI, i *discordgo.InteractionCreate) {
 	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
@@ -131,7 +214,7 @@ func (b *DiscordBot) handleArunoCommand(s *discordgo.Session, i *discordgo.Inter
 	}
 }
 
-func (b *DiscordBot) handleReomonCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (b *DiscordBot) handleReomonCommand(s DiscordAPI, i *discordgo.InteractionCreate) {
 	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
@@ -143,7 +226,7 @@ func (b *DiscordBot) handleReomonCommand(s *discordgo.Session, i *discordgo.Inte
 	}
 }
 
-func (b *DiscordBot) handleHonyubinCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (b *DiscordBot) handleHonyubinCommand(s DiscordAPI, i *discordgo.InteractionCreate) {
 	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
@@ -155,17 +238,25 @@ func (b *DiscordBot) handleHonyubinCommand(s *discordgo.Session, i *discordgo.In
 	}
 }
 
-// handleStatusCommand responds with RabbitMQ connection status
-func (b *DiscordBot) handleStatusCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+// handleStatusCommand responds with RabbitMQ connection status and, in HA mode, the replica role
+func (b *DiscordBot) handleStatusCommand(s DiscordAPI, i *discordgo.InteractionCreate) {
 	status := "🔴 Disconnected"
-	if b.rabbitMQConnected {
+	if b.rabbitMQConnected.Load() {
 		status = "🟢 Connected"
 	}
 
+	content := fmt.Sprintf("RabbitMQ Status: %s", status)
+	switch b.ReplicaRole() {
+	case "active":
+		content += "\nReplica Role: 🟢 Active"
+	case "standby":
+		content += "\nReplica Role: 🟡 Standby"
+	}
+
 	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
-			Content: fmt.Sprintf("RabbitMQ Status: %s", status),
+			Content: content,
 		},
 	})
 	if err != nil {
@@ -173,6 +264,20 @@ func (b *DiscordBot) handleStatusCommand(s *discordgo.Session, i *discordgo.Inte
 	}
 }
 
+// SetReplicaRole sets the HA role shown by /status ("active" or "standby"); empty hides it
+func (b *DiscordBot) SetReplicaRole(role string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.replicaRole = role
+}
+
+// ReplicaRole returns the HA role of this replica, or empty if unknown
+func (b *DiscordBot) ReplicaRole() string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.replicaRole
+}
+
 // RegisterCommands registers slash commands with Discord
 func (b *DiscordBot) RegisterCommands() error {
 	commands := []*discordgo.ApplicationCommand{
@@ -203,7 +308,7 @@ func (b *DiscordBot) RegisterCommands() error {
 	}
 
 	for _, cmd := range commands {
-		_, err := b.Session.ApplicationCommandCreate(b.Session.State.User.ID, "", cmd)
+		_, err := b.api.ApplicationCommandCreate(b.api.State().User.ID, "", cmd)
 		if err != nil {
 			return fmt.Errorf("failed to create command %s: %w", cmd.Name, err)
 		}
@@ -215,7 +320,7 @@ func (b *DiscordBot) RegisterCommands() error {
 
 // NotifyRabbitMQStatus updates the RabbitMQ connection status
 func (b *DiscordBot) NotifyRabbitMQStatus(connected bool, err error) {
-	b.rabbitMQConnected = connected
+	b.rabbitMQConnected.Store(connected)
 
 	var message string
 	if connected {
@@ -235,8 +340,8 @@ func (b *DiscordBot) NotifyRabbitMQStatus(connected bool, err error) {
 }
 
 // SendMessage sends a message to a Discord channel
-func (b *DiscordBot) SendMessage(channelID, content string) (*models.SendMessageResult, error) {
-	message, err := b.Session.ChannelMessageSend(channelID, content)
+func (b *DiscordBot) SendMessage(ctx context.Context, channelID, content string) (*models.SendMessageResult, error) {
+	message, err := b.api.ChannelMessageSend(channelID, content, discordgo.WithContext(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to send message: %w", err)
 	}
@@ -248,17 +353,17 @@ func (b *DiscordBot) SendMessage(channelID, content string) (*models.SendMessage
 }
 
 // MoveMembers moves members between voice channels
-func (b *DiscordBot) MoveMembers(guildID, fromChannelID, toChannelID string, userIDs []string) (*models.MoveMembersResult, error) {
+func (b *DiscordBot) MoveMembers(ctx context.Context, guildID, fromChannelID, toChannelID string, userIDs []string) (*models.MoveMembersResult, error) {
 	// Validate that channels are different
 	if fromChannelID == toChannelID {
-		return nil, fmt.Errorf("source and destination channels must be different")
+		return nil, NewValidationError("source and destination channels must be different")
 	}
 
 	// Get members in the source voice channel
-	guild, err := b.Session.State.Guild(guildID)
+	guild, err := b.api.State().Guild(guildID)
 	if err != nil {
 		// If not in state, fetch from API
-		guild, err = b.Session.Guild(guildID)
+		guild, err = b.api.Guild(guildID, discordgo.WithContext(ctx))
 		if err != nil {
 			return nil, fmt.Errorf("failed to get guild: %w", err)
 		}
@@ -290,7 +395,7 @@ func (b *DiscordBot) MoveMembers(guildID, fromChannelID, toChannelID string, use
 	movedCount := 0
 
 	for _, userID := range membersToMove {
-		err := b.Session.GuildMemberMove(guildID, userID, &toChannelID)
+		err := b.api.GuildMemberMove(guildID, userID, &toChannelID, discordgo.WithContext(ctx))
 		if err != nil {
 			slog.Warn("Failed to move user", "user_id", userID, "error", err)
 			failedUsers = append(failedUsers, userID)
@@ -306,8 +411,8 @@ func (b *DiscordBot) MoveMembers(guildID, fromChannelID, toChannelID string, use
 }
 
 // GetVoiceChannels retrieves all voice channels in the guild
-func (b *DiscordBot) GetVoiceChannels(guildID string) (*models.GetChannelsResult, error) {
-	channels, err := b.Session.GuildChannels(guildID)
+func (b *DiscordBot) GetVoiceChannels(ctx context.Context, guildID string) (*models.GetChannelsResult, error) {
+	channels, err := b.api.GuildChannels(guildID, discordgo.WithContext(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get guild channels: %w", err)
 	}
@@ -328,8 +433,8 @@ func (b *DiscordBot) GetVoiceChannels(guildID string) (*models.GetChannelsResult
 }
 
 // GetTextChannels retrieves all text channels in the guild
-func (b *DiscordBot) GetTextChannels(guildID string) (*models.GetChannelsResult, error) {
-	channels, err := b.Session.GuildChannels(guildID)
+func (b *DiscordBot) GetTextChannels(ctx context.Context, guildID string) (*models.GetChannelsResult, error) {
+	channels, err := b.api.GuildChannels(guildID, discordgo.WithContext(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get guild channels: %w", err)
 	}
@@ -350,7 +455,7 @@ func (b *DiscordBot) GetTextChannels(guildID string) (*models.GetChannelsResult,
 }
 
 // SendContestInvitation sends a contest invitation to specified users
-func (b *DiscordBot) SendContestInvitation(channelID string, userIDs []string, contestName, customMessage string) (*models.ContestInvitationResult, error) {
+func (b *DiscordBot) SendContestInvitation(ctx context.Context, channelID string, userIDs []string, contestName, customMessage string) (*models.ContestInvitationResult, error) {
 	// Build mention string
 	var mentions string
 	for _, userID := range userIDs {
@@ -366,7 +471,7 @@ func (b *DiscordBot) SendContestInvitation(channelID string, userIDs []string, c
 	}
 
 	// Send the message
-	message, err := b.Session.ChannelMessageSend(channelID, content)
+	message, err := b.api.ChannelMessageSend(channelID, content, discordgo.WithContext(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to send contest invitation: %w", err)
 	}
@@ -387,8 +492,9 @@ const (
 	StatusRejected  ApplicationStatus = "REJECTED"
 )
 
-// SendApplicationNotification sends a contest application status notification to a user
-func (b *DiscordBot) SendApplicationNotification(channelID, userID, contestTitle string, status ApplicationStatus, processedByDiscordID string) (*models.ApplicationNotificationResult, error) {
+// SendApplicationNotification sends a contest application status notification to a user.
+// Requested notifications carry Approve/Reject buttons for organizers.
+func (b *DiscordBot) SendApplicationNotification(ctx context.Context, channelID, userID, contestTitle string, status ApplicationStatus, processedByDiscordID string, application ApplicationRef) (*models.ApplicationNotificationResult, error) {
 	var content string
 
 	switch status {
@@ -437,8 +543,13 @@ func (b *DiscordBot) SendApplicationNotification(channelID, userID, contestTitle
 		return nil, fmt.Errorf("unknown application status: %s", status)
 	}
 
+	send := &discordgo.MessageSend{Content: content}
+	if status == StatusRequested {
+		send.Components = applicationReviewComponents(application)
+	}
+
 	// Send the message
-	message, err := b.Session.ChannelMessageSend(channelID, content)
+	message, err := b.api.ChannelMessageSendComplex(channelID, send, discordgo.WithContext(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to send application notification: %w", err)
 	}
@@ -467,6 +578,7 @@ const (
 
 // SendTeamInviteNotification sends a team invite notification (sent/accepted/rejected)
 func (b *DiscordBot) SendTeamInviteNotification(
+	ctx context.Context,
 	channelID string,
 	inviterDiscordID string,
 	inviterUsername string,
@@ -505,7 +617,7 @@ func (b *DiscordBot) SendTeamInviteNotification(
 		return nil, fmt.Errorf("unknown team invite event type: %s", eventType)
 	}
 
-	message, err := b.Session.ChannelMessageSend(channelID, content)
+	message, err := b.api.ChannelMessageSend(channelID, content, discordgo.WithContext(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to send team invite notification: %w", err)
 	}
@@ -518,6 +630,7 @@ func (b *DiscordBot) SendTeamInviteNotification(
 
 // SendTeamMemberNotification sends a team member notification (joined/left/kicked)
 func (b *DiscordBot) SendTeamMemberNotification(
+	ctx context.Context,
 	channelID string,
 	discordUserID string,
 	username string,
@@ -554,7 +667,7 @@ func (b *DiscordBot) SendTeamMemberNotification(
 		return nil, fmt.Errorf("unknown team member event type: %s", eventType)
 	}
 
-	message, err := b.Session.ChannelMessageSend(channelID, content)
+	message, err := b.api.ChannelMessageSend(channelID, content, discordgo.WithContext(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to send team member notification: %w", err)
 	}
@@ -567,6 +680,7 @@ func (b *DiscordBot) SendTeamMemberNotification(
 
 // SendTeamStatusNotification sends a team status notification (finalized/deleted/leadership)
 func (b *DiscordBot) SendTeamStatusNotification(
+	ctx context.Context,
 	channelID string,
 	leaderDiscordID string,
 	memberCount int,
@@ -598,7 +712,7 @@ func (b *DiscordBot) SendTeamStatusNotification(
 		return nil, fmt.Errorf("unknown team status event type: %s", eventType)
 	}
 
-	message, err := b.Session.ChannelMessageSend(channelID, content)
+	message, err := b.api.ChannelMessageSend(channelID, content, discordgo.WithContext(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to send team status notification: %w", err)
 	}
@@ -610,15 +724,15 @@ func (b *DiscordBot) SendTeamStatusNotification(
 }
 
 // SendDirectMessage sends a DM to a user
-func (b *DiscordBot) SendDirectMessage(userID string, content string) (*models.TeamNotificationResult, error) {
+func (b *DiscordBot) SendDirectMessage(ctx context.Context, userID string, content string) (*models.TeamNotificationResult, error) {
 	// Create a DM channel with the user
-	channel, err := b.Session.UserChannelCreate(userID)
+	channel, err := b.api.UserChannelCreate(userID, discordgo.WithContext(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create DM channel: %w", err)
 	}
 
 	// Send the message
-	message, err := b.Session.ChannelMessageSend(channel.ID, content)
+	message, err := b.api.ChannelMessageSend(channel.ID, content, discordgo.WithContext(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to send DM: %w", err)
 	}
@@ -0,0 +1,496 @@
+// Package discordtest provides an in-memory implementation of bot.DiscordAPI, so the bot and
+// its handlers can run without a Discord token or network access.
+package discordtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/gamers-bot/internal/bot"
+)
+
+var _ bot.DiscordAPI = (*Fake)(nil)
+
+// BotUserID is the user ID of the fake bot user
+const BotUserID = "100000000000000000"
+
+// dmChannelPrefix prefixes the IDs of direct message channels created by UserChannelCreate
+const dmChannelPrefix = "dm-"
+
+// Message is a message sent or edited through the fake
+type Message struct {
+	ID         string
+	ChannelID  string
+	UserID     string // recipient of a direct message; empty for channel messages
+	Content    string
+	Embeds     []*discordgo.MessageEmbed
+	Components []discordgo.MessageComponent
+	Pinned     bool
+	Edited     bool
+}
+
+// Move is a member moved between voice channels
+type Move struct {
+	GuildID   string
+	UserID    string
+	ChannelID string
+}
+
+// InteractionResponse is a response to an interaction
+type InteractionResponse struct {
+	InteractionID string
+	Response      *discordgo.InteractionResponse
+}
+
+// Fake is an in-memory Discord API. It serves guilds and channels added with AddGuild and
+// records everything the bot sends. It is safe for concurrent use.
+type Fake struct {
+	mu      sync.Mutex
+	state   *discordgo.State
+	latency time.Duration
+	nextID  int64
+
+	messages        []*Message
+	moves           []Move
+	scheduledEvents []*discordgo.GuildScheduledEvent
+	commands        []*discordgo.ApplicationCommand
+	responses       []InteractionResponse
+	failures        map[string]error
+	hanging         map[string]bool
+}
+
+// New creates an empty Fake with a bot user
+func New() *Fake {
+	state := discordgo.NewState()
+	state.User = &discordgo.User{ID: BotUserID, Username: "gamers-bot", Bot: true}
+	return &Fake{
+		state:    state,
+		nextID:   200000000000000000,
+		failures: make(map[string]error),
+		hanging:  make(map[string]bool),
+	}
+}
+
+// AddGuild adds a guild, with its channels and voice states, to the state
+func (f *Fake) AddGuild(guild *discordgo.Guild) {
+	if err := f.state.GuildAdd(guild); err != nil {
+		panic(fmt.Sprintf("discordtest: failed to add guild: %v", err))
+	}
+}
+
+// SetHeartbeatLatency sets the latency reported by HeartbeatLatency
+func (f *Fake) SetHeartbeatLatency(latency time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.latency = latency
+}
+
+// Fail makes every call of the named method (e.g. "ChannelMessageSend") return err until it is
+// cleared with a nil err
+func (f *Fake) Fail(method string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err == nil {
+		delete(f.failures, method)
+		return
+	}
+	f.failures[method] = err
+}
+
+// Hang makes every call of the named method block until its request context is done, like a
+// REST call stuck on the network, until it is cleared with hang false. Calls made without
+// discordgo.WithContext block forever.
+func (f *Fake) Hang(method string, hang bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !hang {
+		delete(f.hanging, method)
+		return
+	}
+	f.hanging[method] = true
+}
+
+// RESTError returns a Discord REST API error with the given HTTP status and Discord error code,
+// as the session returns it, for use with Fail
+func RESTError(status, code int, message string) error {
+	body, _ := json.Marshal(discordgo.APIErrorMessage{Code: code, Message: message})
+	return &discordgo.RESTError{
+		Response:     &http.Response{StatusCode: status, Status: strconv.Itoa(status) + " " + http.StatusText(status)},
+		ResponseBody: body,
+		Message:      &discordgo.APIErrorMessage{Code: code, Message: message},
+	}
+}
+
+// Messages returns the messages sent to channels, in order
+func (f *Fake) Messages() []Message {
+	return f.collectMessages(func(m *Message) bool { return m.UserID == "" })
+}
+
+// DirectMessages returns the direct messages sent to users, in order
+func (f *Fake) DirectMessages() []Message {
+	return f.collectMessages(func(m *Message) bool { return m.UserID != "" })
+}
+
+// Embeds returns the embeds of every message, sent or edited, in order
+func (f *Fake) Embeds() []*discordgo.MessageEmbed {
+	var embeds []*discordgo.MessageEmbed
+	for _, m := range f.collectMessages(func(*Message) bool { return true }) {
+		embeds = append(embeds, m.Embeds...)
+	}
+	return embeds
+}
+
+// Moves returns the voice channel moves, in order
+func (f *Fake) Moves() []Move {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.moves)
+}
+
+// ScheduledEvents returns the scheduled events created, in order
+func (f *Fake) ScheduledEvents() []*discordgo.GuildScheduledEvent {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.scheduledEvents)
+}
+
+// Commands returns the application commands registered, in order
+func (f *Fake) Commands() []*discordgo.ApplicationCommand {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.commands)
+}
+
+// InteractionResponses returns the interaction responses, in order
+func (f *Fake) InteractionResponses() []InteractionResponse {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.responses)
+}
+
+// Reset forgets everything recorded, keeping the state and configured failures
+func (f *Fake) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.messages = nil
+	f.moves = nil
+	f.scheduledEvents = nil
+	f.commands = nil
+	f.responses = nil
+}
+
+// collectMessages returns copies of the messages matching keep. The caller must not hold f.mu.
+func (f *Fake) collectMessages(keep func(*Message) bool) []Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var messages []Message
+	for _, m := range f.messages {
+		if keep(m) {
+			messages = append(messages, *m)
+		}
+	}
+	return messages
+}
+
+// State implements bot.DiscordAPI
+func (f *Fake) State() *discordgo.State {
+	return f.state
+}
+
+// HeartbeatLatency implements bot.DiscordAPI
+func (f *Fake) HeartbeatLatency() time.Duration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.latency
+}
+
+// ChannelMessageSend implements bot.DiscordAPI
+func (f *Fake) ChannelMessageSend(channelID, content string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return f.send("ChannelMessageSend", channelID, &discordgo.MessageSend{Content: content}, options)
+}
+
+// ChannelMessageSendComplex implements bot.DiscordAPI
+func (f *Fake) ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return f.send("ChannelMessageSendComplex", channelID, data, options)
+}
+
+// ChannelMessageSendEmbed implements bot.DiscordAPI
+func (f *Fake) ChannelMessageSendEmbed(channelID string, embed *discordgo.MessageEmbed, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return f.send("ChannelMessageSendEmbed", channelID, &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{embed}}, options)
+}
+
+// ChannelMessageEditEmbed implements bot.DiscordAPI
+func (f *Fake) ChannelMessageEditEmbed(channelID, messageID string, embed *discordgo.MessageEmbed, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	if err := f.wait("ChannelMessageEditEmbed", options); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.failure("ChannelMessageEditEmbed"); err != nil {
+		return nil, err
+	}
+
+	m := f.findMessage(channelID, messageID)
+	if m == nil {
+		return nil, RESTError(http.StatusNotFound, discordgo.ErrCodeUnknownMessage, "Unknown Message")
+	}
+	m.Embeds = []*discordgo.MessageEmbed{embed}
+	m.Edited = true
+	return f.toDiscord(m), nil
+}
+
+// ChannelMessagePin implements bot.DiscordAPI
+func (f *Fake) ChannelMessagePin(channelID, messageID string, options ...discordgo.RequestOption) error {
+	if err := f.wait("ChannelMessagePin", options); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.failure("ChannelMessagePin"); err != nil {
+		return err
+	}
+
+	m := f.findMessage(channelID, messageID)
+	if m == nil {
+		return RESTError(http.StatusNotFound, discordgo.ErrCodeUnknownMessage, "Unknown Message")
+	}
+	m.Pinned = true
+	return nil
+}
+
+// UserChannelCreate implements bot.DiscordAPI
+func (f *Fake) UserChannelCreate(recipientID string, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	if err := f.wait("UserChannelCreate", options); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.failure("UserChannelCreate"); err != nil {
+		return nil, err
+	}
+	return &discordgo.Channel{
+		ID:         dmChannelPrefix + recipientID,
+		Type:       discordgo.ChannelTypeDM,
+		Recipients: []*discordgo.User{{ID: recipientID}},
+	}, nil
+}
+
+// Guild implements bot.DiscordAPI
+func (f *Fake) Guild(guildID string, options ...discordgo.RequestOption) (*discordgo.Guild, error) {
+	if err := f.wait("Guild", options); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	err := f.failure("Guild")
+	f.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	guild, err := f.state.Guild(guildID)
+	if err != nil {
+		return nil, RESTError(http.StatusNotFound, discordgo.ErrCodeUnknownGuild, "Unknown Guild")
+	}
+	return guild, nil
+}
+
+// GuildChannels implements bot.DiscordAPI
+func (f *Fake) GuildChannels(guildID string, options ...discordgo.RequestOption) ([]*discordgo.Channel, error) {
+	guild, err := f.Guild(guildID, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.failure("GuildChannels"); err != nil {
+		return nil, err
+	}
+	f.state.RLock()
+	defer f.state.RUnlock()
+	return slices.Clone(guild.Channels), nil
+}
+
+// GuildMemberMove implements bot.DiscordAPI
+func (f *Fake) GuildMemberMove(guildID, userID string, channelID *string, options ...discordgo.RequestOption) error {
+	if err := f.wait("GuildMemberMove", options); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.failure("GuildMemberMove"); err != nil {
+		return err
+	}
+
+	move := Move{GuildID: guildID, UserID: userID}
+	if channelID != nil {
+		move.ChannelID = *channelID
+	}
+	f.moves = append(f.moves, move)
+	return nil
+}
+
+// GuildScheduledEventCreate implements bot.DiscordAPI
+func (f *Fake) GuildScheduledEventCreate(guildID string, params *discordgo.GuildScheduledEventParams, options ...discordgo.RequestOption) (*discordgo.GuildScheduledEvent, error) {
+	if err := f.wait("GuildScheduledEventCreate", options); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.failure("GuildScheduledEventCreate"); err != nil {
+		return nil, err
+	}
+
+	event := &discordgo.GuildScheduledEvent{
+		ID:               f.newID(),
+		GuildID:          guildID,
+		Name:             params.Name,
+		Description:      params.Description,
+		ScheduledEndTime: params.ScheduledEndTime,
+		EntityType:       params.EntityType,
+		PrivacyLevel:     params.PrivacyLevel,
+		Status:           discordgo.GuildScheduledEventStatusScheduled,
+	}
+	if params.ScheduledStartTime != nil {
+		event.ScheduledStartTime = *params.ScheduledStartTime
+	}
+	if params.EntityMetadata != nil {
+		event.EntityMetadata = *params.EntityMetadata
+	}
+	f.scheduledEvents = append(f.scheduledEvents, event)
+	return event, nil
+}
+
+// ApplicationCommandCreate implements bot.DiscordAPI
+func (f *Fake) ApplicationCommandCreate(appID, guildID string, cmd *discordgo.ApplicationCommand, options ...discordgo.RequestOption) (*discordgo.ApplicationCommand, error) {
+	if err := f.wait("ApplicationCommandCreate", options); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.failure("ApplicationCommandCreate"); err != nil {
+		return nil, err
+	}
+
+	created := *cmd
+	created.ID = f.newID()
+	created.ApplicationID = appID
+	created.GuildID = guildID
+	f.commands = append(f.commands, &created)
+	return &created, nil
+}
+
+// InteractionRespond implements bot.DiscordAPI
+func (f *Fake) InteractionRespond(interaction *discordgo.Interaction, resp *discordgo.InteractionResponse, options ...discordgo.RequestOption) error {
+	if err := f.wait("InteractionRespond", options); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.failure("InteractionRespond"); err != nil {
+		return err
+	}
+	f.responses = append(f.responses, InteractionResponse{InteractionID: interaction.ID, Response: resp})
+	return nil
+}
+
+// send records a message sent with method
+func (f *Fake) send(method, channelID string, data *discordgo.MessageSend, options []discordgo.RequestOption) (*discordgo.Message, error) {
+	if err := f.wait(method, options); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.failure(method); err != nil {
+		return nil, err
+	}
+
+	m := &Message{
+		ID:         f.newID(),
+		ChannelID:  channelID,
+		Content:    data.Content,
+		Embeds:     slices.Clone(data.Embeds),
+		Components: slices.Clone(data.Components),
+	}
+	if userID, ok := strings.CutPrefix(channelID, dmChannelPrefix); ok {
+		m.UserID = userID
+	}
+	if data.Embed != nil {
+		m.Embeds = append(m.Embeds, data.Embed)
+	}
+	f.messages = append(f.messages, m)
+	return f.toDiscord(m), nil
+}
+
+// wait blocks a call of method while it hangs and returns the error the session returns for a
+// request whose context is done. The caller must not hold f.mu.
+func (f *Fake) wait(method string, options []discordgo.RequestOption) error {
+	ctx := requestContext(options)
+	f.mu.Lock()
+	hanging := f.hanging[method]
+	f.mu.Unlock()
+	if hanging {
+		<-ctx.Done()
+	}
+	if err := ctx.Err(); err != nil {
+		return &url.Error{Op: http.MethodPost, URL: discordgo.EndpointAPI + method, Err: err}
+	}
+	return nil
+}
+
+// requestContext returns the context the options give a request
+func requestContext(options []discordgo.RequestOption) context.Context {
+	req, err := http.NewRequest(http.MethodPost, discordgo.EndpointAPI, nil)
+	if err != nil {
+		panic(fmt.Sprintf("discordtest: failed to build request: %v", err))
+	}
+	cfg := &discordgo.RequestConfig{Request: req}
+	for _, option := range options {
+		option(cfg)
+	}
+	return cfg.Request.Context()
+}
+
+// failure returns the configured error of method. The caller must hold f.mu.
+func (f *Fake) failure(method string) error {
+	return f.failures[method]
+}
+
+// findMessage returns a recorded message. The caller must hold f.mu.
+func (f *Fake) findMessage(channelID, messageID string) *Message {
+	for _, m := range f.messages {
+		if m.ChannelID == channelID && m.ID == messageID {
+			return m
+		}
+	}
+	return nil
+}
+
+// newID returns a new snowflake-like ID. The caller must hold f.mu.
+func (f *Fake) newID() string {
+	f.nextID++
+	return strconv.FormatInt(f.nextID, 10)
+}
+
+// toDiscord converts a recorded message to the message the API would return
+func (f *Fake) toDiscord(m *Message) *discordgo.Message {
+	return &discordgo.Message{
+		ID:         m.ID,
+		ChannelID:  m.ChannelID,
+		Content:    m.Content,
+		Embeds:     slices.Clone(m.Embeds),
+		Components: slices.Clone(m.Components),
+		Pinned:     m.Pinned,
+		Timestamp:  time.Now().UTC(),
+		Author:     f.state.User,
+	}
+}
@@ -0,0 +1,157 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ErrorKind classifies an error for retry decisions
+type ErrorKind string
+
+const (
+	// ErrorKindTransient errors may succeed when retried later (Discord 5xx, rate limits, timeouts)
+	ErrorKindTransient ErrorKind = "TRANSIENT"
+	// ErrorKindPermanent errors will fail again on retry (validation errors, missing access, unknown channel)
+	ErrorKindPermanent ErrorKind = "PERMANENT"
+	// ErrorKindCanceled errors were caused by the caller giving up (e.g. shutdown) and should be requeued
+	ErrorKindCanceled ErrorKind = "CANCELED"
+)
+
+// ErrorCode is a machine-readable error code returned to the WAS in error responses
+type ErrorCode string
+
+const (
+	ErrCodeValidation         ErrorCode = "VALIDATION_FAILED"
+	ErrCodeInvalidPayload     ErrorCode = "INVALID_PAYLOAD"
+	ErrCodeUnsupportedEvent   ErrorCode = "UNSUPPORTED_EVENT"
+	ErrCodeRateLimited        ErrorCode = "DISCORD_RATE_LIMITED"
+	ErrCodeDiscordUnavailable ErrorCode = "DISCORD_UNAVAILABLE"
+	ErrCodeNotConnected       ErrorCode = "DISCORD_NOT_CONNECTED"
+	ErrCodeMissingAccess      ErrorCode = "DISCORD_MISSING_ACCESS"
+	ErrCodeMissingPermissions ErrorCode = "DISCORD_MISSING_PERMISSIONS"
+	ErrCodeUnknownChannel     ErrorCode = "DISCORD_UNKNOWN_CHANNEL"
+	ErrCodeUnknownResource    ErrorCode = "DISCORD_UNKNOWN_RESOURCE"
+	ErrCodeCannotMessageUser  ErrorCode = "DISCORD_CANNOT_MESSAGE_USER"
+	ErrCodeDiscordRequest     ErrorCode = "DISCORD_REQUEST_FAILED"
+	ErrCodeTimeout            ErrorCode = "TIMEOUT"
+	ErrCodeCanceled           ErrorCode = "CANCELED"
+	ErrCodeNetwork            ErrorCode = "NETWORK_ERROR"
+	ErrCodeInternal           ErrorCode = "INTERNAL_ERROR"
+	ErrCodeHandlerPanic       ErrorCode = "HANDLER_PANIC"
+)
+
+// Error is an error annotated with its kind and code
+type Error struct {
+	Kind ErrorKind
+	Code ErrorCode
+	Err  error
+}
+
+// NewError annotates err with a kind and code
+func NewError(kind ErrorKind, code ErrorCode, err error) *Error {
+	return &Error{Kind: kind, Code: code, Err: err}
+}
+
+// NewValidationError returns a permanent validation error with the given message
+func NewValidationError(format string, args ...interface{}) error {
+	return NewError(ErrorKindPermanent, ErrCodeValidation, fmt.Errorf(format, args...))
+}
+
+// NewInvalidPayloadError returns a permanent error for payloads that cannot be decoded
+func NewInvalidPayloadError(format string, args ...interface{}) error {
+	return NewError(ErrorKindPermanent, ErrCodeInvalidPayload, fmt.Errorf(format, args...))
+}
+
+// Error returns the underlying error message
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Classify determines the kind and code of an error.
+// Explicitly annotated errors win; otherwise Discord REST errors, context errors and network
+// errors are inspected. Only network failures, timeouts, rate limits and Discord 5xx responses are
+// transient. Unrecognised errors are treated as permanent, since retrying a handler that failed
+// deterministically would fail again and may repeat the messages it already sent.
+func Classify(err error) (ErrorKind, ErrorCode) {
+	if err == nil {
+		return "", ""
+	}
+
+	var annotated *Error
+	if errors.As(err, &annotated) {
+		return annotated.Kind, annotated.Code
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return ErrorKindCanceled, ErrCodeCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorKindTransient, ErrCodeTimeout
+	case errors.Is(err, discordgo.ErrWSNotFound):
+		return ErrorKindTransient, ErrCodeNotConnected
+	}
+
+	var rateLimitErr *discordgo.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		return ErrorKindTransient, ErrCodeRateLimited
+	}
+
+	var restErr *discordgo.RESTError
+	if errors.As(err, &restErr) {
+		return classifyRESTError(restErr)
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return ErrorKindTransient, ErrCodeNetwork
+	}
+
+	return ErrorKindPermanent, ErrCodeInternal
+}
+
+// classifyRESTError classifies a Discord REST API error by JSON error code and HTTP status
+func classifyRESTError(restErr *discordgo.RESTError) (ErrorKind, ErrorCode) {
+	if restErr.Message != nil {
+		switch restErr.Message.Code {
+		case discordgo.ErrCodeUnknownChannel:
+			return ErrorKindPermanent, ErrCodeUnknownChannel
+		case discordgo.ErrCodeUnknownGuild, discordgo.ErrCodeUnknownMember,
+			discordgo.ErrCodeUnknownMessage, discordgo.ErrCodeUnknownUser:
+			return ErrorKindPermanent, ErrCodeUnknownResource
+		case discordgo.ErrCodeMissingAccess:
+			return ErrorKindPermanent, ErrCodeMissingAccess
+		case discordgo.ErrCodeMissingPermissions:
+			return ErrorKindPermanent, ErrCodeMissingPermissions
+		case discordgo.ErrCodeCannotSendMessagesToThisUser:
+			return ErrorKindPermanent, ErrCodeCannotMessageUser
+		}
+	}
+
+	if restErr.Response == nil {
+		return ErrorKindTransient, ErrCodeDiscordRequest
+	}
+
+	status := restErr.Response.StatusCode
+	switch {
+	case status == http.StatusTooManyRequests:
+		return ErrorKindTransient, ErrCodeRateLimited
+	case status >= 500:
+		return ErrorKindTransient, ErrCodeDiscordUnavailable
+	case status == http.StatusForbidden:
+		return ErrorKindPermanent, ErrCodeMissingAccess
+	case status == http.StatusNotFound:
+		return ErrorKindPermanent, ErrCodeUnknownResource
+	default:
+		return ErrorKindPermanent, ErrCodeDiscordRequest
+	}
+}
@@ -0,0 +1,50 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestClassify(t *testing.T) {
+	restError := func(status int, code int) error {
+		err := &discordgo.RESTError{Response: &http.Response{StatusCode: status}}
+		if code != 0 {
+			err.Message = &discordgo.APIErrorMessage{Code: code}
+		}
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	tests := []struct {
+		name     string
+		err      error
+		wantKind ErrorKind
+		wantCode ErrorCode
+	}{
+		{"annotated", NewValidationError("contest_name is required"), ErrorKindPermanent, ErrCodeValidation},
+		{"canceled", fmt.Errorf("failed to send message: %w", context.Canceled), ErrorKindCanceled, ErrCodeCanceled},
+		{"deadline", context.DeadlineExceeded, ErrorKindTransient, ErrCodeTimeout},
+		{"rate limited", restError(http.StatusTooManyRequests, 0), ErrorKindTransient, ErrCodeRateLimited},
+		{"rate limit error", &discordgo.RateLimitError{RateLimit: &discordgo.RateLimit{}}, ErrorKindTransient, ErrCodeRateLimited},
+		{"server error", restError(http.StatusBadGateway, 0), ErrorKindTransient, ErrCodeDiscordUnavailable},
+		{"network", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, ErrorKindTransient, ErrCodeNetwork},
+		{"unknown channel", restError(http.StatusNotFound, discordgo.ErrCodeUnknownChannel), ErrorKindPermanent, ErrCodeUnknownChannel},
+		{"missing access", restError(http.StatusForbidden, 0), ErrorKindPermanent, ErrCodeMissingAccess},
+		{"bad request", restError(http.StatusBadRequest, 0), ErrorKindPermanent, ErrCodeDiscordRequest},
+		{"unknown application status", fmt.Errorf("unknown application status: %s", "WITHDRAWN"), ErrorKindPermanent, ErrCodeInternal},
+		{"unknown game event type", errors.New("unknown game event type: game.paused"), ErrorKindPermanent, ErrCodeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kind, code := Classify(tt.err)
+			if kind != tt.wantKind || code != tt.wantCode {
+				t.Errorf("Classify(%v) = %s, %s; want %s, %s", tt.err, kind, code, tt.wantKind, tt.wantCode)
+			}
+		})
+	}
+}
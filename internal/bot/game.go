@@ -0,0 +1,325 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/gamers-bot/internal/models"
+)
+
+// GameEventType represents the type of game lifecycle notification
+type GameEventType string
+
+const (
+	GameScheduled      GameEventType = "SCHEDULED"
+	GameActivated      GameEventType = "ACTIVATED"
+	GameMatchDetecting GameEventType = "MATCH_DETECTING"
+	GameMatchDetected  GameEventType = "MATCH_DETECTED"
+	GameMatchFailed    GameEventType = "MATCH_FAILED"
+	GameFinished       GameEventType = "FINISHED"
+)
+
+// Embed colors for game notifications
+const (
+	colorScheduled = 0x3498DB // blue
+	colorActivated = 0x2ECC71 // green
+	colorDetecting = 0xF1C40F // yellow
+	colorDetected  = 0x1ABC9C // teal
+	colorFailed    = 0xE74C3C // red
+	colorFinished  = 0x9B59B6 // purple
+)
+
+// GameNotification contains the game information rendered into a status embed.
+// Zero-valued fields are omitted from the embed. It is stored between events so that
+// the status message keeps information that later events do not repeat.
+type GameNotification struct {
+	GameID        int64     `json:"game_id"`
+	ContestID     int64     `json:"contest_id"`
+	GameName      string    `json:"game_name,omitempty"`
+	ScheduledAt   time.Time `json:"scheduled_at"`
+	Teams         []string  `json:"teams,omitempty"`
+	DetectedCount int       `json:"detected_count,omitempty"`
+	RequiredCount int       `json:"required_count,omitempty"`
+	MatchID       string    `json:"match_id,omitempty"`
+	FailureReason string    `json:"failure_reason,omitempty"`
+	WinnerTeam    string    `json:"winner_team,omitempty"`
+	Result        string    `json:"result,omitempty"`
+}
+
+// Merge overwrites fields of g with the non-zero fields of update
+func (g *GameNotification) Merge(update *GameNotification) {
+	if update.GameID != 0 {
+		g.GameID = update.GameID
+	}
+	if update.ContestID != 0 {
+		g.ContestID = update.ContestID
+	}
+	if update.GameName != "" {
+		g.GameName = update.GameName
+	}
+	if !update.ScheduledAt.IsZero() {
+		g.ScheduledAt = update.ScheduledAt
+	}
+	if len(update.Teams) > 0 {
+		g.Teams = update.Teams
+	}
+	if update.RequiredCount > 0 {
+		g.DetectedCount = update.DetectedCount
+		g.RequiredCount = update.RequiredCount
+	}
+	if update.MatchID != "" {
+		g.MatchID = update.MatchID
+	}
+	if update.FailureReason != "" {
+		g.FailureReason = update.FailureReason
+	}
+	if update.WinnerTeam != "" {
+		g.WinnerTeam = update.WinnerTeam
+	}
+	if update.Result != "" {
+		g.Result = update.Result
+	}
+}
+
+// SendGameNotification posts a game status embed to a channel
+func (b *DiscordBot) SendGameNotification(ctx context.Context, channelID string, game *GameNotification, eventType GameEventType) (*models.GameNotificationResult, error) {
+	embed, err := buildGameEmbed(game, eventType)
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := b.api.ChannelMessageSendEmbed(channelID, embed, discordgo.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to send game notification: %w", err)
+	}
+
+	return &models.GameNotificationResult{
+		MessageID: message.ID,
+		ChannelID: message.ChannelID,
+		Timestamp: message.Timestamp.Format("2006-01-02T15:04:05Z"),
+	}, nil
+}
+
+// EditGameNotification re-renders an existing game status message in place
+func (b *DiscordBot) EditGameNotification(ctx context.Context, channelID, messageID string, game *GameNotification, eventType GameEventType) (*models.GameNotificationResult, error) {
+	embed, err := buildGameEmbed(game, eventType)
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := b.api.ChannelMessageEditEmbed(channelID, messageID, embed, discordgo.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to edit game notification: %w", err)
+	}
+
+	timestamp := message.Timestamp
+	if message.EditedTimestamp != nil {
+		timestamp = *message.EditedTimestamp
+	}
+
+	return &models.GameNotificationResult{
+		MessageID: message.ID,
+		ChannelID: message.ChannelID,
+		Timestamp: timestamp.Format("2006-01-02T15:04:05Z"),
+		Edited:    true,
+	}, nil
+}
+
+// buildGameEmbed renders the game status for the given stage as a Discord embed
+func buildGameEmbed(game *GameNotification, eventType GameEventType) (*discordgo.MessageEmbed, error) {
+	embed := &discordgo.MessageEmbed{
+		Footer:    &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("Game #%d", game.GameID)},
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+
+	switch eventType {
+	case GameScheduled:
+		embed.Title = "[ゲーム予定]"
+		embed.Description = "ゲームのスケジュールが決まりました。"
+		embed.Color = colorScheduled
+	case GameActivated:
+		embed.Title = "[ゲーム開始]"
+		embed.Description = "ゲームが開始されました！準備はいいですか？"
+		embed.Color = colorActivated
+	case GameMatchDetecting:
+		embed.Title = "[マッチ検出中]"
+		embed.Description = "ゲーム内のマッチを検出しています。しばらくお待ちください。"
+		embed.Color = colorDetecting
+	case GameMatchDetected:
+		embed.Title = "[マッチ検出完了]"
+		embed.Description = "マッチが検出されました。健闘を祈ります！"
+		embed.Color = colorDetected
+	case GameMatchFailed:
+		embed.Title = "[マッチ検出失敗]"
+		embed.Description = "マッチを検出できませんでした。運営人の案内をお待ちください。"
+		embed.Color = colorFailed
+	case GameFinished:
+		embed.Title = "[ゲーム終了]"
+		embed.Description = "ゲームが終了しました。お疲れ様でした！"
+		embed.Color = colorFinished
+	default:
+		return nil, fmt.Errorf("unknown game event type: %s", eventType)
+	}
+
+	if game.GameName != "" {
+		embed.Title = fmt.Sprintf("%s %s", embed.Title, game.GameName)
+	}
+
+	if !game.ScheduledAt.IsZero() {
+		unix := game.ScheduledAt.Unix()
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "開始予定",
+			Value:  fmt.Sprintf("<t:%d:F> (<t:%d:R>)", unix, unix),
+			Inline: false,
+		})
+	}
+
+	if len(game.Teams) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "参加チーム",
+			Value:  formatTeams(game.Teams),
+			Inline: false,
+		})
+	}
+
+	if game.RequiredCount > 0 && (eventType == GameMatchDetecting || eventType == GameMatchDetected) {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "検出状況",
+			Value:  formatProgress(game.DetectedCount, game.RequiredCount),
+			Inline: false,
+		})
+	}
+
+	if game.MatchID != "" && eventType == GameMatchDetected {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "マッチID",
+			Value:  game.MatchID,
+			Inline: true,
+		})
+	}
+
+	if eventType == GameMatchFailed {
+		reason := game.FailureReason
+		if reason == "" {
+			reason = "不明"
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "理由",
+			Value:  reason,
+			Inline: false,
+		})
+	}
+
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+		Name:   "進行状況",
+		Value:  formatStages(eventType),
+		Inline: false,
+	})
+
+	if eventType == GameFinished {
+		if game.WinnerTeam != "" {
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+				Name:   "勝者",
+				Value:  fmt.Sprintf("🏆 **%s**", game.WinnerTeam),
+				Inline: true,
+			})
+		}
+		if game.Result != "" {
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+				Name:   "結果",
+				Value:  game.Result,
+				Inline: true,
+			})
+		}
+	}
+
+	return embed, nil
+}
+
+// gameStages is the order of stages shown in the status timeline
+var gameStages = []struct {
+	eventType GameEventType
+	label     string
+}{
+	{GameScheduled, "予定"},
+	{GameActivated, "開始"},
+	{GameMatchDetecting, "マッチ検出"},
+	{GameMatchDetected, "マッチ確定"},
+	{GameFinished, "終了"},
+}
+
+// StageIndex returns the position of a stage in the game lifecycle. A failed detection shares
+// the detection stage, since detection can be retried after it fails.
+func (t GameEventType) StageIndex() int {
+	if t == GameMatchFailed {
+		t = GameMatchDetecting
+	}
+	for i, stage := range gameStages {
+		if stage.eventType == t {
+			return i
+		}
+	}
+	return 0
+}
+
+// formatStages renders the lifecycle timeline with the current stage highlighted,
+// e.g. "✅ 予定 → ✅ 開始 → ⏳ マッチ検出 → ▫️ マッチ確定 → ▫️ 終了"
+func formatStages(current GameEventType) string {
+	// A failed detection is shown on the detection stage
+	failed := current == GameMatchFailed
+	if failed {
+		current = GameMatchDetecting
+	}
+
+	currentIndex := current.StageIndex()
+
+	parts := make([]string, len(gameStages))
+	for i, stage := range gameStages {
+		var mark string
+		switch {
+		case i < currentIndex || (i == currentIndex && current == GameFinished):
+			mark = "✅"
+		case i == currentIndex && failed:
+			mark = "❌"
+		case i == currentIndex:
+			mark = "⏳"
+		default:
+			mark = "▫️"
+		}
+		parts[i] = mark + " " + stage.label
+	}
+	return strings.Join(parts, " → ")
+}
+
+// formatTeams renders team names as a "vs" line for two teams or a bulleted list otherwise
+func formatTeams(teams []string) string {
+	if len(teams) == 2 {
+		return fmt.Sprintf("**%s** vs **%s**", teams[0], teams[1])
+	}
+
+	var sb strings.Builder
+	for _, team := range teams {
+		sb.WriteString("• ")
+		sb.WriteString(team)
+		sb.WriteString("\n")
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// formatProgress renders match-detection progress as a bar, e.g. "▰▰▰▱▱ 3/5"
+func formatProgress(current, total int) string {
+	if current > total {
+		current = total
+	}
+	if current < 0 {
+		current = 0
+	}
+	return fmt.Sprintf("%s%s %d/%d",
+		strings.Repeat("▰", current),
+		strings.Repeat("▱", total-current),
+		current, total,
+	)
+}
@@ -0,0 +1,423 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/gamers-bot/internal/models"
+)
+
+// Events published back to the web server when users click message components
+const (
+	CommandTeamInviteAcceptRequested   = "team.invite.accept_requested"
+	CommandTeamInviteRejectRequested   = "team.invite.reject_requested"
+	CommandApplicationApproveRequested = "application.approve_requested"
+	CommandApplicationRejectRequested  = "application.reject_requested"
+)
+
+// Component custom ID prefixes. Custom IDs have the form "<prefix>:<action>:<args...>".
+const (
+	componentTeamInvite        = "team_invite"
+	componentApplication       = "application"
+	modalApplicationReject     = "application_reject"
+	applicationReasonInputID   = "reason"
+	applicationReasonMaxLength = 500
+)
+
+// eventPublishTimeout bounds publishing so interactions are answered within Discord's 3 second window
+const eventPublishTimeout = 2 * time.Second
+
+// EventPublisher publishes bot-originated events back to the web server.
+// The publisher fills in the event envelope (event_id, event_type, timestamp).
+type EventPublisher interface {
+	PublishEvent(ctx context.Context, eventType, correlationID string, payload interface{}) error
+}
+
+// SetEventPublisher sets the publisher used for bot-originated events.
+// Pass nil while RabbitMQ is disconnected; interactions are then answered with an error.
+func (b *DiscordBot) SetEventPublisher(publisher EventPublisher) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.eventPublisher = publisher
+}
+
+// publishEvent publishes an event with the current publisher.
+// The interaction ID is used as correlation ID so the web server can trace the click.
+func (b *DiscordBot) publishEvent(i *discordgo.InteractionCreate, eventType string, payload interface{}) error {
+	b.mu.RLock()
+	publisher := b.eventPublisher
+	b.mu.RUnlock()
+
+	if publisher == nil {
+		return fmt.Errorf("event publisher is not available")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), eventPublishTimeout)
+	defer cancel()
+	return publisher.PublishEvent(ctx, eventType, i.ID, payload)
+}
+
+// TeamInviteRef identifies a team invite in component custom IDs
+type TeamInviteRef struct {
+	GameID        int64
+	InviteeUserID int64
+	InviterUserID int64
+}
+
+// SendTeamInviteDirectMessage sends a team invite DM with Accept and Decline buttons
+func (b *DiscordBot) SendTeamInviteDirectMessage(ctx context.Context, userID, content string, invite TeamInviteRef) (*models.TeamNotificationResult, error) {
+	channel, err := b.api.UserChannelCreate(userID, discordgo.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create DM channel: %w", err)
+	}
+
+	message, err := b.api.ChannelMessageSendComplex(channel.ID, &discordgo.MessageSend{
+		Content: content,
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.Button{
+						Label:    "承諾",
+						Style:    discordgo.SuccessButton,
+						CustomID: teamInviteCustomID("accept", invite),
+					},
+					discordgo.Button{
+						Label:    "拒否",
+						Style:    discordgo.DangerButton,
+						CustomID: teamInviteCustomID("reject", invite),
+					},
+				},
+			},
+		},
+	}, discordgo.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to send DM: %w", err)
+	}
+
+	return &models.TeamNotificationResult{
+		MessageID: message.ID,
+		Timestamp: message.Timestamp.Format("2006-01-02T15:04:05Z"),
+	}, nil
+}
+
+// teamInviteCustomID builds "team_invite:<action>:<game_id>:<invitee_user_id>:<inviter_user_id>"
+func teamInviteCustomID(action string, invite TeamInviteRef) string {
+	return fmt.Sprintf("%s:%s:%d:%d:%d", componentTeamInvite, action, invite.GameID, invite.InviteeUserID, invite.InviterUserID)
+}
+
+// parseTeamInviteCustomID parses the action and invite from a team invite custom ID
+func parseTeamInviteCustomID(customID string) (string, TeamInviteRef, error) {
+	parts := strings.Split(customID, ":")
+	if len(parts) != 5 || parts[0] != componentTeamInvite {
+		return "", TeamInviteRef{}, fmt.Errorf("invalid team invite custom id: %s", customID)
+	}
+
+	ids := make([]int64, 3)
+	for i, raw := range parts[2:] {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return "", TeamInviteRef{}, fmt.Errorf("invalid team invite custom id: %s", customID)
+		}
+		ids[i] = id
+	}
+
+	return parts[1], TeamInviteRef{GameID: ids[0], InviteeUserID: ids[1], InviterUserID: ids[2]}, nil
+}
+
+// handleComponentInteraction dispatches message component (button) interactions by custom ID prefix
+func (b *DiscordBot) handleComponentInteraction(s DiscordAPI, i *discordgo.InteractionCreate) {
+	customID := i.MessageComponentData().CustomID
+	prefix, _, _ := strings.Cut(customID, ":")
+
+	switch prefix {
+	case componentTeamInvite:
+		b.handleTeamInviteComponent(s, i, customID)
+	case componentApplication:
+		b.handleApplicationComponent(s, i, customID)
+	default:
+		slog.Warn("Unknown component interaction", "custom_id", customID)
+	}
+}
+
+// handleTeamInviteComponent publishes the invitee's accept/decline choice and updates the DM
+func (b *DiscordBot) handleTeamInviteComponent(s DiscordAPI, i *discordgo.InteractionCreate, customID string) {
+	action, invite, err := parseTeamInviteCustomID(customID)
+	if err != nil {
+		slog.Error("Failed to parse team invite component", "error", err)
+		respondEphemeral(s, i, "この招待は処理できません。")
+		return
+	}
+
+	var commandType, outcome string
+	switch action {
+	case "accept":
+		commandType = CommandTeamInviteAcceptRequested
+		outcome = "✅ 招待を承諾しました。"
+	case "reject":
+		commandType = CommandTeamInviteRejectRequested
+		outcome = "❌ 招待を拒否しました。"
+	default:
+		slog.Error("Unknown team invite action", "action", action)
+		respondEphemeral(s, i, "この招待は処理できません。")
+		return
+	}
+
+	user := interactionUser(i)
+	err = b.publishEvent(i, commandType, &models.TeamInviteCommandPayload{
+		GameID:           invite.GameID,
+		InviteeUserID:    invite.InviteeUserID,
+		InviterUserID:    invite.InviterUserID,
+		InviteeDiscordID: user.ID,
+	})
+	if err != nil {
+		slog.Error("Failed to publish team invite command", "command", commandType, "game_id", invite.GameID, "error", err)
+		respondEphemeral(s, i, "現在処理できません。しばらくしてからもう一度お試しください。")
+		return
+	}
+
+	slog.Info("Team invite command published", "command", commandType, "game_id", invite.GameID, "discord_user_id", user.ID)
+
+	// Replace the buttons with the outcome so the invite cannot be answered twice
+	content := fmt.Sprintf("%s\n\n**%s**", i.Message.Content, outcome)
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:    content,
+			Components: []discordgo.MessageComponent{},
+		},
+	})
+	if err != nil {
+		slog.Error("Failed to update team invite message", "error", err)
+	}
+}
+
+// ApplicationRef identifies a contest application in component custom IDs
+type ApplicationRef struct {
+	ContestID     int64
+	UserID        int64
+	DiscordUserID string
+}
+
+// applicationReviewComponents returns the Approve/Reject buttons for an application request
+func applicationReviewComponents(application ApplicationRef) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "承認",
+					Style:    discordgo.SuccessButton,
+					CustomID: applicationCustomID(componentApplication, "approve", application),
+				},
+				discordgo.Button{
+					Label:    "拒否",
+					Style:    discordgo.DangerButton,
+					CustomID: applicationCustomID(componentApplication, "reject", application),
+				},
+			},
+		},
+	}
+}
+
+// applicationCustomID builds "<prefix>:<action>:<contest_id>:<user_id>:<discord_user_id>"
+func applicationCustomID(prefix, action string, application ApplicationRef) string {
+	return fmt.Sprintf("%s:%s:%d:%d:%s", prefix, action, application.ContestID, application.UserID, application.DiscordUserID)
+}
+
+// parseApplicationCustomID parses the action and application from an application custom ID
+func parseApplicationCustomID(customID string) (string, ApplicationRef, error) {
+	parts := strings.Split(customID, ":")
+	if len(parts) != 5 {
+		return "", ApplicationRef{}, fmt.Errorf("invalid application custom id: %s", customID)
+	}
+
+	contestID, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return "", ApplicationRef{}, fmt.Errorf("invalid application custom id: %s", customID)
+	}
+	userID, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil {
+		return "", ApplicationRef{}, fmt.Errorf("invalid application custom id: %s", customID)
+	}
+
+	return parts[1], ApplicationRef{ContestID: contestID, UserID: userID, DiscordUserID: parts[4]}, nil
+}
+
+// handleApplicationComponent handles organizer Approve/Reject clicks on an application request.
+// Approve publishes immediately; Reject opens a modal asking for a reason.
+func (b *DiscordBot) handleApplicationComponent(s DiscordAPI, i *discordgo.InteractionCreate, customID string) {
+	if !b.isOrganizer(i) {
+		respondEphemeral(s, i, "この操作は運営人のみ可能です。")
+		return
+	}
+
+	action, application, err := parseApplicationCustomID(customID)
+	if err != nil {
+		slog.Error("Failed to parse application component", "error", err)
+		respondEphemeral(s, i, "この申請は処理できません。")
+		return
+	}
+
+	switch action {
+	case "approve":
+		b.completeApplicationReview(s, i, CommandApplicationApproveRequested, application, "")
+	case "reject":
+		err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseModal,
+			Data: &discordgo.InteractionResponseData{
+				CustomID: applicationCustomID(modalApplicationReject, "submit", application),
+				Title:    "参加申請の拒否",
+				Components: []discordgo.MessageComponent{
+					discordgo.ActionsRow{
+						Components: []discordgo.MessageComponent{
+							discordgo.TextInput{
+								CustomID:  applicationReasonInputID,
+								Label:     "拒否理由",
+								Style:     discordgo.TextInputParagraph,
+								Required:  true,
+								MaxLength: applicationReasonMaxLength,
+							},
+						},
+					},
+				},
+			},
+		})
+		if err != nil {
+			slog.Error("Failed to open application reject modal", "error", err)
+		}
+	default:
+		slog.Error("Unknown application action", "action", action)
+		respondEphemeral(s, i, "この申請は処理できません。")
+	}
+}
+
+// handleModalSubmit dispatches modal submissions by custom ID prefix
+func (b *DiscordBot) handleModalSubmit(s DiscordAPI, i *discordgo.InteractionCreate) {
+	data := i.ModalSubmitData()
+	prefix, _, _ := strings.Cut(data.CustomID, ":")
+
+	switch prefix {
+	case modalApplicationReject:
+		if !b.isOrganizer(i) {
+			respondEphemeral(s, i, "この操作は運営人のみ可能です。")
+			return
+		}
+		_, application, err := parseApplicationCustomID(data.CustomID)
+		if err != nil {
+			slog.Error("Failed to parse application reject modal", "error", err)
+			respondEphemeral(s, i, "この申請は処理できません。")
+			return
+		}
+		reason := modalTextInputValue(data, applicationReasonInputID)
+		b.completeApplicationReview(s, i, CommandApplicationRejectRequested, application, reason)
+	default:
+		slog.Warn("Unknown modal submission", "custom_id", data.CustomID)
+	}
+}
+
+// completeApplicationReview publishes the organizer's decision and edits the request message
+// to show who handled it
+func (b *DiscordBot) completeApplicationReview(s DiscordAPI, i *discordgo.InteractionCreate, commandType string, application ApplicationRef, reason string) {
+	organizer := interactionUser(i)
+
+	err := b.publishEvent(i, commandType, &models.ApplicationCommandPayload{
+		ContestID:            application.ContestID,
+		UserID:               application.UserID,
+		DiscordUserID:        application.DiscordUserID,
+		ProcessedByDiscordID: organizer.ID,
+		Reason:               reason,
+	})
+	if err != nil {
+		slog.Error("Failed to publish application command", "command", commandType, "contest_id", application.ContestID, "error", err)
+		respondEphemeral(s, i, "現在処理できません。しばらくしてからもう一度お試しください。")
+		return
+	}
+
+	slog.Info("Application command published", "command", commandType, "contest_id", application.ContestID, "processed_by", organizer.ID)
+
+	var outcome string
+	if commandType == CommandApplicationApproveRequested {
+		outcome = fmt.Sprintf("✅ <@%s>さんが承認しました。", organizer.ID)
+	} else {
+		outcome = fmt.Sprintf("❌ <@%s>さんが拒否しました。\n理由: %s", organizer.ID, reason)
+	}
+
+	content := outcome
+	if i.Message != nil {
+		content = fmt.Sprintf("%s\n\n**%s**", i.Message.Content, outcome)
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:         content,
+			Components:      []discordgo.MessageComponent{},
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+	if err != nil {
+		slog.Error("Failed to update application message", "error", err)
+	}
+}
+
+// isOrganizer reports whether the interacting member holds the organizer role.
+// Nobody is an organizer when no role is configured.
+func (b *DiscordBot) isOrganizer(i *discordgo.InteractionCreate) bool {
+	if i.Member == nil {
+		return false
+	}
+
+	if b.organizerRoleID == "" {
+		slog.Warn("Application review denied, no organizer role is configured", "guild_id", i.GuildID)
+		return false
+	}
+
+	for _, roleID := range i.Member.Roles {
+		if roleID == b.organizerRoleID {
+			return true
+		}
+	}
+	return false
+}
+
+// modalTextInputValue returns the value of a text input in a submitted modal
+func modalTextInputValue(data discordgo.ModalSubmitInteractionData, customID string) string {
+	for _, row := range data.Components {
+		actionsRow, ok := row.(*discordgo.ActionsRow)
+		if !ok {
+			continue
+		}
+		for _, component := range actionsRow.Components {
+			if input, ok := component.(*discordgo.TextInput); ok && input.CustomID == customID {
+				return strings.TrimSpace(input.Value)
+			}
+		}
+	}
+	return ""
+}
+
+// respondEphemeral answers an interaction with a message only the clicking user can see
+func respondEphemeral(s DiscordAPI, i *discordgo.InteractionCreate, content string) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		slog.Error("Failed to respond to interaction", "error", err)
+	}
+}
+
+// interactionUser returns the user who triggered an interaction (Member in guilds, User in DMs)
+func interactionUser(i *discordgo.InteractionCreate) *discordgo.User {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User
+	}
+	return i.User
+}
@@ -0,0 +1,102 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// respondRecorder records interaction responses; any other call panics
+type respondRecorder struct {
+	DiscordAPI
+	responses []*discordgo.InteractionResponse
+}
+
+func (r *respondRecorder) InteractionRespond(_ *discordgo.Interaction, resp *discordgo.InteractionResponse, _ ...discordgo.RequestOption) error {
+	r.responses = append(r.responses, resp)
+	return nil
+}
+
+func newComponentInteraction(member *discordgo.Member, customID string) *discordgo.InteractionCreate {
+	return &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		ID:      "interaction-1",
+		Type:    discordgo.InteractionMessageComponent,
+		GuildID: "guild-1",
+		Member:  member,
+		Data:    discordgo.MessageComponentInteractionData{CustomID: customID},
+	}}
+}
+
+func TestIsOrganizer(t *testing.T) {
+	admin := &discordgo.Member{User: &discordgo.User{ID: "1"}, Permissions: discordgo.PermissionManageGuild | discordgo.PermissionAdministrator}
+	organizer := &discordgo.Member{User: &discordgo.User{ID: "2"}, Roles: []string{"member-role", "organizer-role"}}
+
+	tests := []struct {
+		name   string
+		roleID string
+		member *discordgo.Member
+		want   bool
+	}{
+		{"member with the organizer role", "organizer-role", organizer, true},
+		{"Manage Server without the organizer role", "organizer-role", admin, false},
+		{"Manage Server without a configured role", "", admin, false},
+		{"organizer role without a configured role", "", organizer, false},
+		{"not a guild member", "organizer-role", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &DiscordBot{organizerRoleID: tt.roleID}
+			if got := b.isOrganizer(newComponentInteraction(tt.member, "")); got != tt.want {
+				t.Errorf("isOrganizer() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplicationButtonsRefusedWithoutOrganizerRole(t *testing.T) {
+	b := &DiscordBot{}
+	api := &respondRecorder{}
+	member := &discordgo.Member{User: &discordgo.User{ID: "1"}, Permissions: discordgo.PermissionManageGuild}
+	customID := applicationCustomID(componentApplication, "approve", ApplicationRef{ContestID: 1, UserID: 2, DiscordUserID: "3"})
+
+	b.handleComponentInteraction(api, newComponentInteraction(member, customID))
+
+	if len(api.responses) != 1 {
+		t.Fatalf("got %d responses, want 1", len(api.responses))
+	}
+	resp := api.responses[0]
+	if resp.Data == nil || resp.Data.Flags&discordgo.MessageFlagsEphemeral == 0 {
+		t.Fatalf("response = %+v, want an ephemeral refusal", resp)
+	}
+	if resp.Data.Content != "この操作は運営人のみ可能です。" {
+		t.Errorf("response content = %q", resp.Data.Content)
+	}
+}
+
+func TestStatusCommandReadsRabbitMQStatusConcurrently(t *testing.T) {
+	b := newBot(nil)
+	status := &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		ID:   "interaction-1",
+		Type: discordgo.InteractionApplicationCommand,
+		Data: discordgo.ApplicationCommandInteractionData{Name: "status"},
+	}}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			b.NotifyRabbitMQStatus(i%2 == 0, nil)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		b.handleApplicationCommand(&respondRecorder{}, status)
+	}
+	<-done
+
+	b.NotifyRabbitMQStatus(true, nil)
+	api := &respondRecorder{}
+	b.handleApplicationCommand(api, status)
+	if len(api.responses) != 1 || api.responses[0].Data.Content != "RabbitMQ Status: 🟢 Connected" {
+		t.Fatalf("responses = %+v, want a connected status", api.responses)
+	}
+}
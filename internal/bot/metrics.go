@@ -0,0 +1,115 @@
+package bot
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/gamers-bot/internal/metrics"
+)
+
+// SetMetrics records Discord REST API calls, rate limits, gateway latency and guild count in m.
+// It must be called before Connect.
+func (b *DiscordBot) SetMetrics(m *metrics.Metrics) {
+	if m == nil {
+		return
+	}
+	m.CollectGateway(b.GatewayLatency, b.GuildCount)
+	if b.Session == nil {
+		return
+	}
+
+	transport := b.Session.Client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	b.Session.Client.Transport = &instrumentedTransport{next: transport, metrics: m}
+
+	b.Session.AddHandler(func(_ *discordgo.Session, _ *discordgo.RateLimit) {
+		m.DiscordRateLimited()
+	})
+}
+
+// GatewayLatency returns the latency of the last gateway heartbeat
+func (b *DiscordBot) GatewayLatency() time.Duration {
+	return b.api.HeartbeatLatency()
+}
+
+// GuildCount returns the number of guilds the bot is connected to
+func (b *DiscordBot) GuildCount() int {
+	state := b.api.State()
+	state.RLock()
+	defer state.RUnlock()
+	return len(state.Guilds)
+}
+
+// instrumentedTransport records every Discord REST API request
+type instrumentedTransport struct {
+	next    http.RoundTripper
+	metrics *metrics.Metrics
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	code := ""
+	if resp.StatusCode >= 400 {
+		code = discordErrorCode(resp)
+	}
+	t.metrics.DiscordRequest(req.Method, apiRoute(req.URL.Path), resp.StatusCode, code)
+	return resp, nil
+}
+
+// discordErrorCode reads the JSON error code of a failed response and restores the body for discordgo
+func discordErrorCode(resp *http.Response) string {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+
+	var apiErr struct {
+		Code int `json:"code"`
+	}
+	if json.Unmarshal(body, &apiErr) != nil || apiErr.Code == 0 {
+		return ""
+	}
+	return strconv.Itoa(apiErr.Code)
+}
+
+// apiRoute turns a request path into a low-cardinality route label, e.g.
+// /api/v9/channels/123/messages becomes /channels/:id/messages. Interaction and webhook
+// tokens are replaced as well, so they never end up in metrics.
+func apiRoute(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) >= 2 && segments[0] == "api" && strings.HasPrefix(segments[1], "v") {
+		segments = segments[2:]
+	}
+	for i, segment := range segments {
+		switch {
+		case isSnowflake(segment):
+			segments[i] = ":id"
+		case i > 0 && segments[i-1] == "reactions":
+			segments[i] = ":emoji"
+		case i > 1 && (segments[i-2] == "interactions" || segments[i-2] == "webhooks"):
+			segments[i] = ":token"
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+// isSnowflake reports whether a path segment is a Discord ID
+func isSnowflake(segment string) bool {
+	_, err := strconv.ParseUint(segment, 10, 64)
+	return err == nil
+}
@@ -0,0 +1,240 @@
+package bot
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Bot modes (BOT_MODE)
+const (
+	// ModeLive sends everything to Discord
+	ModeLive = "live"
+	// ModeShadow consumes events as usual but does not send anything to the guilds
+	ModeShadow = "shadow"
+)
+
+// shadowDMPrefix prefixes the IDs of the direct message channels returned in shadow mode
+const shadowDMPrefix = "shadow-dm-"
+
+// discordEpoch is the first millisecond of 2015, the epoch of Discord snowflake IDs
+const discordEpoch = 1420070400000
+
+// shadowAPI is the DiscordAPI of a bot in shadow mode. Reads pass through, so channel lookups
+// see the real guilds. Messages, DMs, pins, voice moves and scheduled events are logged instead
+// of sent, or, with a sandbox channel, messages and DMs are posted there with a line naming the
+// original target. Every write reports synthetic success, so handlers and the legacy responses
+// behave as in live mode.
+type shadowAPI struct {
+	DiscordAPI
+	sandboxChannelID string
+	nextID           atomic.Int64
+}
+
+// SetShadowMode puts the bot in shadow mode. Messages and DMs are redirected to
+// sandboxChannelID, or only logged if it is empty. It must be called before Connect.
+func (b *DiscordBot) SetShadowMode(sandboxChannelID string) {
+	b.api = &shadowAPI{DiscordAPI: b.api, sandboxChannelID: sandboxChannelID}
+	if sandboxChannelID != "" {
+		slog.Warn("Shadow mode enabled, redirecting messages to the sandbox channel", "sandbox_channel_id", sandboxChannelID)
+	} else {
+		slog.Warn("Shadow mode enabled, logging messages instead of sending them")
+	}
+}
+
+// ShadowMode reports whether the bot is in shadow mode
+func (b *DiscordBot) ShadowMode() bool {
+	_, ok := b.api.(*shadowAPI)
+	return ok
+}
+
+// ChannelMessageSend implements DiscordAPI
+func (s *shadowAPI) ChannelMessageSend(channelID, content string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return s.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{Content: content}, options...)
+}
+
+// ChannelMessageSendEmbed implements DiscordAPI
+func (s *shadowAPI) ChannelMessageSendEmbed(channelID string, embed *discordgo.MessageEmbed, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return s.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{embed}}, options...)
+}
+
+// ChannelMessageSendComplex implements DiscordAPI
+func (s *shadowAPI) ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	if s.sandboxChannelID != "" {
+		// Nobody in the sandbox is pinged, and buttons are disabled so clicks do not reach the backend
+		redirected := *data
+		redirected.Content = strings.TrimSpace(fmt.Sprintf("🕶️ **Shadow** → %s\n%s", describeTarget(channelID), data.Content))
+		redirected.Components = disableButtons(data.Components)
+		redirected.AllowedMentions = &discordgo.MessageAllowedMentions{}
+		return s.DiscordAPI.ChannelMessageSendComplex(s.sandboxChannelID, &redirected, options...)
+	}
+
+	slog.Info("Shadow mode: message not sent", append(targetAttrs(channelID), "message", renderMessage(data.Content, data.Embeds, data.Components))...)
+	message := &discordgo.Message{
+		ID:         s.newID(),
+		ChannelID:  channelID,
+		Content:    data.Content,
+		Embeds:     data.Embeds,
+		Components: data.Components,
+		Timestamp:  time.Now(),
+	}
+	if data.Embed != nil {
+		message.Embeds = append(message.Embeds, data.Embed)
+	}
+	return message, nil
+}
+
+// ChannelMessageEditEmbed implements DiscordAPI. With a sandbox channel the message was posted
+// there, so the edit goes there as well.
+func (s *shadowAPI) ChannelMessageEditEmbed(channelID, messageID string, embed *discordgo.MessageEmbed, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	if s.sandboxChannelID != "" {
+		return s.DiscordAPI.ChannelMessageEditEmbed(s.sandboxChannelID, messageID, embed, options...)
+	}
+
+	slog.Info("Shadow mode: message not edited", append(targetAttrs(channelID), "message_id", messageID, "message", renderMessage("", []*discordgo.MessageEmbed{embed}, nil))...)
+	return &discordgo.Message{
+		ID:        messageID,
+		ChannelID: channelID,
+		Embeds:    []*discordgo.MessageEmbed{embed},
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// ChannelMessagePin implements DiscordAPI
+func (s *shadowAPI) ChannelMessagePin(channelID, messageID string, options ...discordgo.RequestOption) error {
+	if s.sandboxChannelID != "" {
+		return s.DiscordAPI.ChannelMessagePin(s.sandboxChannelID, messageID, options...)
+	}
+
+	slog.Info("Shadow mode: message not pinned", append(targetAttrs(channelID), "message_id", messageID)...)
+	return nil
+}
+
+// UserChannelCreate implements DiscordAPI. No DM channel is opened; the returned channel
+// stands for the user in the send methods.
+func (s *shadowAPI) UserChannelCreate(recipientID string, _ ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	return &discordgo.Channel{
+		ID:         shadowDMPrefix + recipientID,
+		Type:       discordgo.ChannelTypeDM,
+		Recipients: []*discordgo.User{{ID: recipientID}},
+	}, nil
+}
+
+// GuildMemberMove implements DiscordAPI
+func (s *shadowAPI) GuildMemberMove(guildID, userID string, channelID *string, _ ...discordgo.RequestOption) error {
+	to := ""
+	if channelID != nil {
+		to = *channelID
+	}
+	slog.Info("Shadow mode: member not moved", "guild_id", guildID, "user_id", userID, "channel_id", to)
+	return nil
+}
+
+// GuildScheduledEventCreate implements DiscordAPI
+func (s *shadowAPI) GuildScheduledEventCreate(guildID string, params *discordgo.GuildScheduledEventParams, _ ...discordgo.RequestOption) (*discordgo.GuildScheduledEvent, error) {
+	event := &discordgo.GuildScheduledEvent{
+		ID:               s.newID(),
+		GuildID:          guildID,
+		Name:             params.Name,
+		Description:      params.Description,
+		ScheduledEndTime: params.ScheduledEndTime,
+		EntityType:       params.EntityType,
+		PrivacyLevel:     params.PrivacyLevel,
+		Status:           discordgo.GuildScheduledEventStatusScheduled,
+	}
+	if params.ScheduledStartTime != nil {
+		event.ScheduledStartTime = *params.ScheduledStartTime
+	}
+	if params.EntityMetadata != nil {
+		event.EntityMetadata = *params.EntityMetadata
+	}
+
+	slog.Info("Shadow mode: scheduled event not created", "guild_id", guildID, "name", event.Name, "start", event.ScheduledStartTime)
+	return event, nil
+}
+
+// describeTarget names the channel or DM recipient a message was meant for. The raw ID is
+// included since the mention does not resolve outside the original guild.
+func describeTarget(channelID string) string {
+	if userID, ok := strings.CutPrefix(channelID, shadowDMPrefix); ok {
+		return fmt.Sprintf("DM <@%s> (%s)", userID, userID)
+	}
+	return fmt.Sprintf("<#%s> (%s)", channelID, channelID)
+}
+
+// targetAttrs returns the log attributes of the channel or DM recipient a message was meant for
+func targetAttrs(channelID string) []interface{} {
+	if userID, ok := strings.CutPrefix(channelID, shadowDMPrefix); ok {
+		return []interface{}{"user_id", userID}
+	}
+	return []interface{}{"channel_id", channelID}
+}
+
+// disableButtons returns a copy of components with every button disabled
+func disableButtons(components []discordgo.MessageComponent) []discordgo.MessageComponent {
+	if len(components) == 0 {
+		return nil
+	}
+	disabled := make([]discordgo.MessageComponent, 0, len(components))
+	for _, component := range components {
+		row, ok := component.(discordgo.ActionsRow)
+		if !ok {
+			disabled = append(disabled, component)
+			continue
+		}
+		buttons := make([]discordgo.MessageComponent, 0, len(row.Components))
+		for _, c := range row.Components {
+			if button, ok := c.(discordgo.Button); ok {
+				button.Disabled = true
+				c = button
+			}
+			buttons = append(buttons, c)
+		}
+		disabled = append(disabled, discordgo.ActionsRow{Components: buttons})
+	}
+	return disabled
+}
+
+// newID returns a synthetic snowflake ID for the current time
+func (s *shadowAPI) newID() string {
+	return strconv.FormatInt((time.Now().UnixMilli()-discordEpoch)<<22|s.nextID.Add(1)&0x3FFFFF, 10)
+}
+
+// renderMessage renders a message as plain text for the logs
+func renderMessage(content string, embeds []*discordgo.MessageEmbed, components []discordgo.MessageComponent) string {
+	var sb strings.Builder
+	sb.WriteString(content)
+	for _, embed := range embeds {
+		if embed == nil {
+			continue
+		}
+		for _, line := range []string{embed.Title, embed.Description} {
+			if line != "" {
+				fmt.Fprintf(&sb, "\n%s", line)
+			}
+		}
+		for _, field := range embed.Fields {
+			fmt.Fprintf(&sb, "\n%s: %s", field.Name, field.Value)
+		}
+		if embed.Footer != nil && embed.Footer.Text != "" {
+			fmt.Fprintf(&sb, "\n%s", embed.Footer.Text)
+		}
+	}
+	for _, component := range components {
+		row, ok := component.(discordgo.ActionsRow)
+		if !ok {
+			continue
+		}
+		for _, c := range row.Components {
+			if button, ok := c.(discordgo.Button); ok {
+				fmt.Fprintf(&sb, "\n[%s]", button.Label)
+			}
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
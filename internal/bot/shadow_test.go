@@ -0,0 +1,209 @@
+package bot_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/gamers-bot/internal/bot"
+	"github.com/gamers-bot/internal/bot/discordtest"
+)
+
+const (
+	shadowGuildID   = "900000000000000000"
+	shadowTextID    = "900000000000000001"
+	shadowVoiceID   = "900000000000000002"
+	shadowLobbyID   = "900000000000000003"
+	shadowSandboxID = "900000000000000009"
+	shadowUserID    = "800000000000000001"
+)
+
+// newShadowBot returns a connected bot in shadow mode on a fake guild with a text and two voice channels
+func newShadowBot(t *testing.T, sandboxChannelID string) (*bot.DiscordBot, *discordtest.Fake) {
+	t.Helper()
+	fake := discordtest.New()
+	fake.AddGuild(&discordgo.Guild{
+		ID:   shadowGuildID,
+		Name: "GAMERS",
+		Channels: []*discordgo.Channel{
+			{ID: shadowTextID, GuildID: shadowGuildID, Name: "general", Type: discordgo.ChannelTypeGuildText},
+			{ID: shadowVoiceID, GuildID: shadowGuildID, Name: "team-a", Type: discordgo.ChannelTypeGuildVoice},
+			{ID: shadowLobbyID, GuildID: shadowGuildID, Name: "lobby", Type: discordgo.ChannelTypeGuildVoice},
+		},
+	})
+	b := bot.NewWithAPI(fake)
+	b.SetShadowMode(sandboxChannelID)
+	if err := b.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	if !b.ShadowMode() {
+		t.Fatal("ShadowMode() = false after SetShadowMode")
+	}
+	return b, fake
+}
+
+// captureLogs records the default logger's output until the test ends
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+// writeEverything calls every bot method that writes to Discord and fails the test if one reports an error
+func writeEverything(t *testing.T, b *bot.DiscordBot) {
+	t.Helper()
+	ctx := context.Background()
+	start := time.Now().Add(24 * time.Hour)
+	contest := &bot.ContestAnnouncement{ContestID: 1, Title: "Cup", StartDate: start, ApplyURL: "https://gamers.example/contests/1"}
+	game := &bot.GameNotification{GameID: 7, GameName: "Final", ScheduledAt: start}
+
+	if _, err := b.SendMessage(ctx, shadowTextID, "hello"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	announcement, err := b.SendContestAnnouncement(ctx, shadowTextID, contest)
+	if err != nil {
+		t.Fatalf("SendContestAnnouncement: %v", err)
+	}
+	if err := b.PinMessage(ctx, shadowTextID, announcement.MessageID); err != nil {
+		t.Fatalf("PinMessage: %v", err)
+	}
+	status, err := b.SendGameNotification(ctx, shadowTextID, game, bot.GameScheduled)
+	if err != nil {
+		t.Fatalf("SendGameNotification: %v", err)
+	}
+	if _, err := b.EditGameNotification(ctx, shadowTextID, status.MessageID, game, bot.GameActivated); err != nil {
+		t.Fatalf("EditGameNotification: %v", err)
+	}
+	if _, err := b.SendDirectMessage(ctx, shadowUserID, "you are in"); err != nil {
+		t.Fatalf("SendDirectMessage: %v", err)
+	}
+	if _, err := b.SendTeamInviteDirectMessage(ctx, shadowUserID, "join us", bot.TeamInviteRef{GameID: 7, InviteeUserID: 2, InviterUserID: 3}); err != nil {
+		t.Fatalf("SendTeamInviteDirectMessage: %v", err)
+	}
+	moved, err := b.MoveMembers(ctx, shadowGuildID, shadowLobbyID, shadowVoiceID, []string{shadowUserID})
+	if err != nil || moved.MovedCount != 1 {
+		t.Fatalf("MoveMembers = %+v, %v; want 1 member reported moved", moved, err)
+	}
+	eventID, err := b.CreateContestScheduledEvent(ctx, shadowGuildID, contest)
+	if err != nil || eventID == "" {
+		t.Fatalf("CreateContestScheduledEvent = %q, %v; want a synthetic event ID", eventID, err)
+	}
+}
+
+func TestShadowModeLogsWritesInsteadOfSending(t *testing.T) {
+	logs := captureLogs(t)
+	b, fake := newShadowBot(t, "")
+
+	writeEverything(t, b)
+
+	if n := len(fake.Messages()); n != 0 {
+		t.Errorf("%d messages sent to the guild, want none", n)
+	}
+	if n := len(fake.DirectMessages()); n != 0 {
+		t.Errorf("%d direct messages sent, want none", n)
+	}
+	if n := len(fake.Moves()); n != 0 {
+		t.Errorf("%d members moved, want none", n)
+	}
+	if n := len(fake.ScheduledEvents()); n != 0 {
+		t.Errorf("%d scheduled events created, want none", n)
+	}
+
+	for _, want := range []string{
+		`msg="Shadow mode: message not sent" channel_id=` + shadowTextID + ` message=hello`,
+		`msg="Shadow mode: message not sent" user_id=` + shadowUserID + ` message="you are in"`,
+		`msg="Shadow mode: message not pinned" channel_id=` + shadowTextID,
+		`msg="Shadow mode: message not edited" channel_id=` + shadowTextID,
+		`msg="Shadow mode: member not moved" guild_id=` + shadowGuildID + ` user_id=` + shadowUserID + ` channel_id=` + shadowVoiceID,
+		`msg="Shadow mode: scheduled event not created" guild_id=` + shadowGuildID + ` name=Cup`,
+	} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("logs do not contain %s:\n%s", want, logs)
+		}
+	}
+}
+
+func TestShadowModeMirrorsMessagesToSandbox(t *testing.T) {
+	b, fake := newShadowBot(t, shadowSandboxID)
+
+	writeEverything(t, b)
+
+	messages := fake.Messages()
+	if len(messages) != 5 {
+		t.Fatalf("got %d messages, want the 3 channel messages and 2 DMs mirrored", len(messages))
+	}
+	targets := []string{
+		"🕶️ **Shadow** → <#" + shadowTextID + "> (" + shadowTextID + ")\nhello",
+		"🕶️ **Shadow** → <#" + shadowTextID + "> (" + shadowTextID + ")",
+		"🕶️ **Shadow** → <#" + shadowTextID + "> (" + shadowTextID + ")",
+		"🕶️ **Shadow** → DM <@" + shadowUserID + "> (" + shadowUserID + ")\nyou are in",
+		"🕶️ **Shadow** → DM <@" + shadowUserID + "> (" + shadowUserID + ")\njoin us",
+	}
+	for i, m := range messages {
+		if m.ChannelID != shadowSandboxID {
+			t.Errorf("message %d sent to %s, want the sandbox channel", i, m.ChannelID)
+		}
+		if m.Content != targets[i] {
+			t.Errorf("message %d content = %q, want %q", i, m.Content, targets[i])
+		}
+	}
+
+	// The pin and the edit follow the messages to the sandbox
+	if !messages[1].Pinned {
+		t.Error("contest announcement not pinned in the sandbox")
+	}
+	if !messages[2].Edited {
+		t.Error("game status message not edited in the sandbox")
+	}
+
+	// Buttons cannot be clicked in the sandbox
+	invite := messages[4].Components
+	if len(invite) == 0 {
+		t.Fatal("team invite mirrored without its buttons")
+	}
+	for _, c := range invite[0].(discordgo.ActionsRow).Components {
+		if button, ok := c.(discordgo.Button); ok && !button.Disabled {
+			t.Errorf("button %q is enabled in the sandbox", button.Label)
+		}
+	}
+
+	if n := len(fake.DirectMessages()); n != 0 {
+		t.Errorf("%d direct messages sent, want none", n)
+	}
+	if n := len(fake.Moves()); n != 0 {
+		t.Errorf("%d members moved, want none", n)
+	}
+	if n := len(fake.ScheduledEvents()); n != 0 {
+		t.Errorf("%d scheduled events created, want none", n)
+	}
+}
+
+func TestShadowModePassesReadsAndInteractionResponses(t *testing.T) {
+	b, fake := newShadowBot(t, "")
+
+	channels, err := b.GetTextChannels(context.Background(), shadowGuildID)
+	if err != nil {
+		t.Fatalf("GetTextChannels: %v", err)
+	}
+	if len(channels.Channels) != 1 || channels.Channels[0].ID != shadowTextID {
+		t.Errorf("GetTextChannels = %+v, want the guild's text channel", channels.Channels)
+	}
+
+	b.HandleInteraction(&discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		ID:      "interaction-1",
+		Type:    discordgo.InteractionApplicationCommand,
+		GuildID: shadowGuildID,
+		Data:    discordgo.ApplicationCommandInteractionData{Name: "status"},
+	}})
+	responses := fake.InteractionResponses()
+	if len(responses) != 1 || responses[0].InteractionID != "interaction-1" {
+		t.Errorf("interaction responses = %+v, want the /status response", responses)
+	}
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseHandlerMiddlewares(t *testing.T) {
+	chains, err := parseHandlerMiddlewares("MOVE_MEMBERS=logging|metrics|recover, game.finished = recover ,SEND_MESSAGE=")
+	if err != nil {
+		t.Fatalf("parseHandlerMiddlewares: %v", err)
+	}
+	want := map[string][]string{
+		"MOVE_MEMBERS":  {"logging", "metrics", "recover"},
+		"game.finished": {"recover"},
+		"SEND_MESSAGE":  {},
+	}
+	if !reflect.DeepEqual(chains, want) {
+		t.Errorf("chains = %v, want %v", chains, want)
+	}
+
+	cfg := &Config{HandlerMiddlewares: chains}
+	if got := cfg.HandlerMiddlewaresFor("game.created"); !reflect.DeepEqual(got, DefaultHandlerMiddlewares) {
+		t.Errorf("HandlerMiddlewaresFor(game.created) = %v, want the default chain", got)
+	}
+}
+
+func TestParseHandlerMiddlewaresRejectsInvalidEntries(t *testing.T) {
+	for _, value := range []string{"MOVE_MEMBERS", "=recover", "MOVE_MEMBERS=logging|retry"} {
+		if _, err := parseHandlerMiddlewares(value); err == nil {
+			t.Errorf("parseHandlerMiddlewares(%q) succeeded, want an error", value)
+		}
+	}
+}
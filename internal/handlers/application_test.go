@@ -0,0 +1,109 @@
+package handlers_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/gamers-bot/internal/bot"
+	"github.com/gamers-bot/internal/handlers"
+)
+
+const (
+	testApplicantDiscordID = "800000000000000003"
+	testOrganizerDiscordID = "800000000000000004"
+)
+
+// application returns an application event payload for user 5 applying to contest 7
+func application(eventType string, data map[string]interface{}) map[string]interface{} {
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	data["contest_title"] = "Spring Cup"
+	return map[string]interface{}{
+		"event_type":              eventType,
+		"contest_id":              7,
+		"user_id":                 5,
+		"discord_user_id":         testApplicantDiscordID,
+		"discord_guild_id":        testGuildID,
+		"discord_text_channel_id": testTextChannelID,
+		"data":                    data,
+	}
+}
+
+func TestApplicationRequestedHasReviewButtons(t *testing.T) {
+	b, fake := newTestBot(t)
+
+	result, err := handle(t, b, handlers.NewApplicationRequestedHandler(), application("application.requested", nil))
+	if err != nil {
+		t.Fatalf("application.requested: %v", err)
+	}
+
+	message := onlyMessage(t, fake)
+	if !strings.Contains(message.Content, "<@"+testApplicantDiscordID+">様が **Spring Cup** 大会に参加申請を送りました") {
+		t.Errorf("content = %q", message.Content)
+	}
+	if result["user_id"] != testApplicantDiscordID {
+		t.Errorf("result user_id = %v", result["user_id"])
+	}
+
+	got := buttons(message.Components)
+	if len(got) != 2 {
+		t.Fatalf("got %d buttons, want approve and reject", len(got))
+	}
+	if got[0].Style != discordgo.SuccessButton || got[0].CustomID != "application:approve:7:5:"+testApplicantDiscordID {
+		t.Errorf("approve button = %+v", got[0])
+	}
+	if got[1].Style != discordgo.DangerButton || got[1].CustomID != "application:reject:7:5:"+testApplicantDiscordID {
+		t.Errorf("reject button = %+v", got[1])
+	}
+}
+
+func TestApplicationDecisionNotifications(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler handlers.Handler
+		data    map[string]interface{}
+		want    []string
+	}{
+		{"accepted", handlers.NewApplicationAcceptedHandler(), map[string]interface{}{"processed_by_discord_id": testOrganizerDiscordID},
+			[]string{"[申請承認]", "**Spring Cup** 大会参加申請が完了されました", "承認者: <@" + testOrganizerDiscordID + ">"}},
+		{"accepted without organizer", handlers.NewApplicationAcceptedHandler(), nil,
+			[]string{"[申請承認]", "**Spring Cup**"}},
+		{"rejected", handlers.NewApplicationRejectedHandler(), map[string]interface{}{"processed_by_discord_id": testOrganizerDiscordID},
+			[]string{"[申請許節]", "**Spring Cup** 大会参加申請が断れました", "処理者: <@" + testOrganizerDiscordID + ">"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, fake := newTestBot(t)
+
+			if _, err := handle(t, b, tt.handler, application("application."+strings.Fields(tt.name)[0], tt.data)); err != nil {
+				t.Fatalf("Handle: %v", err)
+			}
+
+			message := onlyMessage(t, fake)
+			for _, want := range tt.want {
+				if !strings.Contains(message.Content, want) {
+					t.Errorf("content %q does not contain %q", message.Content, want)
+				}
+			}
+			if len(message.Components) != 0 {
+				t.Errorf("decision has components %+v, want none", message.Components)
+			}
+		})
+	}
+}
+
+func TestApplicationRequiresContestTitle(t *testing.T) {
+	b, fake := newTestBot(t)
+	payload := application("application.requested", nil)
+	payload["data"] = map[string]interface{}{}
+
+	_, err := handle(t, b, handlers.NewApplicationRequestedHandler(), payload)
+	if code := errorCode(t, err); code != bot.ErrCodeValidation {
+		t.Errorf("error code = %s, want %s", code, bot.ErrCodeValidation)
+	}
+	if n := len(fake.Messages()); n != 0 {
+		t.Errorf("sent %d messages, want 0", n)
+	}
+}
@@ -18,7 +18,7 @@ func NewVoiceChannelHandler() *VoiceChannelHandler {
 
 // Handle processes a GET_VOICE_CHANNELS event
 func (h *VoiceChannelHandler) Handle(ctx context.Context, bot *bot.DiscordBot, guildID string, payload map[string]interface{}) (map[string]interface{}, error) {
-	result, err := bot.GetVoiceChannels(guildID)
+	result, err := bot.GetVoiceChannels(ctx, guildID)
 	if err != nil {
 		return nil, err
 	}
@@ -47,7 +47,7 @@ func NewTextChannelHandler() *TextChannelHandler {
 
 // Handle processes a GET_TEXT_CHANNELS event
 func (h *TextChannelHandler) Handle(ctx context.Context, bot *bot.DiscordBot, guildID string, payload map[string]interface{}) (map[string]interface{}, error) {
-	result, err := bot.GetTextChannels(guildID)
+	result, err := bot.GetTextChannels(ctx, guildID)
 	if err != nil {
 		return nil, err
 	}
@@ -2,80 +2,100 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/gamers-bot/internal/bot"
 	"github.com/gamers-bot/internal/models"
 )
 
-// ContestCreatedHandler handles contest.created events
-type ContestCreatedHandler struct{}
-
-func NewContestCreatedHandler() *ContestCreatedHandler {
-	return &ContestCreatedHandler{}
+// contestCreatedHandler handles contest.created events
+type contestCreatedHandler struct {
+	webAppURL            string
+	pinAnnouncement      bool
+	createScheduledEvent bool
 }
 
-func (h *ContestCreatedHandler) Handle(ctx context.Context, b *bot.DiscordBot, guildID string, payload map[string]interface{}) (map[string]interface{}, error) {
-	// TODO: Discord 알림 전송 로직
-	slog.Info("ContestCreatedHandler invoked", "guild_id", guildID)
-	return nil, nil
+// NewContestCreatedHandler creates a handler for contest.created events.
+// webAppURL is used to build the "Apply" link; an empty URL omits the button.
+func NewContestCreatedHandler(webAppURL string, pinAnnouncement, createScheduledEvent bool) *TypedHandler[models.ContestCreatedEventPayload, models.ContestAnnouncementResult] {
+	h := &contestCreatedHandler{
+		webAppURL:            strings.TrimSuffix(webAppURL, "/"),
+		pinAnnouncement:      pinAnnouncement,
+		createScheduledEvent: createScheduledEvent,
+	}
+	return NewTypedHandler(h.announce)
 }
 
-// ContestInvitationHandler handles SEND_CONTEST_INVITATION events
-type ContestInvitationHandler struct{}
-
-// NewContestInvitationHandler creates a new ContestInvitationHandler
-func NewContestInvitationHandler() *ContestInvitationHandler {
-	return &ContestInvitationHandler{}
-}
+// announce processes a contest.created event - posts a contest announcement to the contest channel.
+// Pinning and scheduled event creation are best-effort: the announcement has already been sent,
+// so their failures are logged instead of failing (and retrying) the whole event.
+func (h *contestCreatedHandler) announce(ctx context.Context, b *bot.DiscordBot, guildID string, eventPayload *models.ContestCreatedEventPayload) (*models.ContestAnnouncementResult, error) {
+	contest := &bot.ContestAnnouncement{
+		ContestID:   eventPayload.ContestID,
+		Title:       eventPayload.ContestTitle,
+		Description: dataString(eventPayload.Data, "description"),
+		GameType:    dataString(eventPayload.Data, "game_type"),
+		StartDate:   dataTime(eventPayload.Data, "start_date", "started_at"),
+		EndDate:     dataTime(eventPayload.Data, "end_date", "ended_at"),
+		MaxTeams:    dataInt(eventPayload.Data, "max_team_count"),
+		ApplyURL:    h.applyURL(eventPayload.ContestID),
+	}
 
-// Handle processes a SEND_CONTEST_INVITATION event
-func (h *ContestInvitationHandler) Handle(ctx context.Context, bot *bot.DiscordBot, guildID string, payload map[string]interface{}) (map[string]interface{}, error) {
-	// Parse payload to ContestInvitationPayload
-	payloadBytes, err := json.Marshal(payload)
+	result, err := b.SendContestAnnouncement(ctx, eventPayload.DiscordTextChannelID, contest)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+		return nil, err
 	}
 
-	var invitePayload models.ContestInvitationPayload
-	if err := json.Unmarshal(payloadBytes, &invitePayload); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal payload: %w", err)
+	if h.pinAnnouncement {
+		if err := b.PinMessage(ctx, result.ChannelID, result.MessageID); err != nil {
+			slog.Warn("Failed to pin contest announcement", "contest_id", eventPayload.ContestID, "error", err)
+		} else {
+			result.Pinned = true
+		}
 	}
 
-	// Validate payload
-	if invitePayload.ChannelID == "" {
-		return nil, fmt.Errorf("channel_id is required")
-	}
-	if len(invitePayload.UserIDs) == 0 {
-		return nil, fmt.Errorf("user_ids cannot be empty")
-	}
-	if invitePayload.ContestName == "" {
-		return nil, fmt.Errorf("contest_name is required")
+	if h.createScheduledEvent {
+		switch {
+		case eventPayload.DiscordGuildID == "":
+			slog.Warn("Skipping scheduled event: discord_guild_id is missing", "contest_id", eventPayload.ContestID)
+		case contest.StartDate.IsZero() || contest.StartDate.Before(time.Now()):
+			slog.Warn("Skipping scheduled event: contest start date is missing or in the past", "contest_id", eventPayload.ContestID)
+		default:
+			eventID, err := b.CreateContestScheduledEvent(ctx, eventPayload.DiscordGuildID, contest)
+			if err != nil {
+				slog.Warn("Failed to create scheduled event", "contest_id", eventPayload.ContestID, "error", err)
+			} else {
+				result.ScheduledEventID = eventID
+			}
+		}
 	}
 
-	// Send contest invitation
-	result, err := bot.SendContestInvitation(
-		invitePayload.ChannelID,
-		invitePayload.UserIDs,
-		invitePayload.ContestName,
-		invitePayload.Message,
-	)
-	if err != nil {
-		return nil, err
-	}
+	return result, nil
+}
 
-	// Convert result to map
-	resultBytes, err := json.Marshal(result)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal result: %w", err)
+// applyURL returns the web app page where users apply to the contest
+func (h *contestCreatedHandler) applyURL(contestID int64) string {
+	if h.webAppURL == "" {
+		return ""
 	}
+	return fmt.Sprintf("%s/contests/%d", h.webAppURL, contestID)
+}
 
-	var resultMap map[string]interface{}
-	if err := json.Unmarshal(resultBytes, &resultMap); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal result: %w", err)
-	}
+// NewContestInvitationHandler creates a handler for SEND_CONTEST_INVITATION events
+func NewContestInvitationHandler() *TypedHandler[models.ContestInvitationPayload, models.ContestInvitationResult] {
+	return NewTypedHandler(sendContestInvitation)
+}
 
-	return resultMap, nil
+// sendContestInvitation processes a SEND_CONTEST_INVITATION event
+func sendContestInvitation(ctx context.Context, b *bot.DiscordBot, guildID string, payload *models.ContestInvitationPayload) (*models.ContestInvitationResult, error) {
+	return b.SendContestInvitation(
+		ctx,
+		payload.ChannelID,
+		payload.UserIDs,
+		payload.ContestName,
+		payload.Message,
+	)
 }
@@ -0,0 +1,121 @@
+package handlers_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/gamers-bot/internal/bot/discordtest"
+	"github.com/gamers-bot/internal/handlers"
+)
+
+// contestCreated returns a contest.created payload for contest 7 starting at start
+func contestCreated(start time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"event_id":                "evt-contest",
+		"event_type":              "contest.created",
+		"contest_id":              7,
+		"contest_title":           "Spring Cup",
+		"discord_guild_id":        testGuildID,
+		"discord_text_channel_id": testTextChannelID,
+		"data": map[string]interface{}{
+			"description":    "5v5 tournament",
+			"game_type":      "VALORANT",
+			"max_team_count": 16,
+			"start_date":     start.Format(time.RFC3339),
+		},
+	}
+}
+
+func TestContestCreatedAnnouncesContest(t *testing.T) {
+	b, fake := newTestBot(t)
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Second)
+	h := handlers.NewContestCreatedHandler("https://gamers.example/", true, true)
+
+	result, err := handle(t, b, h, contestCreated(start))
+	if err != nil {
+		t.Fatalf("contest.created: %v", err)
+	}
+
+	message := onlyMessage(t, fake)
+	if !message.Pinned {
+		t.Error("announcement is not pinned")
+	}
+	if len(message.Embeds) != 1 {
+		t.Fatalf("got %d embeds, want 1", len(message.Embeds))
+	}
+	embed := message.Embeds[0]
+	if embed.Title != "[大会開催] Spring Cup" || embed.Description != "5v5 tournament" {
+		t.Errorf("embed title/description = %q / %q", embed.Title, embed.Description)
+	}
+	if game, _ := fieldValue(embed, "ゲーム"); game != "VALORANT" {
+		t.Errorf("game = %q", game)
+	}
+	if teams, _ := fieldValue(embed, "最大チーム数"); teams != "16チーム" {
+		t.Errorf("max teams = %q", teams)
+	}
+
+	apply := buttons(message.Components)
+	if len(apply) != 1 || apply[0].Style != discordgo.LinkButton || apply[0].URL != "https://gamers.example/contests/7" {
+		t.Errorf("buttons = %+v, want a link to the contest page", apply)
+	}
+
+	events := fake.ScheduledEvents()
+	if len(events) != 1 {
+		t.Fatalf("got %d scheduled events, want 1", len(events))
+	}
+	if events[0].Name != "Spring Cup" || !events[0].ScheduledStartTime.Equal(start) {
+		t.Errorf("scheduled event = %q at %v, want Spring Cup at %v", events[0].Name, events[0].ScheduledStartTime, start)
+	}
+
+	if result["message_id"] != message.ID || result["pinned"] != true || result["scheduled_event_id"] != events[0].ID {
+		t.Errorf("result = %v", result)
+	}
+}
+
+func TestContestCreatedWithoutOptionalFeatures(t *testing.T) {
+	b, fake := newTestBot(t)
+	h := handlers.NewContestCreatedHandler("", false, false)
+
+	if _, err := handle(t, b, h, contestCreated(time.Now().Add(time.Hour))); err != nil {
+		t.Fatalf("contest.created: %v", err)
+	}
+
+	message := onlyMessage(t, fake)
+	if message.Pinned || len(message.Components) != 0 {
+		t.Errorf("message = %+v, want an unpinned announcement without buttons", message)
+	}
+	if n := len(fake.ScheduledEvents()); n != 0 {
+		t.Errorf("created %d scheduled events, want 0", n)
+	}
+}
+
+func TestContestCreatedSucceedsWhenPinningFails(t *testing.T) {
+	b, fake := newTestBot(t)
+	fake.Fail("ChannelMessagePin", discordtest.RESTError(http.StatusForbidden, discordgo.ErrCodeMissingPermissions, "Missing Permissions"))
+	h := handlers.NewContestCreatedHandler("", true, false)
+
+	result, err := handle(t, b, h, contestCreated(time.Now().Add(time.Hour)))
+	if err != nil {
+		t.Fatalf("contest.created: %v", err)
+	}
+	if onlyMessage(t, fake).Pinned {
+		t.Error("announcement is pinned")
+	}
+	if pinned, _ := result["pinned"].(bool); pinned {
+		t.Errorf("result pinned = %v, want false", result["pinned"])
+	}
+}
+
+func TestContestCreatedSkipsScheduledEventInThePast(t *testing.T) {
+	b, fake := newTestBot(t)
+	h := handlers.NewContestCreatedHandler("", false, true)
+
+	if _, err := handle(t, b, h, contestCreated(time.Now().Add(-time.Hour))); err != nil {
+		t.Fatalf("contest.created: %v", err)
+	}
+	if n := len(fake.ScheduledEvents()); n != 0 {
+		t.Errorf("created %d scheduled events, want 0", n)
+	}
+}
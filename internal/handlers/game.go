@@ -2,98 +2,240 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"log/slog"
+	"strconv"
+	"time"
 
 	"github.com/gamers-bot/internal/bot"
+	"github.com/gamers-bot/internal/models"
+	"github.com/gamers-bot/internal/store"
 )
 
-// GameScheduledHandler handles game.scheduled events
-type GameScheduledHandler struct{}
+// gameHandler updates the game status message for one game lifecycle event type
+type gameHandler struct {
+	messages  store.GameMessageStore
+	eventType bot.GameEventType
+}
 
-func NewGameScheduledHandler() *GameScheduledHandler {
-	return &GameScheduledHandler{}
+// NewGameScheduledHandler creates a handler for game.scheduled events
+func NewGameScheduledHandler(messages store.GameMessageStore) *TypedHandler[models.GameEventPayload, models.GameNotificationResult] {
+	return newGameHandler(messages, bot.GameScheduled)
 }
 
-func (h *GameScheduledHandler) Handle(ctx context.Context, b *bot.DiscordBot, guildID string, payload map[string]interface{}) (map[string]interface{}, error) {
-	// TODO: Discord 알림 전송 로직
-	slog.Info("GameScheduledHandler invoked", "guild_id", guildID)
-	return nil, nil
+// NewGameActivatedHandler creates a handler for game.activated events
+func NewGameActivatedHandler(messages store.GameMessageStore) *TypedHandler[models.GameEventPayload, models.GameNotificationResult] {
+	return newGameHandler(messages, bot.GameActivated)
 }
 
-// GameActivatedHandler handles game.activated events
-type GameActivatedHandler struct{}
+// NewGameMatchDetectingHandler creates a handler for game.match.detecting events
+func NewGameMatchDetectingHandler(messages store.GameMessageStore) *TypedHandler[models.GameEventPayload, models.GameNotificationResult] {
+	return newGameHandler(messages, bot.GameMatchDetecting)
+}
 
-func NewGameActivatedHandler() *GameActivatedHandler {
-	return &GameActivatedHandler{}
+// NewGameMatchDetectedHandler creates a handler for game.match.detected events
+func NewGameMatchDetectedHandler(messages store.GameMessageStore) *TypedHandler[models.GameEventPayload, models.GameNotificationResult] {
+	return newGameHandler(messages, bot.GameMatchDetected)
 }
 
-func (h *GameActivatedHandler) Handle(ctx context.Context, b *bot.DiscordBot, guildID string, payload map[string]interface{}) (map[string]interface{}, error) {
-	// TODO: Discord 알림 전송 로직
-	slog.Info("GameActivatedHandler invoked", "guild_id", guildID)
-	return nil, nil
+// NewGameMatchFailedHandler creates a handler for game.match.failed events
+func NewGameMatchFailedHandler(messages store.GameMessageStore) *TypedHandler[models.GameEventPayload, models.GameNotificationResult] {
+	return newGameHandler(messages, bot.GameMatchFailed)
 }
 
-// GameMatchDetectingHandler handles game.match.detecting events
-type GameMatchDetectingHandler struct{}
+// NewGameFinishedHandler creates a handler for game.finished events
+func NewGameFinishedHandler(messages store.GameMessageStore) *TypedHandler[models.GameEventPayload, models.GameNotificationResult] {
+	return newGameHandler(messages, bot.GameFinished)
+}
 
-func NewGameMatchDetectingHandler() *GameMatchDetectingHandler {
-	return &GameMatchDetectingHandler{}
+// newGameHandler creates a handler that updates the game status message for eventType
+func newGameHandler(messages store.GameMessageStore, eventType bot.GameEventType) *TypedHandler[models.GameEventPayload, models.GameNotificationResult] {
+	h := &gameHandler{messages: messages, eventType: eventType}
+	return NewTypedHandler(h.handle)
 }
 
-func (h *GameMatchDetectingHandler) Handle(ctx context.Context, b *bot.DiscordBot, guildID string, payload map[string]interface{}) (map[string]interface{}, error) {
-	// TODO: Discord 알림 전송 로직
-	slog.Info("GameMatchDetectingHandler invoked", "guild_id", guildID)
-	return nil, nil
+// handle processes a game lifecycle event - updates the game status message
+func (h *gameHandler) handle(ctx context.Context, b *bot.DiscordBot, guildID string, payload *models.GameEventPayload) (*models.GameNotificationResult, error) {
+	return handleGameNotification(ctx, b, h.messages, payload, h.eventType)
 }
 
-// GameMatchDetectedHandler handles game.match.detected events
-type GameMatchDetectedHandler struct{}
+// ContestTeamsReadyHandler handles game.contest.teams.ready events
+type ContestTeamsReadyHandler struct{}
 
-func NewGameMatchDetectedHandler() *GameMatchDetectedHandler {
-	return &GameMatchDetectedHandler{}
+func NewContestTeamsReadyHandler() *ContestTeamsReadyHandler {
+	return &ContestTeamsReadyHandler{}
 }
 
-func (h *GameMatchDetectedHandler) Handle(ctx context.Context, b *bot.DiscordBot, guildID string, payload map[string]interface{}) (map[string]interface{}, error) {
+func (h *ContestTeamsReadyHandler) Handle(ctx context.Context, b *bot.DiscordBot, guildID string, payload map[string]interface{}) (map[string]interface{}, error) {
 	// TODO: Discord 알림 전송 로직
-	slog.Info("GameMatchDetectedHandler invoked", "guild_id", guildID)
+	slog.Info("ContestTeamsReadyHandler invoked", "guild_id", guildID)
 	return nil, nil
 }
 
-// GameMatchFailedHandler handles game.match.failed events
-type GameMatchFailedHandler struct{}
-
-func NewGameMatchFailedHandler() *GameMatchFailedHandler {
-	return &GameMatchFailedHandler{}
+// handleGameNotification is a shared function for game lifecycle events. Each game has a single
+// status message: the first event posts it, later events merge their data into the stored state
+// and edit the message in place. A new message is posted if the old one was deleted. Events older
+// than the one last applied are ignored, so a delayed retry cannot rewind the message.
+func handleGameNotification(ctx context.Context, b *bot.DiscordBot, messages store.GameMessageStore, eventPayload *models.GameEventPayload, eventType bot.GameEventType) (*models.GameNotificationResult, error) {
+	record, found, err := messages.Get(eventPayload.GameID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load game status message: %w", err)
+	}
+
+	eventAt, _ := time.Parse(time.RFC3339, eventPayload.Timestamp)
+	if found && isStaleGameEvent(record, eventType, eventAt) {
+		slog.Info("Ignoring stale game event",
+			"game_id", eventPayload.GameID,
+			"event_type", eventType,
+			"stage", record.Stage,
+			"timestamp", eventPayload.Timestamp,
+		)
+		return &models.GameNotificationResult{
+			MessageID: record.MessageID,
+			ChannelID: record.ChannelID,
+		}, nil
+	}
+	if eventAt.IsZero() && found {
+		eventAt = record.EventAt
+	}
+
+	game := buildGameNotification(eventPayload)
+	var result *models.GameNotificationResult
+
+	if found {
+		state := &bot.GameNotification{}
+		if err := json.Unmarshal(record.State, state); err != nil {
+			slog.Warn("Failed to restore game state, rendering from event only", "game_id", eventPayload.GameID, "error", err)
+		}
+		state.Merge(game)
+		game = state
+
+		result, err = b.EditGameNotification(ctx, record.ChannelID, record.MessageID, game, eventType)
+		if err != nil {
+			if _, code := bot.Classify(err); code != bot.ErrCodeUnknownResource && code != bot.ErrCodeUnknownChannel {
+				return nil, err
+			}
+			slog.Warn("Game status message is gone, posting a new one", "game_id", eventPayload.GameID, "error", err)
+			result = nil
+		}
+	}
+
+	if result == nil {
+		result, err = b.SendGameNotification(ctx, eventPayload.DiscordTextChannelID, game, eventType)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The message is already posted; a failure to record it must not trigger a retry
+	state, err := json.Marshal(game)
+	if err == nil {
+		err = messages.Put(store.GameMessage{
+			GameID:    eventPayload.GameID,
+			ChannelID: result.ChannelID,
+			MessageID: result.MessageID,
+			Stage:     string(eventType),
+			State:     state,
+			EventAt:   eventAt,
+			UpdatedAt: time.Now(),
+		})
+	}
+	if err != nil {
+		slog.Warn("Failed to save game status message", "game_id", eventPayload.GameID, "error", err)
+	}
+
+	return result, nil
 }
 
-func (h *GameMatchFailedHandler) Handle(ctx context.Context, b *bot.DiscordBot, guildID string, payload map[string]interface{}) (map[string]interface{}, error) {
-	// TODO: Discord 알림 전송 로직
-	slog.Info("GameMatchFailedHandler invoked", "guild_id", guildID)
-	return nil, nil
+// isStaleGameEvent reports whether an event would rewind the status message of record. A finished
+// game stays finished. Otherwise the event timestamps decide, or the lifecycle stages if either
+// event has no timestamp; events of the same stage (e.g. detection progress) are always applied.
+func isStaleGameEvent(record *store.GameMessage, eventType bot.GameEventType, eventAt time.Time) bool {
+	stage := bot.GameEventType(record.Stage)
+	if stage == bot.GameFinished {
+		return eventType != bot.GameFinished
+	}
+	if !eventAt.IsZero() && !record.EventAt.IsZero() {
+		return eventAt.Before(record.EventAt)
+	}
+	return eventType.StageIndex() < stage.StageIndex()
 }
 
-// GameFinishedHandler handles game.finished events
-type GameFinishedHandler struct{}
-
-func NewGameFinishedHandler() *GameFinishedHandler {
-	return &GameFinishedHandler{}
+// buildGameNotification extracts the embed contents from the free-form Data of a game event
+func buildGameNotification(p *models.GameEventPayload) *bot.GameNotification {
+	return &bot.GameNotification{
+		GameID:        p.GameID,
+		ContestID:     p.ContestID,
+		GameName:      dataString(p.Data, "game_name", "contest_title"),
+		ScheduledAt:   dataTime(p.Data, "scheduled_at", "start_time"),
+		Teams:         dataTeamNames(p.Data, "teams"),
+		DetectedCount: dataInt(p.Data, "detected_count"),
+		RequiredCount: dataInt(p.Data, "required_count"),
+		MatchID:       dataString(p.Data, "match_id"),
+		FailureReason: dataString(p.Data, "reason", "failure_reason"),
+		WinnerTeam:    dataString(p.Data, "winner_team_name", "winner"),
+		Result:        dataString(p.Data, "result", "score"),
+	}
 }
 
-func (h *GameFinishedHandler) Handle(ctx context.Context, b *bot.DiscordBot, guildID string, payload map[string]interface{}) (map[string]interface{}, error) {
-	// TODO: Discord 알림 전송 로직
-	slog.Info("GameFinishedHandler invoked", "guild_id", guildID)
-	return nil, nil
+// dataString returns the first non-empty string value among keys
+func dataString(data map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		if v, ok := data[key].(string); ok && v != "" {
+			return v
+		}
+	}
+	return ""
 }
 
-// ContestTeamsReadyHandler handles game.contest.teams.ready events
-type ContestTeamsReadyHandler struct{}
+// dataInt returns the value of key as an int; JSON numbers and numeric strings are accepted
+func dataInt(data map[string]interface{}, key string) int {
+	switch v := data[key].(type) {
+	case float64:
+		return int(v)
+	case string:
+		n, _ := strconv.Atoi(v)
+		return n
+	default:
+		return 0
+	}
+}
 
-func NewContestTeamsReadyHandler() *ContestTeamsReadyHandler {
-	return &ContestTeamsReadyHandler{}
+// dataTime returns the first parseable time among keys; RFC3339 strings and unix seconds are accepted
+func dataTime(data map[string]interface{}, keys ...string) time.Time {
+	for _, key := range keys {
+		switch v := data[key].(type) {
+		case string:
+			if t, err := time.Parse(time.RFC3339, v); err == nil {
+				return t
+			}
+		case float64:
+			if v > 0 {
+				return time.Unix(int64(v), 0)
+			}
+		}
+	}
+	return time.Time{}
 }
 
-func (h *ContestTeamsReadyHandler) Handle(ctx context.Context, b *bot.DiscordBot, guildID string, payload map[string]interface{}) (map[string]interface{}, error) {
-	// TODO: Discord 알림 전송 로직
-	slog.Info("ContestTeamsReadyHandler invoked", "guild_id", guildID)
-	return nil, nil
+// dataTeamNames returns team names from a list of strings or objects with a team_name/name field
+func dataTeamNames(data map[string]interface{}, key string) []string {
+	items, ok := data[key].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var names []string
+	for _, item := range items {
+		switch v := item.(type) {
+		case string:
+			names = append(names, v)
+		case map[string]interface{}:
+			if name := dataString(v, "team_name", "name"); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
 }
@@ -0,0 +1,165 @@
+package handlers_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamers-bot/internal/handlers"
+	"github.com/gamers-bot/internal/store"
+)
+
+// gameEvent returns a game event payload for game 42 with the given timestamp and data
+func gameEvent(eventType string, at time.Time, data map[string]interface{}) map[string]interface{} {
+	event := map[string]interface{}{
+		"event_id":                "evt-" + eventType,
+		"event_type":              eventType,
+		"game_id":                 42,
+		"contest_id":              7,
+		"discord_guild_id":        testGuildID,
+		"discord_text_channel_id": testTextChannelID,
+		"data":                    data,
+	}
+	if !at.IsZero() {
+		event["timestamp"] = at.Format(time.RFC3339)
+	}
+	return event
+}
+
+func TestGameEventsEditOneStatusMessage(t *testing.T) {
+	b, fake := newTestBot(t)
+	messages := store.NewMemoryGameMessageStore()
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	result, err := handle(t, b, handlers.NewGameScheduledHandler(messages), gameEvent("game.scheduled", start, map[string]interface{}{
+		"game_name":    "Finals",
+		"scheduled_at": start.Add(time.Hour).Format(time.RFC3339),
+		"teams":        []interface{}{"Red", map[string]interface{}{"team_name": "Blue"}},
+	}))
+	if err != nil {
+		t.Fatalf("game.scheduled: %v", err)
+	}
+	if result["edited"] != false {
+		t.Errorf("game.scheduled result edited = %v, want false", result["edited"])
+	}
+
+	_, err = handle(t, b, handlers.NewGameMatchDetectedHandler(messages), gameEvent("game.match.detected", start.Add(2*time.Hour), map[string]interface{}{
+		"match_id":       "KR-123",
+		"detected_count": 10,
+		"required_count": 10,
+	}))
+	if err != nil {
+		t.Fatalf("game.match.detected: %v", err)
+	}
+
+	message := onlyMessage(t, fake)
+	if !message.Edited || message.ChannelID != testTextChannelID {
+		t.Errorf("message = %+v, want the status message edited in place", message)
+	}
+	embed := message.Embeds[0]
+	if embed.Title != "[マッチ検出完了] Finals" {
+		t.Errorf("title = %q", embed.Title)
+	}
+	if teams, _ := fieldValue(embed, "参加チーム"); teams != "**Red** vs **Blue**" {
+		t.Errorf("teams = %q, want the teams of game.scheduled", teams)
+	}
+	if matchID, _ := fieldValue(embed, "マッチID"); matchID != "KR-123" {
+		t.Errorf("match ID = %q", matchID)
+	}
+	if progress, _ := fieldValue(embed, "検出状況"); !strings.Contains(progress, "10/10") {
+		t.Errorf("detection progress = %q", progress)
+	}
+}
+
+func TestLateGameEventDoesNotRewindStatusMessage(t *testing.T) {
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		lateAt      time.Time
+		detectedAt  time.Time
+		lateHandler func(store.GameMessageStore) handlers.Handler
+		lateType    string
+	}{
+		{"older timestamp", start, start.Add(time.Minute), func(m store.GameMessageStore) handlers.Handler { return handlers.NewGameActivatedHandler(m) }, "game.activated"},
+		{"earlier stage without timestamps", time.Time{}, time.Time{}, func(m store.GameMessageStore) handlers.Handler { return handlers.NewGameActivatedHandler(m) }, "game.activated"},
+		{"earlier stage with one timestamp", start.Add(time.Hour), time.Time{}, func(m store.GameMessageStore) handlers.Handler { return handlers.NewGameMatchDetectingHandler(m) }, "game.match.detecting"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, fake := newTestBot(t)
+			messages := store.NewMemoryGameMessageStore()
+
+			if _, err := handle(t, b, handlers.NewGameMatchDetectedHandler(messages), gameEvent("game.match.detected", tt.detectedAt, map[string]interface{}{"match_id": "KR-123"})); err != nil {
+				t.Fatal(err)
+			}
+			result, err := handle(t, b, tt.lateHandler(messages), gameEvent(tt.lateType, tt.lateAt, nil))
+			if err != nil {
+				t.Fatalf("late %s: %v", tt.lateType, err)
+			}
+
+			message := onlyMessage(t, fake)
+			if message.Edited {
+				t.Errorf("status message edited by the late %s", tt.lateType)
+			}
+			if title := message.Embeds[0].Title; title != "[マッチ検出完了]" {
+				t.Errorf("title = %q, want the match detected stage", title)
+			}
+			if result["message_id"] != message.ID {
+				t.Errorf("result message_id = %v, want the existing message %s", result["message_id"], message.ID)
+			}
+			if record, _, _ := messages.Get(42); record.Stage != "MATCH_DETECTED" {
+				t.Errorf("stored stage = %s, want MATCH_DETECTED", record.Stage)
+			}
+		})
+	}
+}
+
+func TestGameEventsOfTheSameStageAreApplied(t *testing.T) {
+	b, fake := newTestBot(t)
+	messages := store.NewMemoryGameMessageStore()
+	h := handlers.NewGameMatchDetectingHandler(messages)
+
+	for _, detected := range []int{3, 7} {
+		if _, err := handle(t, b, h, gameEvent("game.match.detecting", time.Time{}, map[string]interface{}{"detected_count": detected, "required_count": 10})); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A failed detection can be retried
+	if _, err := handle(t, b, handlers.NewGameMatchFailedHandler(messages), gameEvent("game.match.failed", time.Time{}, map[string]interface{}{"reason": "timeout"})); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := handle(t, b, h, gameEvent("game.match.detecting", time.Time{}, map[string]interface{}{"detected_count": 1, "required_count": 10})); err != nil {
+		t.Fatal(err)
+	}
+
+	embed := onlyMessage(t, fake).Embeds[0]
+	if embed.Title != "[マッチ検出中]" {
+		t.Errorf("title = %q, want detection in progress", embed.Title)
+	}
+	if progress, _ := fieldValue(embed, "検出状況"); !strings.Contains(progress, "1/10") {
+		t.Errorf("detection progress = %q, want 1/10", progress)
+	}
+}
+
+func TestFinishedGameStaysFinished(t *testing.T) {
+	b, fake := newTestBot(t)
+	messages := store.NewMemoryGameMessageStore()
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	if _, err := handle(t, b, handlers.NewGameFinishedHandler(messages), gameEvent("game.finished", start, map[string]interface{}{"winner_team_name": "Red", "result": "13-7"})); err != nil {
+		t.Fatal(err)
+	}
+	// Even with a newer timestamp, e.g. from a clock skewed producer
+	if _, err := handle(t, b, handlers.NewGameActivatedHandler(messages), gameEvent("game.activated", start.Add(time.Minute), nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	message := onlyMessage(t, fake)
+	if message.Edited {
+		t.Error("finished game message edited")
+	}
+	if winner, _ := fieldValue(message.Embeds[0], "勝者"); winner != "🏆 **Red**" {
+		t.Errorf("winner = %q", winner)
+	}
+}
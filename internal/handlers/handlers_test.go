@@ -0,0 +1,167 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/gamers-bot/internal/bot"
+	"github.com/gamers-bot/internal/bot/discordtest"
+	"github.com/gamers-bot/internal/handlers"
+)
+
+const (
+	testGuildID       = "900000000000000001"
+	testTextChannelID = "900000000000000002"
+	testVoiceFromID   = "900000000000000003"
+	testVoiceToID     = "900000000000000004"
+)
+
+// newTestBot returns a connected bot backed by a fake Discord API with one guild, a text
+// channel and two voice channels
+func newTestBot(t *testing.T) (*bot.DiscordBot, *discordtest.Fake) {
+	t.Helper()
+	fake := discordtest.New()
+	fake.AddGuild(&discordgo.Guild{
+		ID:   testGuildID,
+		Name: "Test Guild",
+		Channels: []*discordgo.Channel{
+			{ID: testTextChannelID, GuildID: testGuildID, Name: "general", Type: discordgo.ChannelTypeGuildText},
+			{ID: testVoiceFromID, GuildID: testGuildID, Name: "lobby", Type: discordgo.ChannelTypeGuildVoice},
+			{ID: testVoiceToID, GuildID: testGuildID, Name: "team-a", Type: discordgo.ChannelTypeGuildVoice},
+		},
+	})
+	b := bot.NewWithAPI(fake)
+	if err := b.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	return b, fake
+}
+
+// handle calls h with payload, encoded as the consumer decodes it from a delivery
+func handle(t *testing.T, b *bot.DiscordBot, h handlers.Handler, payload interface{}) (map[string]interface{}, error) {
+	t.Helper()
+	body, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	return h.Handle(context.Background(), b, testGuildID, decoded)
+}
+
+// onlyMessage returns the single message recorded by the fake
+func onlyMessage(t *testing.T, fake *discordtest.Fake) discordtest.Message {
+	t.Helper()
+	messages := fake.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("got %d messages, want 1: %+v", len(messages), messages)
+	}
+	return messages[0]
+}
+
+// fieldValue returns the value of the embed field with the given name
+func fieldValue(embed *discordgo.MessageEmbed, name string) (string, bool) {
+	for _, field := range embed.Fields {
+		if field.Name == name {
+			return field.Value, true
+		}
+	}
+	return "", false
+}
+
+// errorCode returns the error code of err, failing the test if err is nil
+func errorCode(t *testing.T, err error) bot.ErrorCode {
+	t.Helper()
+	if err == nil {
+		t.Fatal("got no error")
+	}
+	_, code := bot.Classify(err)
+	return code
+}
+
+// buttons returns the buttons of message components, in order
+func buttons(components []discordgo.MessageComponent) []discordgo.Button {
+	var found []discordgo.Button
+	for _, component := range components {
+		if row, ok := component.(discordgo.ActionsRow); ok {
+			for _, c := range row.Components {
+				if button, ok := c.(discordgo.Button); ok {
+					found = append(found, button)
+				}
+			}
+		}
+	}
+	return found
+}
+
+// publishedEvent is an event published by the bot through an eventRecorder
+type publishedEvent struct {
+	EventType     string
+	CorrelationID string
+	Payload       map[string]interface{}
+}
+
+// eventRecorder is a bot.EventPublisher recording published events
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []publishedEvent
+	err    error
+}
+
+func (r *eventRecorder) PublishEvent(_ context.Context, eventType, correlationID string, payload interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return err
+	}
+	r.events = append(r.events, publishedEvent{EventType: eventType, CorrelationID: correlationID, Payload: decoded})
+	return nil
+}
+
+// onlyEvent returns the single event recorded by r
+func (r *eventRecorder) onlyEvent(t *testing.T) publishedEvent {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.events) != 1 {
+		t.Fatalf("got %d published events, want 1: %+v", len(r.events), r.events)
+	}
+	return r.events[0]
+}
+
+// errPublisherDown is returned by an eventRecorder while RabbitMQ is unavailable
+var errPublisherDown = errors.New("publisher is down")
+
+// clickButton delivers a click on a button of message by member, as the gateway would
+func clickButton(b *bot.DiscordBot, message discordtest.Message, button discordgo.Button, member *discordgo.Member) {
+	i := &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		ID:        "interaction-" + button.CustomID,
+		Type:      discordgo.InteractionMessageComponent,
+		ChannelID: message.ChannelID,
+		Message:   &discordgo.Message{ID: message.ID, ChannelID: message.ChannelID, Content: message.Content, Components: message.Components},
+		Data:      discordgo.MessageComponentInteractionData{CustomID: button.CustomID, ComponentType: discordgo.ButtonComponent},
+	}}
+	if member != nil {
+		i.GuildID = testGuildID
+		i.Member = member
+	} else {
+		// Direct messages carry the user instead of a member
+		i.User = &discordgo.User{ID: message.UserID}
+	}
+	b.HandleInteraction(i)
+}
@@ -0,0 +1,208 @@
+package handlers_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/gamers-bot/internal/bot"
+	"github.com/gamers-bot/internal/bot/discordtest"
+	"github.com/gamers-bot/internal/handlers"
+	"github.com/gamers-bot/internal/rabbitmq"
+	"github.com/gamers-bot/internal/rabbitmq/rabbitmqtest"
+)
+
+const testOrganizerRoleID = "700000000000000001"
+
+// organizer is a guild member holding the organizer role
+var organizer = &discordgo.Member{
+	User:  &discordgo.User{ID: testOrganizerDiscordID},
+	Roles: []string{testOrganizerRoleID},
+}
+
+// onlyResponse returns the single interaction response recorded by the fake
+func onlyResponse(t *testing.T, fake *discordtest.Fake) *discordgo.InteractionResponse {
+	t.Helper()
+	responses := fake.InteractionResponses()
+	if len(responses) != 1 {
+		t.Fatalf("got %d interaction responses, want 1: %+v", len(responses), responses)
+	}
+	return responses[0].Response
+}
+
+// sendApplicationRequest posts an application request and returns the message with its buttons
+func sendApplicationRequest(t *testing.T, b *bot.DiscordBot, fake *discordtest.Fake) (discordtest.Message, []discordgo.Button) {
+	t.Helper()
+	if _, err := handle(t, b, handlers.NewApplicationRequestedHandler(), application("application.requested", nil)); err != nil {
+		t.Fatalf("application.requested: %v", err)
+	}
+	message := onlyMessage(t, fake)
+	fake.Reset()
+	return message, buttons(message.Components)
+}
+
+func TestTeamInviteAcceptClickPublishesCommand(t *testing.T) {
+	b, fake := newTestBot(t)
+	events := &eventRecorder{}
+	b.SetEventPublisher(events)
+
+	if _, err := handle(t, b, handlers.NewTeamInviteSentHandler(), teamInvite("team.invite.sent")); err != nil {
+		t.Fatalf("team.invite.sent: %v", err)
+	}
+	dm := fake.DirectMessages()[0]
+
+	clickButton(b, dm, buttons(dm.Components)[0], nil)
+
+	event := events.onlyEvent(t)
+	if event.EventType != bot.CommandTeamInviteAcceptRequested {
+		t.Errorf("event type = %s, want %s", event.EventType, bot.CommandTeamInviteAcceptRequested)
+	}
+	if event.Payload["game_id"] != float64(42) || event.Payload["invitee_user_id"] != float64(2) ||
+		event.Payload["inviter_user_id"] != float64(1) || event.Payload["invitee_discord_id"] != testInviteeDiscordID {
+		t.Errorf("payload = %v", event.Payload)
+	}
+
+	resp := onlyResponse(t, fake)
+	if resp.Type != discordgo.InteractionResponseUpdateMessage {
+		t.Fatalf("response type = %v, want an update of the invite", resp.Type)
+	}
+	if !strings.HasSuffix(resp.Data.Content, "**✅ 招待を承諾しました。**") || len(resp.Data.Components) != 0 {
+		t.Errorf("updated invite = %q with %d components, want the outcome without buttons", resp.Data.Content, len(resp.Data.Components))
+	}
+}
+
+func TestTeamInviteClickWithoutPublisherIsRefused(t *testing.T) {
+	b, fake := newTestBot(t)
+	events := &eventRecorder{err: errPublisherDown}
+	b.SetEventPublisher(events)
+
+	if _, err := handle(t, b, handlers.NewTeamInviteSentHandler(), teamInvite("team.invite.sent")); err != nil {
+		t.Fatalf("team.invite.sent: %v", err)
+	}
+	dm := fake.DirectMessages()[0]
+
+	clickButton(b, dm, buttons(dm.Components)[1], nil)
+
+	resp := onlyResponse(t, fake)
+	if resp.Type != discordgo.InteractionResponseChannelMessageWithSource || resp.Data.Flags&discordgo.MessageFlagsEphemeral == 0 {
+		t.Errorf("response = %+v, want an ephemeral error that keeps the buttons", resp)
+	}
+}
+
+func TestTeamInviteClickWithUnroutableCommandIsRefused(t *testing.T) {
+	b, fake := newTestBot(t)
+	broker := rabbitmqtest.NewBroker()
+	conn, err := broker.Dial("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	// No queue is bound for the command, so the broker returns it
+	publisher, err := rabbitmq.NewEventPublisher(conn, "gamers.events")
+	if err != nil {
+		t.Fatalf("NewEventPublisher: %v", err)
+	}
+	defer publisher.Close()
+	b.SetEventPublisher(publisher)
+
+	if _, err := handle(t, b, handlers.NewTeamInviteSentHandler(), teamInvite("team.invite.sent")); err != nil {
+		t.Fatalf("team.invite.sent: %v", err)
+	}
+	dm := fake.DirectMessages()[0]
+
+	clickButton(b, dm, buttons(dm.Components)[0], nil)
+
+	resp := onlyResponse(t, fake)
+	if resp.Type != discordgo.InteractionResponseChannelMessageWithSource || resp.Data.Flags&discordgo.MessageFlagsEphemeral == 0 {
+		t.Errorf("response = %+v, want an ephemeral error that keeps the buttons", resp)
+	}
+}
+
+func TestApplicationApproveClickPublishesCommand(t *testing.T) {
+	b, fake := newTestBot(t)
+	b.SetOrganizerRoleID(testOrganizerRoleID)
+	events := &eventRecorder{}
+	b.SetEventPublisher(events)
+	message, review := sendApplicationRequest(t, b, fake)
+
+	clickButton(b, message, review[0], organizer)
+
+	event := events.onlyEvent(t)
+	if event.EventType != bot.CommandApplicationApproveRequested {
+		t.Errorf("event type = %s, want %s", event.EventType, bot.CommandApplicationApproveRequested)
+	}
+	if event.Payload["contest_id"] != float64(7) || event.Payload["user_id"] != float64(5) ||
+		event.Payload["discord_user_id"] != testApplicantDiscordID || event.Payload["processed_by_discord_id"] != testOrganizerDiscordID {
+		t.Errorf("payload = %v", event.Payload)
+	}
+
+	resp := onlyResponse(t, fake)
+	if resp.Type != discordgo.InteractionResponseUpdateMessage || len(resp.Data.Components) != 0 {
+		t.Fatalf("response = %+v, want the request updated without buttons", resp)
+	}
+	if !strings.Contains(resp.Data.Content, "✅ <@"+testOrganizerDiscordID+">さんが承認しました。") {
+		t.Errorf("updated request = %q", resp.Data.Content)
+	}
+}
+
+func TestApplicationRejectAsksForReason(t *testing.T) {
+	b, fake := newTestBot(t)
+	b.SetOrganizerRoleID(testOrganizerRoleID)
+	events := &eventRecorder{}
+	b.SetEventPublisher(events)
+	message, review := sendApplicationRequest(t, b, fake)
+
+	// The reject button opens a modal instead of publishing
+	clickButton(b, message, review[1], organizer)
+
+	modal := onlyResponse(t, fake)
+	if modal.Type != discordgo.InteractionResponseModal {
+		t.Fatalf("response type = %v, want a modal", modal.Type)
+	}
+	if len(events.events) != 0 {
+		t.Fatalf("published %d events before the reason was submitted", len(events.events))
+	}
+	fake.Reset()
+
+	b.HandleInteraction(&discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		ID:      "interaction-modal",
+		Type:    discordgo.InteractionModalSubmit,
+		GuildID: testGuildID,
+		Member:  organizer,
+		Message: &discordgo.Message{ID: message.ID, ChannelID: message.ChannelID, Content: message.Content},
+		Data: discordgo.ModalSubmitInteractionData{
+			CustomID: modal.Data.CustomID,
+			Components: []discordgo.MessageComponent{
+				&discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+					&discordgo.TextInput{CustomID: "reason", Value: "  Team is full  "},
+				}},
+			},
+		},
+	}})
+
+	event := events.onlyEvent(t)
+	if event.EventType != bot.CommandApplicationRejectRequested || event.Payload["reason"] != "Team is full" {
+		t.Errorf("event = %+v, want a reject request with the reason", event)
+	}
+	if resp := onlyResponse(t, fake); !strings.Contains(resp.Data.Content, "理由: Team is full") {
+		t.Errorf("updated request = %q", resp.Data.Content)
+	}
+}
+
+func TestApplicationClickByNonOrganizerIsRefused(t *testing.T) {
+	b, fake := newTestBot(t)
+	b.SetOrganizerRoleID(testOrganizerRoleID)
+	events := &eventRecorder{}
+	b.SetEventPublisher(events)
+	message, review := sendApplicationRequest(t, b, fake)
+
+	member := &discordgo.Member{User: &discordgo.User{ID: testApplicantDiscordID}, Permissions: discordgo.PermissionManageGuild}
+	clickButton(b, message, review[0], member)
+
+	if resp := onlyResponse(t, fake); resp.Data.Content != "この操作は運営人のみ可能です。" {
+		t.Errorf("response = %q, want a refusal", resp.Data.Content)
+	}
+	if len(events.events) != 0 {
+		t.Errorf("published %d events, want 0", len(events.events))
+	}
+}
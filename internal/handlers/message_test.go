@@ -0,0 +1,168 @@
+package handlers_test
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/gamers-bot/internal/bot"
+	"github.com/gamers-bot/internal/bot/discordtest"
+	"github.com/gamers-bot/internal/handlers"
+)
+
+func TestSendMessage(t *testing.T) {
+	b, fake := newTestBot(t)
+
+	result, err := handle(t, b, handlers.NewMessageHandler(), map[string]interface{}{
+		"channel_id": testTextChannelID,
+		"content":    "hello",
+	})
+	if err != nil {
+		t.Fatalf("SEND_MESSAGE: %v", err)
+	}
+
+	message := onlyMessage(t, fake)
+	if message.ChannelID != testTextChannelID || message.Content != "hello" {
+		t.Errorf("message = %+v", message)
+	}
+	if result["message_id"] != message.ID {
+		t.Errorf("result message_id = %v, want %s", result["message_id"], message.ID)
+	}
+}
+
+func TestSendMessageRequiresContent(t *testing.T) {
+	b, fake := newTestBot(t)
+
+	_, err := handle(t, b, handlers.NewMessageHandler(), map[string]interface{}{"channel_id": testTextChannelID})
+	if code := errorCode(t, err); code != bot.ErrCodeValidation {
+		t.Errorf("error code = %s, want %s", code, bot.ErrCodeValidation)
+	}
+	if n := len(fake.Messages()); n != 0 {
+		t.Errorf("sent %d messages, want 0", n)
+	}
+}
+
+func TestSendMessageToUnknownChannelIsPermanent(t *testing.T) {
+	b, fake := newTestBot(t)
+	fake.Fail("ChannelMessageSend", discordtest.RESTError(http.StatusNotFound, discordgo.ErrCodeUnknownChannel, "Unknown Channel"))
+
+	_, err := handle(t, b, handlers.NewMessageHandler(), map[string]interface{}{
+		"channel_id": testTextChannelID,
+		"content":    "hello",
+	})
+	kind, code := bot.Classify(err)
+	if kind != bot.ErrorKindPermanent || code != bot.ErrCodeUnknownChannel {
+		t.Errorf("Classify(err) = %s/%s, want PERMANENT/%s", kind, code, bot.ErrCodeUnknownChannel)
+	}
+}
+
+func TestMoveMembers(t *testing.T) {
+	b, fake := newTestBot(t)
+
+	result, err := handle(t, b, handlers.NewVoiceHandler(), map[string]interface{}{
+		"from_channel_id": testVoiceFromID,
+		"to_channel_id":   testVoiceToID,
+		"user_ids":        []string{"1", "2"},
+	})
+	if err != nil {
+		t.Fatalf("MOVE_MEMBERS: %v", err)
+	}
+
+	want := []discordtest.Move{
+		{GuildID: testGuildID, UserID: "1", ChannelID: testVoiceToID},
+		{GuildID: testGuildID, UserID: "2", ChannelID: testVoiceToID},
+	}
+	if moves := fake.Moves(); !reflect.DeepEqual(moves, want) {
+		t.Errorf("moves = %+v, want %+v", moves, want)
+	}
+	if result["moved_count"] != float64(2) {
+		t.Errorf("moved_count = %v, want 2", result["moved_count"])
+	}
+}
+
+func TestMoveMembersReportsFailedUsers(t *testing.T) {
+	b, fake := newTestBot(t)
+	fake.Fail("GuildMemberMove", discordtest.RESTError(http.StatusForbidden, discordgo.ErrCodeMissingPermissions, "Missing Permissions"))
+
+	result, err := handle(t, b, handlers.NewVoiceHandler(), map[string]interface{}{
+		"from_channel_id": testVoiceFromID,
+		"to_channel_id":   testVoiceToID,
+		"user_ids":        []string{"1"},
+	})
+	if err != nil {
+		t.Fatalf("MOVE_MEMBERS: %v", err)
+	}
+	if result["moved_count"] != float64(0) || !reflect.DeepEqual(result["failed_users"], []interface{}{"1"}) {
+		t.Errorf("result = %v, want user 1 failed", result)
+	}
+}
+
+func TestMoveMembersRejectsSameChannel(t *testing.T) {
+	b, fake := newTestBot(t)
+
+	_, err := handle(t, b, handlers.NewVoiceHandler(), map[string]interface{}{
+		"from_channel_id": testVoiceFromID,
+		"to_channel_id":   testVoiceFromID,
+	})
+	if code := errorCode(t, err); code != bot.ErrCodeValidation {
+		t.Errorf("error code = %s, want %s", code, bot.ErrCodeValidation)
+	}
+	if n := len(fake.Moves()); n != 0 {
+		t.Errorf("moved %d members, want 0", n)
+	}
+}
+
+func TestGetChannels(t *testing.T) {
+	b, _ := newTestBot(t)
+
+	tests := []struct {
+		name    string
+		handler handlers.Handler
+		want    []interface{}
+	}{
+		{"voice", handlers.NewVoiceChannelHandler(), []interface{}{
+			map[string]interface{}{"id": testVoiceFromID, "name": "lobby"},
+			map[string]interface{}{"id": testVoiceToID, "name": "team-a"},
+		}},
+		{"text", handlers.NewTextChannelHandler(), []interface{}{
+			map[string]interface{}{"id": testTextChannelID, "name": "general"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handle(t, b, tt.handler, map[string]interface{}{})
+			if err != nil {
+				t.Fatalf("Handle: %v", err)
+			}
+			if !reflect.DeepEqual(result["channels"], tt.want) {
+				t.Errorf("channels = %v, want %v", result["channels"], tt.want)
+			}
+		})
+	}
+}
+
+func TestSendContestInvitation(t *testing.T) {
+	b, fake := newTestBot(t)
+
+	result, err := handle(t, b, handlers.NewContestInvitationHandler(), map[string]interface{}{
+		"channel_id":   testTextChannelID,
+		"user_ids":     []string{"11", "12"},
+		"contest_name": "Spring Cup",
+		"message":      "Join us!",
+	})
+	if err != nil {
+		t.Fatalf("SEND_CONTEST_INVITATION: %v", err)
+	}
+
+	message := onlyMessage(t, fake)
+	for _, want := range []string{"Spring Cup", "Join us!", "<@11>", "<@12>"} {
+		if !strings.Contains(message.Content, want) {
+			t.Errorf("content %q does not contain %q", message.Content, want)
+		}
+	}
+	if !reflect.DeepEqual(result["notified_users"], []interface{}{"11", "12"}) {
+		t.Errorf("notified_users = %v", result["notified_users"])
+	}
+}
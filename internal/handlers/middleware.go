@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/gamers-bot/internal/bot"
+)
+
+// Invocation is a single handler call travelling through a middleware chain
+type Invocation struct {
+	Bot     *bot.DiscordBot
+	GuildID string
+	Payload map[string]interface{}
+	Raw     json.RawMessage // undecoded payload; nil when only Payload is available
+}
+
+// Next calls the rest of a middleware chain
+type Next func(ctx context.Context, inv *Invocation) (map[string]interface{}, error)
+
+// Middleware wraps a handler call, e.g. to recover panics or add a deadline
+type Middleware func(next Next) Next
+
+// Chain is a Handler wrapped in middlewares. The first middleware is the outermost.
+type Chain struct {
+	handler Handler
+	next    Next
+}
+
+// NewChain wraps handler in middlewares
+func NewChain(handler Handler, middlewares ...Middleware) *Chain {
+	c := &Chain{handler: handler}
+	c.next = c.call
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		c.next = middlewares[i](c.next)
+	}
+	return c
+}
+
+// Handle implements Handler
+func (c *Chain) Handle(ctx context.Context, b *bot.DiscordBot, guildID string, payload map[string]interface{}) (map[string]interface{}, error) {
+	return c.next(ctx, &Invocation{Bot: b, GuildID: guildID, Payload: payload})
+}
+
+// HandleRaw implements RawHandler
+func (c *Chain) HandleRaw(ctx context.Context, b *bot.DiscordBot, guildID string, payload json.RawMessage) (map[string]interface{}, error) {
+	return c.next(ctx, &Invocation{Bot: b, GuildID: guildID, Raw: payload})
+}
+
+// call invokes the wrapped handler, preferring the raw payload for handlers that decode it themselves
+func (c *Chain) call(ctx context.Context, inv *Invocation) (map[string]interface{}, error) {
+	if rawHandler, ok := c.handler.(RawHandler); ok && inv.Raw != nil {
+		return rawHandler.HandleRaw(ctx, inv.Bot, inv.GuildID, inv.Raw)
+	}
+
+	payload := inv.Payload
+	if payload == nil && inv.Raw != nil {
+		if err := json.Unmarshal(inv.Raw, &payload); err != nil {
+			return nil, bot.NewInvalidPayloadError("failed to unmarshal payload: %w", err)
+		}
+	}
+	return c.handler.Handle(ctx, inv.Bot, inv.GuildID, payload)
+}
+
+// EventInfo identifies the event being handled, for logging and metrics
+type EventInfo struct {
+	EventType     string
+	EventID       string
+	CorrelationID string
+	Queue         string
+}
+
+type eventInfoKey struct{}
+
+// WithEventInfo returns a context carrying info
+func WithEventInfo(ctx context.Context, info EventInfo) context.Context {
+	return context.WithValue(ctx, eventInfoKey{}, info)
+}
+
+// EventInfoFrom returns the EventInfo carried by ctx, or the zero value
+func EventInfoFrom(ctx context.Context) EventInfo {
+	info, _ := ctx.Value(eventInfoKey{}).(EventInfo)
+	return info
+}
+
+// Recover turns a handler panic into a permanent HANDLER_PANIC error, so the message is
+// dead-lettered instead of crashing the consumer
+func Recover() Middleware {
+	return func(next Next) Next {
+		return func(ctx context.Context, inv *Invocation) (data map[string]interface{}, err error) {
+			defer func() {
+				p := recover()
+				if p == nil {
+					return
+				}
+				stack := debug.Stack()
+
+				info := EventInfoFrom(ctx)
+				slog.Error("Handler panicked",
+					"event_type", info.EventType,
+					"event_id", info.EventID,
+					"correlation_id", info.CorrelationID,
+					"panic", p,
+					"stack", string(stack),
+				)
+				data, err = nil, bot.NewError(bot.ErrorKindPermanent, bot.ErrCodeHandlerPanic, fmt.Errorf("handler panicked: %v", p))
+			}()
+			return next(ctx, inv)
+		}
+	}
+}
+
+// Timeout gives the handler a deadline through its context and waits for it to return, so a
+// message is never retried while its handler is still running. An error returned after the
+// deadline expired becomes a transient TIMEOUT error so the message is retried, unless it is
+// classified as permanent; a handler that finishes its work despite the deadline keeps its
+// result. A zero timeout disables the deadline.
+func Timeout(timeout time.Duration) Middleware {
+	return func(next Next) Next {
+		if timeout <= 0 {
+			return next
+		}
+		return func(ctx context.Context, inv *Invocation) (map[string]interface{}, error) {
+			handlerCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			start := time.Now()
+			data, err := next(handlerCtx, inv)
+			if handlerCtx.Err() == nil || ctx.Err() != nil {
+				// Finished in time, or canceled by the caller (shutdown)
+				return data, err
+			}
+
+			info := EventInfoFrom(ctx)
+			slog.Warn("Handler exceeded its deadline",
+				"event_type", info.EventType,
+				"event_id", info.EventID,
+				"timeout", timeout,
+				"duration", time.Since(start),
+				"error", err,
+			)
+			if err != nil {
+				if kind, _ := bot.Classify(err); kind == bot.ErrorKindPermanent {
+					return nil, err
+				}
+				return nil, bot.NewError(bot.ErrorKindTransient, bot.ErrCodeTimeout, fmt.Errorf("handler did not finish within %s: %w", timeout, err))
+			}
+			return data, nil
+		}
+	}
+}
+
+// Logging logs the outcome and duration of every handler call with the event's identifiers
+func Logging() Middleware {
+	return func(next Next) Next {
+		return func(ctx context.Context, inv *Invocation) (map[string]interface{}, error) {
+			start := time.Now()
+			data, err := next(ctx, inv)
+
+			info := EventInfoFrom(ctx)
+			attrs := []any{
+				"event_type", info.EventType,
+				"event_id", info.EventID,
+				"correlation_id", info.CorrelationID,
+				"queue", info.Queue,
+				"guild_id", inv.GuildID,
+				"duration", time.Since(start),
+			}
+			if err != nil {
+				kind, code := bot.Classify(err)
+				slog.Warn("Handler call failed", append(attrs, "error_kind", kind, "error_code", code, "error", err)...)
+			} else {
+				slog.Info("Handler call succeeded", attrs...)
+			}
+			return data, err
+		}
+	}
+}
+
+// MetricsRecorder records the latency and outcome of handler calls
+type MetricsRecorder interface {
+	ObserveHandler(eventType, outcome string, duration time.Duration)
+}
+
+// OutcomeSuccess is the outcome recorded for handler calls that returned no error
+const OutcomeSuccess = "success"
+
+// Metrics records every handler call in recorder. The outcome is "success" or the lower-case
+// error kind (transient, permanent, canceled).
+func Metrics(recorder MetricsRecorder) Middleware {
+	return func(next Next) Next {
+		return func(ctx context.Context, inv *Invocation) (map[string]interface{}, error) {
+			start := time.Now()
+			data, err := next(ctx, inv)
+			recorder.ObserveHandler(EventInfoFrom(ctx).EventType, Outcome(err), time.Since(start))
+			return data, err
+		}
+	}
+}
+
+// Outcome returns the metrics outcome label of a handler error
+func Outcome(err error) string {
+	if err == nil {
+		return OutcomeSuccess
+	}
+	kind, _ := bot.Classify(err)
+	return strings.ToLower(string(kind))
+}
@@ -0,0 +1,145 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/gamers-bot/internal/bot"
+	"github.com/gamers-bot/internal/bot/discordtest"
+	"github.com/gamers-bot/internal/handlers"
+)
+
+// funcHandler adapts a function to handlers.Handler
+type funcHandler func(ctx context.Context) (map[string]interface{}, error)
+
+func (f funcHandler) Handle(ctx context.Context, _ *bot.DiscordBot, _ string, _ map[string]interface{}) (map[string]interface{}, error) {
+	return f(ctx)
+}
+
+// slowHandler ignores its context, returns result after delay and records that it finished
+func slowHandler(delay time.Duration, finished *atomic.Bool, data map[string]interface{}, err error) handlers.Handler {
+	return funcHandler(func(context.Context) (map[string]interface{}, error) {
+		time.Sleep(delay)
+		finished.Store(true)
+		return data, err
+	})
+}
+
+func TestTimeoutWaitsForHandlerToReturn(t *testing.T) {
+	var finished atomic.Bool
+	unavailable := discordtest.RESTError(http.StatusServiceUnavailable, 0, "Service Unavailable")
+	chain := handlers.NewChain(slowHandler(50*time.Millisecond, &finished, nil, unavailable), handlers.Timeout(10*time.Millisecond))
+
+	_, err := chain.Handle(context.Background(), nil, "", nil)
+	if !finished.Load() {
+		t.Fatal("Timeout returned while the handler was still running")
+	}
+	if kind, code := bot.Classify(err); kind != bot.ErrorKindTransient || code != bot.ErrCodeTimeout {
+		t.Errorf("Classify(err) = %s/%s, want TRANSIENT/TIMEOUT (err: %v)", kind, code, err)
+	}
+}
+
+func TestTimeoutPassesDeadlineToHandler(t *testing.T) {
+	chain := handlers.NewChain(funcHandler(func(ctx context.Context) (map[string]interface{}, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("handler context has no deadline")
+		}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}), handlers.Timeout(10*time.Millisecond))
+
+	_, err := chain.Handle(context.Background(), nil, "", nil)
+	if _, code := bot.Classify(err); code != bot.ErrCodeTimeout {
+		t.Errorf("error code = %s, want TIMEOUT (err: %v)", code, err)
+	}
+}
+
+func TestTimeoutKeepsLateSuccess(t *testing.T) {
+	var finished atomic.Bool
+	want := map[string]interface{}{"message_id": "1"}
+	chain := handlers.NewChain(slowHandler(30*time.Millisecond, &finished, want, nil), handlers.Timeout(10*time.Millisecond))
+
+	data, err := chain.Handle(context.Background(), nil, "", nil)
+	if err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if data["message_id"] != "1" {
+		t.Errorf("data = %v, want %v", data, want)
+	}
+}
+
+func TestTimeoutKeepsLatePermanentError(t *testing.T) {
+	var finished atomic.Bool
+	permanent := bot.NewError(bot.ErrorKindPermanent, bot.ErrCodeUnknownChannel, errors.New("unknown channel"))
+	chain := handlers.NewChain(slowHandler(30*time.Millisecond, &finished, nil, permanent), handlers.Timeout(10*time.Millisecond))
+
+	_, err := chain.Handle(context.Background(), nil, "", nil)
+	if _, code := bot.Classify(err); code != bot.ErrCodeUnknownChannel {
+		t.Errorf("error code = %s, want %s", code, bot.ErrCodeUnknownChannel)
+	}
+}
+
+func TestTimeoutKeepsLateDiscordPermanentError(t *testing.T) {
+	var finished atomic.Bool
+	forbidden := discordtest.RESTError(http.StatusForbidden, discordgo.ErrCodeMissingPermissions, "Missing Permissions")
+	chain := handlers.NewChain(slowHandler(30*time.Millisecond, &finished, nil, forbidden), handlers.Timeout(10*time.Millisecond))
+
+	_, err := chain.Handle(context.Background(), nil, "", nil)
+	if kind, code := bot.Classify(err); kind != bot.ErrorKindPermanent || code != bot.ErrCodeMissingPermissions {
+		t.Errorf("Classify(err) = %s/%s, want PERMANENT/%s", kind, code, bot.ErrCodeMissingPermissions)
+	}
+}
+
+func TestTimeoutCancelsBlockedDiscordCall(t *testing.T) {
+	b, fake := newTestBot(t)
+	fake.Hang("ChannelMessageSend", true)
+	chain := handlers.NewChain(handlers.NewMessageHandler(), handlers.Timeout(20*time.Millisecond))
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := handle(t, b, chain, map[string]interface{}{"channel_id": testTextChannelID, "content": "hello"})
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if kind, code := bot.Classify(err); kind != bot.ErrorKindTransient || code != bot.ErrCodeTimeout {
+			t.Errorf("Classify(err) = %s/%s, want TRANSIENT/TIMEOUT (err: %v)", kind, code, err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the deadline did not cancel the Discord call")
+	}
+	if n := len(fake.Messages()); n != 0 {
+		t.Errorf("sent %d messages, want 0", n)
+	}
+}
+
+func TestTimeoutPassesCallerCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	chain := handlers.NewChain(funcHandler(func(ctx context.Context) (map[string]interface{}, error) {
+		cancel()
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}), handlers.Timeout(time.Minute))
+
+	_, err := chain.Handle(ctx, nil, "", nil)
+	if kind, _ := bot.Classify(err); kind != bot.ErrorKindCanceled {
+		t.Errorf("error kind = %s, want CANCELED", kind)
+	}
+}
+
+func TestRecoverInsideTimeoutTurnsPanicIntoPermanentError(t *testing.T) {
+	chain := handlers.NewChain(funcHandler(func(context.Context) (map[string]interface{}, error) {
+		panic("boom")
+	}), handlers.Timeout(time.Minute), handlers.Recover())
+
+	_, err := chain.Handle(context.Background(), nil, "", nil)
+	if kind, code := bot.Classify(err); kind != bot.ErrorKindPermanent || code != bot.ErrCodeHandlerPanic {
+		t.Errorf("Classify(err) = %s/%s, want PERMANENT/HANDLER_PANIC", kind, code)
+	}
+}
@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
 	"github.com/gamers-bot/internal/bot"
@@ -11,71 +10,39 @@ import (
 
 // ==================== Team Invite Handlers ====================
 
-// TeamInviteSentHandler handles team.invite.sent events
-type TeamInviteSentHandler struct{}
-
-// NewTeamInviteSentHandler creates a new TeamInviteSentHandler
-func NewTeamInviteSentHandler() *TeamInviteSentHandler {
-	return &TeamInviteSentHandler{}
+// NewTeamInviteSentHandler creates a handler for team.invite.sent events
+func NewTeamInviteSentHandler() *TypedHandler[models.TeamInviteSentPayload, models.TeamNotificationResult] {
+	return NewTypedHandler(teamInviteSent)
 }
 
-// Handle processes a team.invite.sent event - sends DM to invitee
-func (h *TeamInviteSentHandler) Handle(ctx context.Context, b *bot.DiscordBot, guildID string, payload map[string]interface{}) (map[string]interface{}, error) {
-	eventPayload, err := parseTeamInvitePayload(payload)
-	if err != nil {
-		return nil, err
-	}
-
-	// Validate required fields
-	if eventPayload.InviteeDiscordID == "" {
-		return nil, fmt.Errorf("invitee_discord_id is required")
-	}
-	if eventPayload.TeamName == "" {
-		return nil, fmt.Errorf("team_name is required")
-	}
-
+// teamInviteSent processes a team.invite.sent event - sends DM with Accept/Decline buttons to invitee
+func teamInviteSent(ctx context.Context, b *bot.DiscordBot, guildID string, eventPayload *models.TeamInviteSentPayload) (*models.TeamNotificationResult, error) {
 	// Build DM content
 	content := fmt.Sprintf(
 		"**[チーム招待]**\n\n"+
 			"**%s**さんから **%s** チームに招待されました。\n"+
-			"招待を確認して、参加するかどうかを決めてください。",
+			"下のボタンから参加するかどうかを決めてください。",
 		eventPayload.InviterUsername, eventPayload.TeamName,
 	)
 
-	// Send DM to invitee
-	result, err := b.SendDirectMessage(eventPayload.InviteeDiscordID, content)
-	if err != nil {
-		return nil, err
-	}
-
-	return marshalResult(result)
+	// Send DM with Accept/Decline buttons to invitee
+	return b.SendTeamInviteDirectMessage(ctx, eventPayload.InviteeDiscordID, content, bot.TeamInviteRef{
+		GameID:        eventPayload.GameID,
+		InviteeUserID: eventPayload.InviteeUserID,
+		InviterUserID: eventPayload.InviterUserID,
+	})
 }
 
-// TeamInviteAcceptedHandler handles team.invite.accepted events
-type TeamInviteAcceptedHandler struct{}
-
-// NewTeamInviteAcceptedHandler creates a new TeamInviteAcceptedHandler
-func NewTeamInviteAcceptedHandler() *TeamInviteAcceptedHandler {
-	return &TeamInviteAcceptedHandler{}
+// NewTeamInviteAcceptedHandler creates a handler for team.invite.accepted events
+func NewTeamInviteAcceptedHandler() *TypedHandler[models.TeamInviteAcceptedPayload, models.TeamNotificationResult] {
+	return NewTypedHandler(teamInviteAccepted)
 }
 
-// Handle processes a team.invite.accepted event - sends message to team channel
-func (h *TeamInviteAcceptedHandler) Handle(ctx context.Context, b *bot.DiscordBot, guildID string, payload map[string]interface{}) (map[string]interface{}, error) {
-	eventPayload, err := parseTeamInvitePayload(payload)
-	if err != nil {
-		return nil, err
-	}
-
-	// Validate required fields
-	if eventPayload.DiscordTextChannelID == "" {
-		return nil, fmt.Errorf("discord_text_channel_id is required")
-	}
-	if eventPayload.InviteeDiscordID == "" {
-		return nil, fmt.Errorf("invitee_discord_id is required")
-	}
-
+// teamInviteAccepted processes a team.invite.accepted event - sends message to team channel
+func teamInviteAccepted(ctx context.Context, b *bot.DiscordBot, guildID string, eventPayload *models.TeamInviteAcceptedPayload) (*models.TeamNotificationResult, error) {
 	// Send notification to team channel
-	result, err := b.SendTeamInviteNotification(
+	return b.SendTeamInviteNotification(
+		ctx,
 		eventPayload.DiscordTextChannelID,
 		eventPayload.InviterDiscordID,
 		eventPayload.InviterUsername,
@@ -84,33 +51,15 @@ func (h *TeamInviteAcceptedHandler) Handle(ctx context.Context, b *bot.DiscordBo
 		eventPayload.TeamName,
 		bot.TeamInviteAccepted,
 	)
-	if err != nil {
-		return nil, err
-	}
-
-	return marshalResult(result)
 }
 
-// TeamInviteRejectedHandler handles team.invite.rejected events
-type TeamInviteRejectedHandler struct{}
-
-// NewTeamInviteRejectedHandler creates a new TeamInviteRejectedHandler
-func NewTeamInviteRejectedHandler() *TeamInviteRejectedHandler {
-	return &TeamInviteRejectedHandler{}
+// NewTeamInviteRejectedHandler creates a handler for team.invite.rejected events
+func NewTeamInviteRejectedHandler() *TypedHandler[models.TeamInviteRejectedPayload, models.TeamNotificationResult] {
+	return NewTypedHandler(teamInviteRejected)
 }
 
-// Handle processes a team.invite.rejected event - sends DM to inviter (team leader)
-func (h *TeamInviteRejectedHandler) Handle(ctx context.Context, b *bot.DiscordBot, guildID string, payload map[string]interface{}) (map[string]interface{}, error) {
-	eventPayload, err := parseTeamInvitePayload(payload)
-	if err != nil {
-		return nil, err
-	}
-
-	// Validate required fields
-	if eventPayload.InviterDiscordID == "" {
-		return nil, fmt.Errorf("inviter_discord_id is required")
-	}
-
+// teamInviteRejected processes a team.invite.rejected event - sends DM to inviter (team leader)
+func teamInviteRejected(ctx context.Context, b *bot.DiscordBot, guildID string, eventPayload *models.TeamInviteRejectedPayload) (*models.TeamNotificationResult, error) {
 	// Build DM content for team leader
 	content := fmt.Sprintf(
 		"**[招待拒否]**\n\n"+
@@ -119,41 +68,21 @@ func (h *TeamInviteRejectedHandler) Handle(ctx context.Context, b *bot.DiscordBo
 	)
 
 	// Send DM to inviter (team leader)
-	result, err := b.SendDirectMessage(eventPayload.InviterDiscordID, content)
-	if err != nil {
-		return nil, err
-	}
-
-	return marshalResult(result)
+	return b.SendDirectMessage(ctx, eventPayload.InviterDiscordID, content)
 }
 
 // ==================== Team Member Handlers ====================
 
-// TeamMemberJoinedHandler handles team.member.joined events
-type TeamMemberJoinedHandler struct{}
-
-// NewTeamMemberJoinedHandler creates a new TeamMemberJoinedHandler
-func NewTeamMemberJoinedHandler() *TeamMemberJoinedHandler {
-	return &TeamMemberJoinedHandler{}
+// NewTeamMemberJoinedHandler creates a handler for team.member.joined events
+func NewTeamMemberJoinedHandler() *TypedHandler[models.TeamMemberJoinedPayload, models.TeamNotificationResult] {
+	return NewTypedHandler(teamMemberJoined)
 }
 
-// Handle processes a team.member.joined event - sends welcome message to team channel
-func (h *TeamMemberJoinedHandler) Handle(ctx context.Context, b *bot.DiscordBot, guildID string, payload map[string]interface{}) (map[string]interface{}, error) {
-	eventPayload, err := parseTeamMemberPayload(payload)
-	if err != nil {
-		return nil, err
-	}
-
-	// Validate required fields
-	if eventPayload.DiscordTextChannelID == "" {
-		return nil, fmt.Errorf("discord_text_channel_id is required")
-	}
-	if eventPayload.DiscordUserID == "" {
-		return nil, fmt.Errorf("discord_user_id is required")
-	}
-
+// teamMemberJoined processes a team.member.joined event - sends welcome message to team channel
+func teamMemberJoined(ctx context.Context, b *bot.DiscordBot, guildID string, eventPayload *models.TeamMemberJoinedPayload) (*models.TeamNotificationResult, error) {
 	// Send notification to team channel
-	result, err := b.SendTeamMemberNotification(
+	return b.SendTeamMemberNotification(
+		ctx,
 		eventPayload.DiscordTextChannelID,
 		eventPayload.DiscordUserID,
 		eventPayload.Username,
@@ -161,35 +90,18 @@ func (h *TeamMemberJoinedHandler) Handle(ctx context.Context, b *bot.DiscordBot,
 		eventPayload.MaxMembers,
 		bot.TeamMemberJoined,
 	)
-	if err != nil {
-		return nil, err
-	}
-
-	return marshalResult(result)
 }
 
-// TeamMemberLeftHandler handles team.member.left events
-type TeamMemberLeftHandler struct{}
-
-// NewTeamMemberLeftHandler creates a new TeamMemberLeftHandler
-func NewTeamMemberLeftHandler() *TeamMemberLeftHandler {
-	return &TeamMemberLeftHandler{}
+// NewTeamMemberLeftHandler creates a handler for team.member.left events
+func NewTeamMemberLeftHandler() *TypedHandler[models.TeamMemberLeftPayload, models.TeamNotificationResult] {
+	return NewTypedHandler(teamMemberLeft)
 }
 
-// Handle processes a team.member.left event - sends notification to team channel
-func (h *TeamMemberLeftHandler) Handle(ctx context.Context, b *bot.DiscordBot, guildID string, payload map[string]interface{}) (map[string]interface{}, error) {
-	eventPayload, err := parseTeamMemberPayload(payload)
-	if err != nil {
-		return nil, err
-	}
-
-	// Validate required fields
-	if eventPayload.DiscordTextChannelID == "" {
-		return nil, fmt.Errorf("discord_text_channel_id is required")
-	}
-
+// teamMemberLeft processes a team.member.left event - sends notification to team channel
+func teamMemberLeft(ctx context.Context, b *bot.DiscordBot, guildID string, eventPayload *models.TeamMemberLeftPayload) (*models.TeamNotificationResult, error) {
 	// Send notification to team channel
-	result, err := b.SendTeamMemberNotification(
+	return b.SendTeamMemberNotification(
+		ctx,
 		eventPayload.DiscordTextChannelID,
 		eventPayload.DiscordUserID,
 		eventPayload.Username,
@@ -197,215 +109,73 @@ func (h *TeamMemberLeftHandler) Handle(ctx context.Context, b *bot.DiscordBot, g
 		eventPayload.MaxMembers,
 		bot.TeamMemberLeft,
 	)
-	if err != nil {
-		return nil, err
-	}
-
-	return marshalResult(result)
 }
 
-// TeamMemberKickedHandler handles team.member.kicked events
-type TeamMemberKickedHandler struct{}
-
-// NewTeamMemberKickedHandler creates a new TeamMemberKickedHandler
-func NewTeamMemberKickedHandler() *TeamMemberKickedHandler {
-	return &TeamMemberKickedHandler{}
+// NewTeamMemberKickedHandler creates a handler for team.member.kicked events
+func NewTeamMemberKickedHandler() *TypedHandler[models.TeamMemberKickedPayload, models.TeamNotificationResult] {
+	return NewTypedHandler(teamMemberKicked)
 }
 
-// Handle processes a team.member.kicked event - sends DM to kicked user
-func (h *TeamMemberKickedHandler) Handle(ctx context.Context, b *bot.DiscordBot, guildID string, payload map[string]interface{}) (map[string]interface{}, error) {
-	eventPayload, err := parseTeamMemberPayload(payload)
-	if err != nil {
-		return nil, err
-	}
-
-	// Validate required fields
-	if eventPayload.DiscordUserID == "" {
-		return nil, fmt.Errorf("discord_user_id is required")
-	}
-
+// teamMemberKicked processes a team.member.kicked event - sends DM to kicked user
+func teamMemberKicked(ctx context.Context, b *bot.DiscordBot, guildID string, eventPayload *models.TeamMemberKickedPayload) (*models.TeamNotificationResult, error) {
 	// Build DM content for kicked user
 	content := "**[チーム強制退出]**\n\n" +
 		"チームから退出されました。\n" +
 		"詳しい内容はチームリーダーにお問い合わせください。"
 
 	// Send DM to kicked user
-	result, err := b.SendDirectMessage(eventPayload.DiscordUserID, content)
-	if err != nil {
-		return nil, err
-	}
-
-	return marshalResult(result)
+	return b.SendDirectMessage(ctx, eventPayload.DiscordUserID, content)
 }
 
 // ==================== Team Status Handlers ====================
 
-// TeamLeadershipTransferredHandler handles team.leadership.transferred events
-type TeamLeadershipTransferredHandler struct{}
-
-// NewTeamLeadershipTransferredHandler creates a new TeamLeadershipTransferredHandler
-func NewTeamLeadershipTransferredHandler() *TeamLeadershipTransferredHandler {
-	return &TeamLeadershipTransferredHandler{}
+// NewTeamLeadershipTransferredHandler creates a handler for team.leadership.transferred events
+func NewTeamLeadershipTransferredHandler() *TypedHandler[models.TeamLeaderEventPayload, models.TeamNotificationResult] {
+	return NewTypedHandler(teamLeadershipTransferred)
 }
 
-// Handle processes a team.leadership.transferred event - sends notification to team channel
-func (h *TeamLeadershipTransferredHandler) Handle(ctx context.Context, b *bot.DiscordBot, guildID string, payload map[string]interface{}) (map[string]interface{}, error) {
-	eventPayload, err := parseTeamFinalizedPayload(payload)
-	if err != nil {
-		return nil, err
-	}
-
-	// Validate required fields
-	if eventPayload.DiscordTextChannelID == "" {
-		return nil, fmt.Errorf("discord_text_channel_id is required")
-	}
-	if eventPayload.LeaderDiscordID == "" {
-		return nil, fmt.Errorf("leader_discord_id is required")
-	}
-
+// teamLeadershipTransferred processes a team.leadership.transferred event - sends notification to team channel
+func teamLeadershipTransferred(ctx context.Context, b *bot.DiscordBot, guildID string, eventPayload *models.TeamLeaderEventPayload) (*models.TeamNotificationResult, error) {
 	// Send notification to team channel
-	result, err := b.SendTeamStatusNotification(
+	return b.SendTeamStatusNotification(
+		ctx,
 		eventPayload.DiscordTextChannelID,
 		eventPayload.LeaderDiscordID,
 		eventPayload.MemberCount,
 		bot.TeamLeadershipTransferred,
 	)
-	if err != nil {
-		return nil, err
-	}
-
-	return marshalResult(result)
 }
 
-// TeamFinalizedHandler handles team.finalized events
-type TeamFinalizedHandler struct{}
-
-// NewTeamFinalizedHandler creates a new TeamFinalizedHandler
-func NewTeamFinalizedHandler() *TeamFinalizedHandler {
-	return &TeamFinalizedHandler{}
+// NewTeamFinalizedHandler creates a handler for team.finalized events
+func NewTeamFinalizedHandler() *TypedHandler[models.TeamLeaderEventPayload, models.TeamNotificationResult] {
+	return NewTypedHandler(teamFinalized)
 }
 
-// Handle processes a team.finalized event - sends notification to team channel
-func (h *TeamFinalizedHandler) Handle(ctx context.Context, b *bot.DiscordBot, guildID string, payload map[string]interface{}) (map[string]interface{}, error) {
-	eventPayload, err := parseTeamFinalizedPayload(payload)
-	if err != nil {
-		return nil, err
-	}
-
-	// Validate required fields
-	if eventPayload.DiscordTextChannelID == "" {
-		return nil, fmt.Errorf("discord_text_channel_id is required")
-	}
-	if eventPayload.LeaderDiscordID == "" {
-		return nil, fmt.Errorf("leader_discord_id is required")
-	}
-
+// teamFinalized processes a team.finalized event - sends notification to team channel
+func teamFinalized(ctx context.Context, b *bot.DiscordBot, guildID string, eventPayload *models.TeamLeaderEventPayload) (*models.TeamNotificationResult, error) {
 	// Send notification to team channel
-	result, err := b.SendTeamStatusNotification(
+	return b.SendTeamStatusNotification(
+		ctx,
 		eventPayload.DiscordTextChannelID,
 		eventPayload.LeaderDiscordID,
 		eventPayload.MemberCount,
 		bot.TeamFinalized,
 	)
-	if err != nil {
-		return nil, err
-	}
-
-	return marshalResult(result)
 }
 
-// TeamDeletedHandler handles team.deleted events
-type TeamDeletedHandler struct{}
-
-// NewTeamDeletedHandler creates a new TeamDeletedHandler
-func NewTeamDeletedHandler() *TeamDeletedHandler {
-	return &TeamDeletedHandler{}
+// NewTeamDeletedHandler creates a handler for team.deleted events
+func NewTeamDeletedHandler() *TypedHandler[models.TeamDeletedPayload, models.TeamNotificationResult] {
+	return NewTypedHandler(teamDeleted)
 }
 
-// Handle processes a team.deleted event - sends notification to team channel
-func (h *TeamDeletedHandler) Handle(ctx context.Context, b *bot.DiscordBot, guildID string, payload map[string]interface{}) (map[string]interface{}, error) {
-	eventPayload, err := parseTeamFinalizedPayload(payload)
-	if err != nil {
-		return nil, err
-	}
-
-	// Validate required fields
-	if eventPayload.DiscordTextChannelID == "" {
-		return nil, fmt.Errorf("discord_text_channel_id is required")
-	}
-
+// teamDeleted processes a team.deleted event - sends notification to team channel
+func teamDeleted(ctx context.Context, b *bot.DiscordBot, guildID string, eventPayload *models.TeamDeletedPayload) (*models.TeamNotificationResult, error) {
 	// Send notification to team channel
-	result, err := b.SendTeamStatusNotification(
+	return b.SendTeamStatusNotification(
+		ctx,
 		eventPayload.DiscordTextChannelID,
 		eventPayload.LeaderDiscordID,
 		eventPayload.MemberCount,
 		bot.TeamDeleted,
 	)
-	if err != nil {
-		return nil, err
-	}
-
-	return marshalResult(result)
-}
-
-// ==================== Helper Functions ====================
-
-// parseTeamInvitePayload parses the payload into TeamInviteEventPayload
-func parseTeamInvitePayload(payload map[string]interface{}) (*models.TeamInviteEventPayload, error) {
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal payload: %w", err)
-	}
-
-	var eventPayload models.TeamInviteEventPayload
-	if err := json.Unmarshal(payloadBytes, &eventPayload); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal payload: %w", err)
-	}
-
-	return &eventPayload, nil
-}
-
-// parseTeamMemberPayload parses the payload into TeamMemberEventPayload
-func parseTeamMemberPayload(payload map[string]interface{}) (*models.TeamMemberEventPayload, error) {
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal payload: %w", err)
-	}
-
-	var eventPayload models.TeamMemberEventPayload
-	if err := json.Unmarshal(payloadBytes, &eventPayload); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal payload: %w", err)
-	}
-
-	return &eventPayload, nil
-}
-
-// parseTeamFinalizedPayload parses the payload into TeamFinalizedEventPayload
-func parseTeamFinalizedPayload(payload map[string]interface{}) (*models.TeamFinalizedEventPayload, error) {
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal payload: %w", err)
-	}
-
-	var eventPayload models.TeamFinalizedEventPayload
-	if err := json.Unmarshal(payloadBytes, &eventPayload); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal payload: %w", err)
-	}
-
-	return &eventPayload, nil
-}
-
-// marshalResult converts a result struct to map[string]interface{}
-func marshalResult(result interface{}) (map[string]interface{}, error) {
-	resultBytes, err := json.Marshal(result)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal result: %w", err)
-	}
-
-	var resultMap map[string]interface{}
-	if err := json.Unmarshal(resultBytes, &resultMap); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal result: %w", err)
-	}
-
-	return resultMap, nil
 }
@@ -0,0 +1,234 @@
+package handlers_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/gamers-bot/internal/bot"
+	"github.com/gamers-bot/internal/handlers"
+)
+
+const (
+	testInviterDiscordID = "800000000000000001"
+	testInviteeDiscordID = "800000000000000002"
+)
+
+// teamInvite returns a team invite payload from Alice (user 1) to Bob (user 2) for game 42
+func teamInvite(eventType string) map[string]interface{} {
+	return map[string]interface{}{
+		"event_id":                "evt-" + eventType,
+		"event_type":              eventType,
+		"game_id":                 42,
+		"inviter_user_id":         1,
+		"inviter_discord_id":      testInviterDiscordID,
+		"inviter_username":        "Alice",
+		"invitee_user_id":         2,
+		"invitee_discord_id":      testInviteeDiscordID,
+		"invitee_username":        "Bob",
+		"discord_guild_id":        testGuildID,
+		"discord_text_channel_id": testTextChannelID,
+		"team_name":               "Red",
+	}
+}
+
+// teamMember returns a team member payload for Bob (user 2) in a team of 3 out of 5
+func teamMember(eventType string) map[string]interface{} {
+	return map[string]interface{}{
+		"event_type":              eventType,
+		"game_id":                 42,
+		"user_id":                 2,
+		"discord_user_id":         testInviteeDiscordID,
+		"username":                "Bob",
+		"discord_text_channel_id": testTextChannelID,
+		"current_member_count":    3,
+		"max_members":             5,
+	}
+}
+
+// teamStatus returns a team status payload for a team of 5 led by Alice (user 1)
+func teamStatus(eventType string) map[string]interface{} {
+	return map[string]interface{}{
+		"event_type":              eventType,
+		"game_id":                 42,
+		"leader_user_id":          1,
+		"leader_discord_id":       testInviterDiscordID,
+		"discord_text_channel_id": testTextChannelID,
+		"member_count":            5,
+	}
+}
+
+func TestTeamInviteSentSendsDirectMessageWithButtons(t *testing.T) {
+	b, fake := newTestBot(t)
+
+	if _, err := handle(t, b, handlers.NewTeamInviteSentHandler(), teamInvite("team.invite.sent")); err != nil {
+		t.Fatalf("team.invite.sent: %v", err)
+	}
+
+	dms := fake.DirectMessages()
+	if len(dms) != 1 || dms[0].UserID != testInviteeDiscordID {
+		t.Fatalf("direct messages = %+v, want one to the invitee", dms)
+	}
+	if !strings.Contains(dms[0].Content, "**Alice**さんから **Red** チームに招待されました") {
+		t.Errorf("content = %q", dms[0].Content)
+	}
+
+	got := buttons(dms[0].Components)
+	if len(got) != 2 {
+		t.Fatalf("got %d buttons, want accept and reject", len(got))
+	}
+	if got[0].Style != discordgo.SuccessButton || got[0].CustomID != "team_invite:accept:42:2:1" {
+		t.Errorf("accept button = %+v", got[0])
+	}
+	if got[1].Style != discordgo.DangerButton || got[1].CustomID != "team_invite:reject:42:2:1" {
+		t.Errorf("reject button = %+v", got[1])
+	}
+}
+
+func TestTeamInviteNotifications(t *testing.T) {
+	tests := []struct {
+		name      string
+		handler   handlers.Handler
+		eventType string
+		dmTo      string // recipient of a direct message; empty for the team channel
+		want      string
+	}{
+		{"accepted", handlers.NewTeamInviteAcceptedHandler(), "team.invite.accepted", "", "<@" + testInviteeDiscordID + ">様が **Red** チームへの招待を承諾しました"},
+		{"rejected", handlers.NewTeamInviteRejectedHandler(), "team.invite.rejected", testInviterDiscordID, "**Bob**さんが **Red** チームへの招待を拒否しました"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, fake := newTestBot(t)
+
+			if _, err := handle(t, b, tt.handler, teamInvite(tt.eventType)); err != nil {
+				t.Fatalf("%s: %v", tt.eventType, err)
+			}
+
+			var message string
+			if tt.dmTo != "" {
+				dms := fake.DirectMessages()
+				if len(dms) != 1 || dms[0].UserID != tt.dmTo {
+					t.Fatalf("direct messages = %+v, want one to %s", dms, tt.dmTo)
+				}
+				message = dms[0].Content
+			} else {
+				m := onlyMessage(t, fake)
+				if m.ChannelID != testTextChannelID {
+					t.Errorf("channel = %s, want the team channel", m.ChannelID)
+				}
+				message = m.Content
+			}
+			if !strings.Contains(message, tt.want) {
+				t.Errorf("content %q does not contain %q", message, tt.want)
+			}
+		})
+	}
+}
+
+func TestTeamMemberNotifications(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler handlers.Handler
+		payload map[string]interface{}
+		dm      bool
+		want    []string
+	}{
+		{"joined", handlers.NewTeamMemberJoinedHandler(), teamMember("team.member.joined"), false, []string{"[メンバー加入]", "<@" + testInviteeDiscordID + ">様がチームに参加しました", "3/5"}},
+		{"left", handlers.NewTeamMemberLeftHandler(), teamMember("team.member.left"), false, []string{"[メンバー脱退]", "**Bob**さんがチームから脱退しました", "3/5"}},
+		{"kicked", handlers.NewTeamMemberKickedHandler(), teamMember("team.member.kicked"), true, []string{"[チーム強制退出]"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, fake := newTestBot(t)
+
+			if _, err := handle(t, b, tt.handler, tt.payload); err != nil {
+				t.Fatalf("Handle: %v", err)
+			}
+
+			var content string
+			if tt.dm {
+				dms := fake.DirectMessages()
+				if len(dms) != 1 || dms[0].UserID != testInviteeDiscordID {
+					t.Fatalf("direct messages = %+v, want one to the member", dms)
+				}
+				content = dms[0].Content
+			} else {
+				content = onlyMessage(t, fake).Content
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(content, want) {
+					t.Errorf("content %q does not contain %q", content, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTeamStatusNotifications(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler handlers.Handler
+		want    []string
+	}{
+		{"team.leadership.transferred", handlers.NewTeamLeadershipTransferredHandler(), []string{"[リーダー変更]", "<@" + testInviterDiscordID + ">様がチームの新しいリーダーになりました"}},
+		{"team.finalized", handlers.NewTeamFinalizedHandler(), []string{"[チーム確定]", "チームリーダー: <@" + testInviterDiscordID + ">", "メンバー数: 5人"}},
+		{"team.deleted", handlers.NewTeamDeletedHandler(), []string{"[チーム解散]"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, fake := newTestBot(t)
+
+			if _, err := handle(t, b, tt.handler, teamStatus(tt.name)); err != nil {
+				t.Fatalf("Handle: %v", err)
+			}
+
+			content := onlyMessage(t, fake).Content
+			for _, want := range tt.want {
+				if !strings.Contains(content, want) {
+					t.Errorf("content %q does not contain %q", content, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTeamHandlersRequireRecipients(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler handlers.Handler
+		payload map[string]interface{}
+		omit    string
+	}{
+		{"invite sent without invitee", handlers.NewTeamInviteSentHandler(), teamInvite("team.invite.sent"), "invitee_discord_id"},
+		{"invite sent without team name", handlers.NewTeamInviteSentHandler(), teamInvite("team.invite.sent"), "team_name"},
+		{"invite accepted without channel", handlers.NewTeamInviteAcceptedHandler(), teamInvite("team.invite.accepted"), "discord_text_channel_id"},
+		{"invite accepted without invitee", handlers.NewTeamInviteAcceptedHandler(), teamInvite("team.invite.accepted"), "invitee_discord_id"},
+		{"invite rejected without inviter", handlers.NewTeamInviteRejectedHandler(), teamInvite("team.invite.rejected"), "inviter_discord_id"},
+		{"member joined without channel", handlers.NewTeamMemberJoinedHandler(), teamMember("team.member.joined"), "discord_text_channel_id"},
+		{"member joined without member", handlers.NewTeamMemberJoinedHandler(), teamMember("team.member.joined"), "discord_user_id"},
+		{"member left without channel", handlers.NewTeamMemberLeftHandler(), teamMember("team.member.left"), "discord_text_channel_id"},
+		{"member kicked without member", handlers.NewTeamMemberKickedHandler(), teamMember("team.member.kicked"), "discord_user_id"},
+		{"leadership transferred without channel", handlers.NewTeamLeadershipTransferredHandler(), teamStatus("team.leadership.transferred"), "discord_text_channel_id"},
+		{"leadership transferred without leader", handlers.NewTeamLeadershipTransferredHandler(), teamStatus("team.leadership.transferred"), "leader_discord_id"},
+		{"finalized without channel", handlers.NewTeamFinalizedHandler(), teamStatus("team.finalized"), "discord_text_channel_id"},
+		{"finalized without leader", handlers.NewTeamFinalizedHandler(), teamStatus("team.finalized"), "leader_discord_id"},
+		{"deleted without channel", handlers.NewTeamDeletedHandler(), teamStatus("team.deleted"), "discord_text_channel_id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, fake := newTestBot(t)
+			delete(tt.payload, tt.omit)
+
+			_, err := handle(t, b, tt.handler, tt.payload)
+			if kind, code := bot.Classify(err); err == nil || kind != bot.ErrorKindPermanent || code != bot.ErrCodeValidation {
+				t.Errorf("error = %v (%s, %s), want a permanent %s error", err, kind, code, bot.ErrCodeValidation)
+			}
+			if err != nil && !strings.Contains(err.Error(), tt.omit+" is required") {
+				t.Errorf("error = %v, want it to name %s", err, tt.omit)
+			}
+			if n := len(fake.Messages()) + len(fake.DirectMessages()); n != 0 {
+				t.Errorf("sent %d messages, want 0", n)
+			}
+		})
+	}
+}
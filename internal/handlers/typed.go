@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/gamers-bot/internal/bot"
+)
+
+// Validator is implemented by payload models that check their own required fields.
+// Errors that are not already classified are reported as validation errors.
+type Validator interface {
+	Validate() error
+}
+
+// RawHandler is a Handler that decodes the JSON payload itself. The consumer passes the
+// delivery body to HandleRaw when it has it, skipping the round-trip through a map.
+type RawHandler interface {
+	Handler
+	HandleRaw(ctx context.Context, b *bot.DiscordBot, guildID string, payload json.RawMessage) (map[string]interface{}, error)
+}
+
+// TypedFunc handles a payload decoded into In and returns the result sent back as response data
+type TypedFunc[In, Out any] func(ctx context.Context, b *bot.DiscordBot, guildID string, payload *In) (*Out, error)
+
+// TypedHandler adapts a TypedFunc to Handler and RawHandler. It decodes the payload into In,
+// runs In's Validate method if it has one, calls the function and encodes its result.
+type TypedHandler[In, Out any] struct {
+	handle TypedFunc[In, Out]
+}
+
+// NewTypedHandler creates a TypedHandler calling handle
+func NewTypedHandler[In, Out any](handle TypedFunc[In, Out]) *TypedHandler[In, Out] {
+	return &TypedHandler[In, Out]{handle: handle}
+}
+
+// Handle implements Handler for callers that only have the decoded payload map
+func (h *TypedHandler[In, Out]) Handle(ctx context.Context, b *bot.DiscordBot, guildID string, payload map[string]interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	return h.HandleRaw(ctx, b, guildID, data)
+}
+
+// HandleRaw implements RawHandler
+func (h *TypedHandler[In, Out]) HandleRaw(ctx context.Context, b *bot.DiscordBot, guildID string, payload json.RawMessage) (map[string]interface{}, error) {
+	in, err := decodePayload[In](payload)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := h.handle(ctx, b, guildID, in)
+	if err != nil {
+		return nil, err
+	}
+	if out == nil {
+		return nil, nil
+	}
+	return marshalResult(out)
+}
+
+// decodePayload decodes a JSON payload into In and validates it
+func decodePayload[In any](payload json.RawMessage) (*In, error) {
+	in := new(In)
+	if len(bytes.TrimSpace(payload)) > 0 {
+		if err := json.Unmarshal(payload, in); err != nil {
+			return nil, bot.NewInvalidPayloadError("failed to unmarshal payload: %w", err)
+		}
+	}
+
+	if v, ok := any(in).(Validator); ok {
+		if err := v.Validate(); err != nil {
+			var classified *bot.Error
+			if errors.As(err, &classified) {
+				return nil, err
+			}
+			return nil, bot.NewError(bot.ErrorKindPermanent, bot.ErrCodeValidation, err)
+		}
+	}
+	return in, nil
+}
+
+// marshalResult converts a result struct to map[string]interface{}
+func marshalResult(result interface{}) (map[string]interface{}, error) {
+	resultBytes, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+
+	var resultMap map[string]interface{}
+	if err := json.Unmarshal(resultBytes, &resultMap); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal result: %w", err)
+	}
+
+	return resultMap, nil
+}
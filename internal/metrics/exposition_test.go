@@ -0,0 +1,117 @@
+package metrics
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// writeText returns the exposition of r
+func writeText(t *testing.T, r *Registry) string {
+	t.Helper()
+	var b strings.Builder
+	if err := r.WriteText(&b); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+	return b.String()
+}
+
+func TestWriteTextCountersAndGauges(t *testing.T) {
+	r := NewRegistry()
+	calls := r.NewCounterVec("calls_total", "Calls by route and status.", "route", "status")
+	calls.Inc("/b", "200")
+	calls.Add(2, "/a", "500")
+	calls.Inc("/b", "200")
+	calls.Add(-1, "/b", "200") // counters never go down
+	r.NewCounterVec("restarts_total", "Restarts.")
+	connected := r.NewGaugeVec("connected", "Whether the connection is up.")
+	connected.Set(1)
+	r.NewGaugeFunc("guilds", "Guilds the bot is in.", func() float64 { return 3 })
+	r.NewGaugeVec("latency_seconds", "Gateway latency.").Set(math.Inf(1))
+
+	want := `# HELP calls_total Calls by route and status.
+# TYPE calls_total counter
+calls_total{route="/a",status="500"} 2
+calls_total{route="/b",status="200"} 2
+# HELP restarts_total Restarts.
+# TYPE restarts_total counter
+restarts_total 0
+# HELP connected Whether the connection is up.
+# TYPE connected gauge
+connected 1
+# HELP guilds Guilds the bot is in.
+# TYPE guilds gauge
+guilds 3
+# HELP latency_seconds Gateway latency.
+# TYPE latency_seconds gauge
+latency_seconds +Inf
+`
+	if got := writeText(t, r); got != want {
+		t.Errorf("WriteText =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteTextEscapesHelpAndLabelValues(t *testing.T) {
+	r := NewRegistry()
+	failures := r.NewCounterVec("errors_total", "Errors by message.\nSee C:\\logs.", "message", "code")
+	failures.Inc("say \"hi\"\nC:\\bot")
+	failures.Inc("extra", "404", "ignored")
+
+	want := `# HELP errors_total Errors by message.\nSee C:\\logs.
+# TYPE errors_total counter
+errors_total{message="extra",code="404"} 1
+errors_total{message="say \"hi\"\nC:\\bot",code=""} 1
+`
+	if got := writeText(t, r); got != want {
+		t.Errorf("WriteText =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteTextHistogram(t *testing.T) {
+	r := NewRegistry()
+	duration := r.NewHistogramVec("duration_seconds", "Handler latency.", []float64{1, 0.125, 0.5}, "event_type")
+	for _, v := range []float64{0.0625, 0.25, 2, math.NaN()} {
+		duration.Observe(v, "game.finished")
+	}
+	duration.Observe(0.125, "team.deleted")
+
+	// Buckets are sorted and cumulative; NaN is not recorded and a value on a bound falls in its bucket
+	want := `# HELP duration_seconds Handler latency.
+# TYPE duration_seconds histogram
+duration_seconds_bucket{event_type="game.finished",le="0.125"} 1
+duration_seconds_bucket{event_type="game.finished",le="0.5"} 2
+duration_seconds_bucket{event_type="game.finished",le="1"} 2
+duration_seconds_bucket{event_type="game.finished",le="+Inf"} 3
+duration_seconds_sum{event_type="game.finished"} 2.3125
+duration_seconds_count{event_type="game.finished"} 3
+duration_seconds_bucket{event_type="team.deleted",le="0.125"} 1
+duration_seconds_bucket{event_type="team.deleted",le="0.5"} 1
+duration_seconds_bucket{event_type="team.deleted",le="1"} 1
+duration_seconds_bucket{event_type="team.deleted",le="+Inf"} 1
+duration_seconds_sum{event_type="team.deleted"} 0.125
+duration_seconds_count{event_type="team.deleted"} 1
+`
+	if got := writeText(t, r); got != want {
+		t.Errorf("WriteText =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestHandlerServesTextFormat(t *testing.T) {
+	m := New()
+	m.SetRabbitMQConnected(true)
+
+	rec := httptest.NewRecorder()
+	m.Registry.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Type"); got != contentType {
+		t.Errorf("Content-Type = %q, want %q", got, contentType)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, "\ngamers_bot_rabbitmq_connected 1\n") {
+		t.Errorf("body does not report the RabbitMQ connection:\n%s", body)
+	}
+}
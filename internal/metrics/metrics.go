@@ -0,0 +1,137 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+)
+
+// Metrics are the metrics recorded by the bot. All methods are safe to call on a nil *Metrics,
+// which records nothing, so components work without metrics configured.
+type Metrics struct {
+	Registry *Registry
+
+	handlerCalls    *CounterVec
+	handlerDuration *HistogramVec
+
+	messagesConsumed *CounterVec
+	messagesAcked    *CounterVec
+	messagesNacked   *CounterVec
+
+	discordRequests   *CounterVec
+	discordErrors     *CounterVec
+	discordRateLimits *CounterVec
+
+	rabbitMQConnected  *GaugeVec
+	rabbitMQReconnects *CounterVec
+}
+
+// New creates the bot metrics in a new registry
+func New() *Metrics {
+	r := NewRegistry()
+	return &Metrics{
+		Registry: r,
+
+		handlerCalls:    r.NewCounterVec("gamers_bot_handler_calls_total", "Handler calls by event type and outcome.", "event_type", "outcome"),
+		handlerDuration: r.NewHistogramVec("gamers_bot_handler_duration_seconds", "Handler latency by event type and outcome.", DefaultBuckets, "event_type", "outcome"),
+
+		messagesConsumed: r.NewCounterVec("gamers_bot_messages_consumed_total", "Deliveries received by queue and event type.", "queue", "event_type"),
+		messagesAcked:    r.NewCounterVec("gamers_bot_messages_acked_total", "Deliveries acked by queue and event type.", "queue", "event_type"),
+		messagesNacked:   r.NewCounterVec("gamers_bot_messages_nacked_total", "Deliveries nacked by queue, event type and whether they were requeued.", "queue", "event_type", "requeue"),
+
+		discordRequests:   r.NewCounterVec("gamers_bot_discord_api_requests_total", "Discord REST API requests by method, route and HTTP status.", "method", "route", "status"),
+		discordErrors:     r.NewCounterVec("gamers_bot_discord_api_errors_total", "Failed Discord REST API requests by route and Discord error code (HTTP status if there is none).", "route", "code"),
+		discordRateLimits: r.NewCounterVec("gamers_bot_discord_rate_limits_total", "Discord REST API requests that hit a rate limit."),
+
+		rabbitMQConnected:  r.NewGaugeVec("gamers_bot_rabbitmq_connected", "Whether the RabbitMQ connection is up (1) or not (0)."),
+		rabbitMQReconnects: r.NewCounterVec("gamers_bot_rabbitmq_reconnects_total", "RabbitMQ connections re-established after a connection loss."),
+	}
+}
+
+// ObserveHandler records a handler call; it implements handlers.MetricsRecorder
+func (m *Metrics) ObserveHandler(eventType, outcome string, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.handlerCalls.Inc(eventType, outcome)
+	m.handlerDuration.Observe(duration.Seconds(), eventType, outcome)
+}
+
+// MessageConsumed records a delivery received from a queue
+func (m *Metrics) MessageConsumed(queue, eventType string) {
+	if m == nil {
+		return
+	}
+	m.messagesConsumed.Inc(queue, eventType)
+}
+
+// MessageAcked records an acked delivery
+func (m *Metrics) MessageAcked(queue, eventType string) {
+	if m == nil {
+		return
+	}
+	m.messagesAcked.Inc(queue, eventType)
+}
+
+// MessageNacked records a nacked delivery
+func (m *Metrics) MessageNacked(queue, eventType string, requeue bool) {
+	if m == nil {
+		return
+	}
+	m.messagesNacked.Inc(queue, eventType, strconv.FormatBool(requeue))
+}
+
+// DiscordRequest records a Discord REST API request. code is the Discord error code of a
+// failed request, or empty.
+func (m *Metrics) DiscordRequest(method, route string, status int, code string) {
+	if m == nil {
+		return
+	}
+	m.discordRequests.Inc(method, route, strconv.Itoa(status))
+	if status >= 400 {
+		if code == "" {
+			code = strconv.Itoa(status)
+		}
+		m.discordErrors.Inc(route, code)
+	}
+}
+
+// DiscordRateLimited records a rate-limited Discord REST API request
+func (m *Metrics) DiscordRateLimited() {
+	if m == nil {
+		return
+	}
+	m.discordRateLimits.Inc()
+}
+
+// SetRabbitMQConnected records whether the RabbitMQ connection is up
+func (m *Metrics) SetRabbitMQConnected(connected bool) {
+	if m == nil {
+		return
+	}
+	value := 0.0
+	if connected {
+		value = 1
+	}
+	m.rabbitMQConnected.Set(value)
+}
+
+// RabbitMQReconnected records a connection re-established after a connection loss
+func (m *Metrics) RabbitMQReconnected() {
+	if m == nil {
+		return
+	}
+	m.rabbitMQReconnects.Inc()
+}
+
+// CollectGateway registers the Discord gateway latency and guild count, read at scrape time
+func (m *Metrics) CollectGateway(latency func() time.Duration, guilds func() int) {
+	if m == nil {
+		return
+	}
+	m.Registry.NewGaugeFunc("gamers_bot_discord_gateway_latency_seconds", "Latency of the last Discord gateway heartbeat.", func() float64 {
+		return latency().Seconds()
+	})
+	m.Registry.NewGaugeFunc("gamers_bot_discord_guilds", "Guilds the bot is connected to.", func() float64 {
+		return float64(guilds())
+	})
+}
@@ -0,0 +1,165 @@
+package metrics
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Registry holds the metrics of the bot. Metrics are created once at startup and
+// updated concurrently from consumers, handlers and the Discord session.
+type Registry struct {
+	mu      sync.Mutex
+	metrics []*vec
+}
+
+// NewRegistry creates an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// metricType is the Prometheus type of a metric
+type metricType string
+
+const (
+	typeCounter   metricType = "counter"
+	typeGauge     metricType = "gauge"
+	typeHistogram metricType = "histogram"
+)
+
+// vec is a metric family: one series per combination of label values
+type vec struct {
+	name    string
+	help    string
+	kind    metricType
+	labels  []string
+	buckets []float64      // upper bounds, histograms only
+	collect func() float64 // read at scrape time, gauge funcs only
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+// series is the value of one label combination
+type series struct {
+	labelValues []string
+	value       float64  // counter or gauge value
+	counts      []uint64 // per-bucket counts, histograms only
+	count       uint64
+	sum         float64
+}
+
+// register adds a metric family to the registry
+func (r *Registry) register(name, help string, kind metricType, buckets []float64, labels []string) *vec {
+	v := &vec{
+		name:    name,
+		help:    help,
+		kind:    kind,
+		labels:  labels,
+		buckets: buckets,
+		series:  make(map[string]*series),
+	}
+	if len(labels) == 0 {
+		v.with(nil) // unlabelled metrics are exported as 0 before their first update
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics = append(r.metrics, v)
+	return v
+}
+
+// with returns the series for labelValues, creating it on first use. The caller must hold v.mu.
+// Missing label values are treated as empty and extra ones are ignored.
+func (v *vec) with(labelValues []string) *series {
+	values := make([]string, len(v.labels))
+	copy(values, labelValues)
+	key := strings.Join(values, "\xff")
+
+	s, ok := v.series[key]
+	if !ok {
+		s = &series{labelValues: values}
+		if v.kind == typeHistogram {
+			s.counts = make([]uint64, len(v.buckets))
+		}
+		v.series[key] = s
+	}
+	return s
+}
+
+// CounterVec is a counter partitioned by labels
+type CounterVec struct{ v *vec }
+
+// NewCounterVec registers a counter
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{v: r.register(name, help, typeCounter, nil, labels)}
+}
+
+// Inc adds 1 to the counter with the given label values
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds delta, which must not be negative, to the counter with the given label values
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		return
+	}
+	c.v.mu.Lock()
+	defer c.v.mu.Unlock()
+	c.v.with(labelValues).value += delta
+}
+
+// GaugeVec is a gauge partitioned by labels
+type GaugeVec struct{ v *vec }
+
+// NewGaugeVec registers a gauge
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	return &GaugeVec{v: r.register(name, help, typeGauge, nil, labels)}
+}
+
+// Set sets the gauge with the given label values
+func (g *GaugeVec) Set(value float64, labelValues ...string) {
+	g.v.mu.Lock()
+	defer g.v.mu.Unlock()
+	g.v.with(labelValues).value = value
+}
+
+// NewGaugeFunc registers an unlabelled gauge whose value is read from collect at scrape time
+func (r *Registry) NewGaugeFunc(name, help string, collect func() float64) {
+	v := r.register(name, help, typeGauge, nil, nil)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.collect = collect
+}
+
+// HistogramVec is a histogram partitioned by labels
+type HistogramVec struct{ v *vec }
+
+// DefaultBuckets are latency buckets in seconds, from 5ms to 30s
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// NewHistogramVec registers a histogram with the given bucket upper bounds
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	bounds := slices.Clone(buckets)
+	slices.Sort(bounds)
+	return &HistogramVec{v: r.register(name, help, typeHistogram, bounds, labels)}
+}
+
+// Observe records a value in the histogram with the given label values
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	if math.IsNaN(value) {
+		return
+	}
+	h.v.mu.Lock()
+	defer h.v.mu.Unlock()
+	s := h.v.with(labelValues)
+	for i, bound := range h.v.buckets {
+		if value <= bound {
+			s.counts[i]++
+		}
+	}
+	s.count++
+	s.sum += value
+}
@@ -1,5 +1,7 @@
 package models
 
+import "errors"
+
 // ChannelInfo represents basic information about a Discord channel
 type ChannelInfo struct {
 	ID   string `json:"id"`
@@ -12,6 +14,17 @@ type SendMessagePayload struct {
 	Content   string `json:"content"`
 }
 
+// Validate checks the required fields
+func (p *SendMessagePayload) Validate() error {
+	if p.ChannelID == "" {
+		return errors.New("channel_id is required")
+	}
+	if p.Content == "" {
+		return errors.New("content is required")
+	}
+	return nil
+}
+
 // SendMessageResult contains the result of sending a message
 type SendMessageResult struct {
 	MessageID string `json:"message_id"`
@@ -25,6 +38,17 @@ type MoveMembersPayload struct {
 	UserIDs       []string `json:"user_ids"` // Empty = move all users
 }
 
+// Validate checks the required fields
+func (p *MoveMembersPayload) Validate() error {
+	if p.FromChannelID == "" {
+		return errors.New("from_channel_id is required")
+	}
+	if p.ToChannelID == "" {
+		return errors.New("to_channel_id is required")
+	}
+	return nil
+}
+
 // MoveMembersResult contains the result of moving members
 type MoveMembersResult struct {
 	MovedCount  int      `json:"moved_count"`
@@ -44,6 +68,20 @@ type ContestInvitationPayload struct {
 	Message     string   `json:"message"`      // Optional custom message
 }
 
+// Validate checks the required fields
+func (p *ContestInvitationPayload) Validate() error {
+	if p.ChannelID == "" {
+		return errors.New("channel_id is required")
+	}
+	if len(p.UserIDs) == 0 {
+		return errors.New("user_ids cannot be empty")
+	}
+	if p.ContestName == "" {
+		return errors.New("contest_name is required")
+	}
+	return nil
+}
+
 // ContestInvitationResult contains the result of sending a contest invitation
 type ContestInvitationResult struct {
 	MessageID     string   `json:"message_id"`
@@ -62,6 +100,26 @@ type ContestApplicationEventPayload struct {
 	Data                 map[string]interface{} `json:"data"`
 }
 
+// Validate checks the required fields
+func (p *ContestApplicationEventPayload) Validate() error {
+	if p.DiscordTextChannelID == "" {
+		return errors.New("discord_text_channel_id is required")
+	}
+	if p.DiscordUserID == "" {
+		return errors.New("discord_user_id is required")
+	}
+	if p.ContestTitle() == "" {
+		return errors.New("contest_title is required in data")
+	}
+	return nil
+}
+
+// ContestTitle returns data.contest_title
+func (p *ContestApplicationEventPayload) ContestTitle() string {
+	title, _ := p.Data["contest_title"].(string)
+	return title
+}
+
 // ApplicationNotificationResult contains the result of sending an application notification
 type ApplicationNotificationResult struct {
 	MessageID string `json:"message_id"`
@@ -124,6 +182,123 @@ type TeamFinalizedEventPayload struct {
 	Data                 map[string]interface{} `json:"data"`
 }
 
+// TeamInviteSentPayload is a team.invite.sent event, sent to the invitee by DM
+type TeamInviteSentPayload struct {
+	TeamInviteEventPayload
+}
+
+// Validate checks the required fields
+func (p *TeamInviteSentPayload) Validate() error {
+	if p.InviteeDiscordID == "" {
+		return errors.New("invitee_discord_id is required")
+	}
+	if p.TeamName == "" {
+		return errors.New("team_name is required")
+	}
+	return nil
+}
+
+// TeamInviteAcceptedPayload is a team.invite.accepted event, announced in the team channel
+type TeamInviteAcceptedPayload struct {
+	TeamInviteEventPayload
+}
+
+// Validate checks the required fields
+func (p *TeamInviteAcceptedPayload) Validate() error {
+	if p.DiscordTextChannelID == "" {
+		return errors.New("discord_text_channel_id is required")
+	}
+	if p.InviteeDiscordID == "" {
+		return errors.New("invitee_discord_id is required")
+	}
+	return nil
+}
+
+// TeamInviteRejectedPayload is a team.invite.rejected event, sent to the inviter by DM
+type TeamInviteRejectedPayload struct {
+	TeamInviteEventPayload
+}
+
+// Validate checks the required fields
+func (p *TeamInviteRejectedPayload) Validate() error {
+	if p.InviterDiscordID == "" {
+		return errors.New("inviter_discord_id is required")
+	}
+	return nil
+}
+
+// TeamMemberJoinedPayload is a team.member.joined event, announced in the team channel
+type TeamMemberJoinedPayload struct {
+	TeamMemberEventPayload
+}
+
+// Validate checks the required fields
+func (p *TeamMemberJoinedPayload) Validate() error {
+	if p.DiscordTextChannelID == "" {
+		return errors.New("discord_text_channel_id is required")
+	}
+	if p.DiscordUserID == "" {
+		return errors.New("discord_user_id is required")
+	}
+	return nil
+}
+
+// TeamMemberLeftPayload is a team.member.left event, announced in the team channel
+type TeamMemberLeftPayload struct {
+	TeamMemberEventPayload
+}
+
+// Validate checks the required fields
+func (p *TeamMemberLeftPayload) Validate() error {
+	if p.DiscordTextChannelID == "" {
+		return errors.New("discord_text_channel_id is required")
+	}
+	return nil
+}
+
+// TeamMemberKickedPayload is a team.member.kicked event, sent to the kicked member by DM
+type TeamMemberKickedPayload struct {
+	TeamMemberEventPayload
+}
+
+// Validate checks the required fields
+func (p *TeamMemberKickedPayload) Validate() error {
+	if p.DiscordUserID == "" {
+		return errors.New("discord_user_id is required")
+	}
+	return nil
+}
+
+// TeamLeaderEventPayload is a team event naming the team leader, announced in the team channel
+// Used for: team.finalized, team.leadership.transferred
+type TeamLeaderEventPayload struct {
+	TeamFinalizedEventPayload
+}
+
+// Validate checks the required fields
+func (p *TeamLeaderEventPayload) Validate() error {
+	if p.DiscordTextChannelID == "" {
+		return errors.New("discord_text_channel_id is required")
+	}
+	if p.LeaderDiscordID == "" {
+		return errors.New("leader_discord_id is required")
+	}
+	return nil
+}
+
+// TeamDeletedPayload is a team.deleted event, announced in the team channel
+type TeamDeletedPayload struct {
+	TeamFinalizedEventPayload
+}
+
+// Validate checks the required fields
+func (p *TeamDeletedPayload) Validate() error {
+	if p.DiscordTextChannelID == "" {
+		return errors.New("discord_text_channel_id is required")
+	}
+	return nil
+}
+
 // TeamNotificationResult contains the result of sending a team notification
 type TeamNotificationResult struct {
 	MessageID string `json:"message_id"`
@@ -148,6 +323,25 @@ type GameEventPayload struct {
 	Data                 map[string]interface{} `json:"data"`
 }
 
+// Validate checks the required fields
+func (p *GameEventPayload) Validate() error {
+	if p.GameID == 0 {
+		return errors.New("game_id is required")
+	}
+	if p.DiscordTextChannelID == "" {
+		return errors.New("discord_text_channel_id is required")
+	}
+	return nil
+}
+
+// GameNotificationResult contains the result of posting or updating a game status message
+type GameNotificationResult struct {
+	MessageID string `json:"message_id"`
+	ChannelID string `json:"channel_id"`
+	Timestamp string `json:"timestamp"`
+	Edited    bool   `json:"edited"` // true if an existing status message was updated
+}
+
 // ContestTeamsReadyPayload represents the payload for game.contest.teams.ready events
 type ContestTeamsReadyPayload struct {
 	BaseEvent
@@ -168,3 +362,44 @@ type ContestCreatedEventPayload struct {
 	DiscordTextChannelID string                 `json:"discord_text_channel_id"`
 	Data                 map[string]interface{} `json:"data"`
 }
+
+// Validate checks the required fields
+func (p *ContestCreatedEventPayload) Validate() error {
+	if p.DiscordTextChannelID == "" {
+		return errors.New("discord_text_channel_id is required")
+	}
+	if p.ContestTitle == "" {
+		return errors.New("contest_title is required")
+	}
+	return nil
+}
+
+// ContestAnnouncementResult contains the result of posting a contest announcement
+type ContestAnnouncementResult struct {
+	MessageID        string `json:"message_id"`
+	ChannelID        string `json:"channel_id"`
+	Timestamp        string `json:"timestamp"`
+	Pinned           bool   `json:"pinned"`
+	ScheduledEventID string `json:"scheduled_event_id,omitempty"`
+}
+
+// TeamInviteCommandPayload is published when an invitee answers a team invite from Discord
+// Used for: team.invite.accept_requested, team.invite.reject_requested
+type TeamInviteCommandPayload struct {
+	BaseEvent
+	GameID           int64  `json:"game_id"`
+	InviteeUserID    int64  `json:"invitee_user_id"`
+	InviterUserID    int64  `json:"inviter_user_id"`
+	InviteeDiscordID string `json:"invitee_discord_id"`
+}
+
+// ApplicationCommandPayload is published when an organizer approves or rejects an application from Discord
+// Used for: application.approve_requested, application.reject_requested
+type ApplicationCommandPayload struct {
+	BaseEvent
+	ContestID            int64  `json:"contest_id"`
+	UserID               int64  `json:"user_id"`
+	DiscordUserID        string `json:"discord_user_id"`
+	ProcessedByDiscordID string `json:"processed_by_discord_id"`
+	Reason               string `json:"reason,omitempty"`
+}
@@ -0,0 +1,88 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Connection is the subset of an AMQP connection used by the bot. It is implemented by the
+// amqp091 client (see Dial) and by the in-memory broker in package rabbitmqtest.
+// Deliveries are plain amqp.Delivery values; their Acknowledger settles them with the broker.
+type Connection interface {
+	Channel() (Channel, error)
+	NotifyClose(receiver chan *amqp.Error) chan *amqp.Error
+	NotifyBlocked(receiver chan amqp.Blocking) chan amqp.Blocking
+	Close() error
+}
+
+// Channel is the subset of an AMQP channel used by the bot
+type Channel interface {
+	Qos(prefetchCount, prefetchSize int, global bool) error
+	Confirm(noWait bool) error
+
+	ExchangeDeclare(name, kind string, durable, autoDelete, internal, noWait bool, args amqp.Table) error
+	QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (amqp.Queue, error)
+	QueueDeclarePassive(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (amqp.Queue, error)
+	QueueBind(name, key, exchange string, noWait bool, args amqp.Table) error
+
+	Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error)
+	Cancel(consumer string, noWait bool) error
+
+	PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
+	// PublishWithDeferredConfirmWithContext publishes msg and returns its pending broker confirm.
+	// The channel must be in confirm mode.
+	PublishWithDeferredConfirmWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) (Confirmation, error)
+
+	NotifyClose(receiver chan *amqp.Error) chan *amqp.Error
+	NotifyReturn(receiver chan amqp.Return) chan amqp.Return
+	IsClosed() bool
+	Close() error
+}
+
+// Confirmation is the pending broker confirm of a publish
+type Confirmation interface {
+	// WaitContext blocks until the broker confirms the publish and reports whether it was acked
+	WaitContext(ctx context.Context) (bool, error)
+}
+
+// Dial connects to a RabbitMQ broker
+func Dial(url string) (Connection, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return nil, err
+	}
+	return amqpConnection{conn}, nil
+}
+
+// amqpConnection adapts an amqp091 connection to Connection
+type amqpConnection struct {
+	*amqp.Connection
+}
+
+// Channel implements Connection
+func (c amqpConnection) Channel() (Channel, error) {
+	ch, err := c.Connection.Channel()
+	if err != nil {
+		return nil, err
+	}
+	return amqpChannel{ch}, nil
+}
+
+// amqpChannel adapts an amqp091 channel to Channel
+type amqpChannel struct {
+	*amqp.Channel
+}
+
+// PublishWithDeferredConfirmWithContext implements Channel
+func (c amqpChannel) PublishWithDeferredConfirmWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) (Confirmation, error) {
+	confirm, err := c.Channel.PublishWithDeferredConfirmWithContext(ctx, exchange, key, mandatory, immediate, msg)
+	if err != nil {
+		return nil, err
+	}
+	if confirm == nil {
+		return nil, fmt.Errorf("channel is not in confirm mode")
+	}
+	return confirm, nil
+}
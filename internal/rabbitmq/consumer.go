@@ -3,12 +3,17 @@ package rabbitmq
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"sync"
+	"time"
 
 	"github.com/gamers-bot/internal/bot"
 	"github.com/gamers-bot/internal/handlers"
+	"github.com/gamers-bot/internal/metrics"
+	"github.com/gamers-bot/internal/schema"
+	"github.com/gamers-bot/internal/store"
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
@@ -16,26 +21,106 @@ import (
 // It declares the gamers.events exchange, binds new queues to it, and also
 // maintains the legacy discord.commands queue with its request/response pattern.
 type ConsumerManager struct {
-	conn          *amqp.Connection
+	conn          Connection
 	exchange      string
 	prefetchCount int
 	bot           *bot.DiscordBot
 	publisher     *Publisher // used only by legacy consumer for responses
 	handlers      map[EventType]handlers.Handler
-	channels      []*amqp.Channel
+	retryPolicy   RetryPolicy
+	dedup         store.DedupStore // optional; nil disables duplicate detection
+	schemas       *schema.Registry // optional; nil disables payload validation
+	metrics       *metrics.Metrics // optional; nil records nothing
+	status        *StatusTracker   // optional; nil records nothing
+	topology      *Topology
+	queues        map[string]QueueBinding // topology queues by name
+
+	workers      int            // default worker pool size per queue
+	queueWorkers map[string]int // per-queue overrides of workers
+
+	shutdownGracePeriod time.Duration // how long in-flight handlers may run after Start's ctx is cancelled
+
+	highAvailability bool                   // quorum queues with single active consumer and replica election
+	onRoleChange     func(role ReplicaRole) // optional; reports active/standby in HA mode
+
+	channelsMu sync.Mutex
+	channels   []Channel
+
+	republisherMu sync.Mutex
+	republisher   *Publisher // confirm-mode publisher for retry and dead-letter copies; opened on first use
 }
 
 // NewConsumerManager creates a new ConsumerManager.
-// exchange is the primary exchange name (e.g. "gamers.events").
-func NewConsumerManager(conn *amqp.Connection, exchange string, prefetchCount int, bot *bot.DiscordBot, publisher *Publisher) *ConsumerManager {
-	return &ConsumerManager{
+// exchange is the primary exchange name (e.g. "gamers.events"); topology declares the
+// notification queues and may be nil for the built-in topology.
+func NewConsumerManager(conn Connection, exchange string, prefetchCount int, topology *Topology, bot *bot.DiscordBot, publisher *Publisher) *ConsumerManager {
+	cm := &ConsumerManager{
 		conn:          conn,
 		exchange:      exchange,
 		prefetchCount: prefetchCount,
 		bot:           bot,
 		publisher:     publisher,
 		handlers:      make(map[EventType]handlers.Handler),
+		retryPolicy:   DefaultRetryPolicy(),
+		workers:       1,
+		queueWorkers:  make(map[string]int),
+
+		shutdownGracePeriod: 10 * time.Second,
+	}
+	if topology == nil {
+		topology = DefaultTopology("")
 	}
+	cm.SetTopology(topology)
+	return cm
+}
+
+// SetShutdownGracePeriod sets how long in-flight handlers may keep running once Start's context is cancelled
+func (cm *ConsumerManager) SetShutdownGracePeriod(gracePeriod time.Duration) {
+	cm.shutdownGracePeriod = gracePeriod
+}
+
+// SetWorkers sets the default number of concurrent workers per queue.
+// The prefetch count of a queue is raised to at least its worker count.
+func (cm *ConsumerManager) SetWorkers(workers int) {
+	cm.workers = workers
+}
+
+// SetQueueWorkers overrides the number of concurrent workers for a single queue
+func (cm *ConsumerManager) SetQueueWorkers(queueName string, workers int) {
+	cm.queueWorkers[queueName] = workers
+}
+
+// SetMetrics records consumed, acked and nacked deliveries per queue and event type in m
+func (cm *ConsumerManager) SetMetrics(m *metrics.Metrics) {
+	cm.metrics = m
+}
+
+// SetStatusTracker records consumer state, received deliveries and processing errors in tracker
+func (cm *ConsumerManager) SetStatusTracker(tracker *StatusTracker) {
+	cm.status = tracker
+}
+
+// SetDedupStore enables duplicate detection by event_id using the given store
+func (cm *ConsumerManager) SetDedupStore(dedup store.DedupStore) {
+	cm.dedup = dedup
+}
+
+// SetTopology replaces the notification queue topology. Must be called before SetupTopology.
+// A topology exchange overrides the exchange passed to NewConsumerManager.
+func (cm *ConsumerManager) SetTopology(topology *Topology) {
+	cm.topology = topology
+	cm.queues = make(map[string]QueueBinding, len(topology.Queues))
+	for _, q := range topology.Queues {
+		cm.queues[q.QueueName] = q
+	}
+	if topology.Exchange != "" {
+		cm.exchange = topology.Exchange
+	}
+}
+
+// SetRetryPolicy overrides the retry policy. Must be called before SetupTopology.
+func (cm *ConsumerManager) SetRetryPolicy(policy RetryPolicy) {
+	cm.retryPolicy = policy
 }
 
 // RegisterHandler registers a handler for a specific event type
@@ -43,7 +128,15 @@ func (cm *ConsumerManager) RegisterHandler(eventType EventType, handler handlers
 	cm.handlers[eventType] = handler
 }
 
-// SetupTopology declares the primary exchange and sets up all queue bindings from DefaultQueueBindings.
+// RegisterTyped registers a typed handler for a specific event type. The delivery body is decoded
+// straight into In, validated if In implements handlers.Validator, and the Out result is encoded
+// as the response data. It is a function because Go methods cannot have type parameters.
+func RegisterTyped[In, Out any](cm *ConsumerManager, eventType EventType, handle handlers.TypedFunc[In, Out]) {
+	cm.RegisterHandler(eventType, handlers.NewTypedHandler(handle))
+}
+
+// SetupTopology declares the primary exchange and sets up all queues and bindings of the topology.
+// Every queue gets a dead-letter queue and a set of delay queues according to the retry policy.
 func (cm *ConsumerManager) SetupTopology() error {
 	ch, err := cm.conn.Channel()
 	if err != nil {
@@ -66,18 +159,14 @@ func (cm *ConsumerManager) SetupTopology() error {
 	}
 	slog.Info("Exchange declared", "exchange", cm.exchange)
 
+	if err := cm.declareDeadLetterExchange(ch); err != nil {
+		return err
+	}
+
 	// Declare and bind all queues
-	for _, qb := range DefaultQueueBindings() {
-		_, err = ch.QueueDeclare(
-			qb.QueueName, // name
-			true,          // durable
-			false,         // delete when unused
-			false,         // exclusive
-			false,         // no-wait
-			nil,           // arguments
-		)
-		if err != nil {
-			return fmt.Errorf("failed to declare queue %s: %w", qb.QueueName, err)
+	for _, qb := range cm.topology.Queues {
+		if err := cm.declareQueueWithRetry(ch, qb.QueueName, cm.queueArguments(qb.arguments()), qb.DeadLetterExchange); err != nil {
+			return err
 		}
 
 		for _, rk := range qb.RoutingKeys {
@@ -99,6 +188,7 @@ func (cm *ConsumerManager) SetupTopology() error {
 }
 
 // SetupLegacyQueue declares legacy exchanges and binds the legacy queue.
+// The legacy queue gets the same dead-letter and retry topology as the notification queues.
 func (cm *ConsumerManager) SetupLegacyQueue(queueName string, bindings []LegacyBinding) error {
 	ch, err := cm.conn.Channel()
 	if err != nil {
@@ -106,17 +196,13 @@ func (cm *ConsumerManager) SetupLegacyQueue(queueName string, bindings []LegacyB
 	}
 	defer ch.Close()
 
-	// Declare the legacy queue
-	_, err = ch.QueueDeclare(
-		queueName, // name
-		true,      // durable
-		false,     // delete when unused
-		false,     // exclusive
-		false,     // no-wait
-		nil,       // arguments
-	)
-	if err != nil {
-		return fmt.Errorf("failed to declare legacy queue %s: %w", queueName, err)
+	if err := cm.declareDeadLetterExchange(ch); err != nil {
+		return err
+	}
+
+	// Declare the legacy queue with its DLQ and delay queues
+	if err := cm.declareQueueWithRetry(ch, queueName, cm.queueArguments(nil), ""); err != nil {
+		return err
 	}
 
 	for _, b := range bindings {
@@ -160,21 +246,80 @@ type LegacyBinding struct {
 	RoutingKey string
 }
 
-// Start starts consuming from all queues. It launches a goroutine per queue and blocks until ctx is cancelled.
+// Start starts consuming from all queues. It launches a worker pool per queue and blocks until ctx is cancelled.
+// On cancellation the consumers are cancelled so no new deliveries arrive, and in-flight handlers get up to
+// the shutdown grace period to finish and settle their deliveries before Start returns.
+// In HA mode Start first waits until this replica is elected active.
 func (cm *ConsumerManager) Start(ctx context.Context, legacyQueueName string) error {
+	// Consumers stop when ctx is cancelled or any of them fails
+	consumeCtx, stopConsuming := context.WithCancel(ctx)
+	defer stopConsuming()
+
+	// Handlers run on their own context so in-flight work survives until the grace period expires
+	handlerCtx, cancelHandlers := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelHandlers()
+	go func() {
+		select {
+		case <-consumeCtx.Done():
+		case <-handlerCtx.Done():
+			return
+		}
+		timer := time.NewTimer(cm.shutdownGracePeriod)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			slog.Warn("Shutdown grace period expired, canceling in-flight handlers", "grace_period", cm.shutdownGracePeriod)
+			cancelHandlers()
+		case <-handlerCtx.Done():
+		}
+	}()
+
+	// Every queue is reported as stopped until its consumer is attached
+	for _, qb := range cm.topology.Queues {
+		cm.status.setQueueState(qb.QueueName, QueueStopped)
+	}
+	if legacyQueueName != "" {
+		cm.status.setQueueState(legacyQueueName, QueueStopped)
+	}
+
 	var wg sync.WaitGroup
 	errCh := make(chan error, 1)
 
+	// In HA mode only the elected replica consumes; a standby waits here until it takes over
+	if cm.highAvailability {
+		lost, err := cm.acquireLeadership(consumeCtx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("replica election failed: %w", err)
+		}
+		go func() {
+			select {
+			case <-lost:
+				if consumeCtx.Err() == nil {
+					select {
+					case errCh <- fmt.Errorf("replica leadership lost"):
+					default:
+					}
+					stopConsuming()
+				}
+			case <-consumeCtx.Done():
+			}
+		}()
+	}
+
 	// Start notification queue consumers (no response publishing)
-	for _, qb := range DefaultQueueBindings() {
+	for _, qb := range cm.topology.Queues {
 		wg.Add(1)
 		go func(queueName string) {
 			defer wg.Done()
-			if err := cm.consumeNotificationQueue(ctx, queueName); err != nil {
+			if err := cm.consumeNotificationQueue(consumeCtx, handlerCtx, queueName); err != nil && consumeCtx.Err() == nil {
 				select {
 				case errCh <- fmt.Errorf("notification queue %s error: %w", queueName, err):
 				default:
 				}
+				stopConsuming()
 			}
 		}(qb.QueueName)
 	}
@@ -184,75 +329,71 @@ func (cm *ConsumerManager) Start(ctx context.Context, legacyQueueName string) er
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			if err := cm.consumeLegacyQueue(ctx, legacyQueueName); err != nil {
+			if err := cm.consumeLegacyQueue(consumeCtx, handlerCtx, legacyQueueName); err != nil && consumeCtx.Err() == nil {
 				select {
 				case errCh <- fmt.Errorf("legacy queue %s error: %w", legacyQueueName, err):
 				default:
 				}
+				stopConsuming()
 			}
 		}()
 	}
 
-	// Wait for all consumers to finish or first error
-	doneCh := make(chan struct{})
-	go func() {
-		wg.Wait()
-		close(doneCh)
-	}()
-
+	// Wait for all consumers to drain, then report the first error if any
+	wg.Wait()
 	select {
-	case <-doneCh:
-		return ctx.Err()
 	case err := <-errCh:
 		return err
+	default:
+		return ctx.Err()
 	}
 }
 
 // consumeNotificationQueue consumes from a notification queue (Ack/Nack only, no response).
-func (cm *ConsumerManager) consumeNotificationQueue(ctx context.Context, queueName string) error {
+// Handlers run with handlerCtx; ctx only controls consumption.
+func (cm *ConsumerManager) consumeNotificationQueue(ctx, handlerCtx context.Context, queueName string) error {
 	ch, err := cm.conn.Channel()
 	if err != nil {
 		return fmt.Errorf("failed to open channel for queue %s: %w", queueName, err)
 	}
-	cm.channels = append(cm.channels, ch)
+	cm.trackChannel(ch)
 
-	err = ch.Qos(cm.prefetchCount, 0, false)
+	err = ch.Qos(cm.prefetchFor(queueName), 0, false)
 	if err != nil {
 		return fmt.Errorf("failed to set QoS for queue %s: %w", queueName, err)
 	}
 
 	msgs, err := ch.Consume(
-		queueName, // queue
-		"",        // consumer
-		false,     // auto-ack
-		false,     // exclusive
-		false,     // no-local
-		false,     // no-wait
-		nil,       // args
+		queueName,              // queue
+		consumerTag(queueName), // consumer
+		false,                  // auto-ack
+		false,                  // exclusive
+		false,                  // no-local
+		false,                  // no-wait
+		nil,                    // args
 	)
 	if err != nil {
 		return fmt.Errorf("failed to register consumer for queue %s: %w", queueName, err)
 	}
 
-	slog.Info("Started consuming notification queue", "queue", queueName)
+	slog.Info("Started consuming notification queue", "queue", queueName, "workers", cm.workersFor(queueName))
+	cm.status.setQueueState(queueName, QueueConsuming)
+	defer cm.status.setQueueState(queueName, QueueStopped)
 
-	for {
-		select {
-		case <-ctx.Done():
-			slog.Info("Notification consumer stopped", "queue", queueName)
-			return ctx.Err()
-		case msg, ok := <-msgs:
-			if !ok {
-				slog.Warn("Notification message channel closed", "queue", queueName)
-				return fmt.Errorf("message channel closed for queue %s", queueName)
-			}
-			cm.handleNotificationMessage(ctx, msg, queueName)
-		}
+	err = cm.consumeWithWorkers(ctx, handlerCtx, ch, msgs, queueName, func(msg amqp.Delivery) {
+		cm.handleNotificationMessage(handlerCtx, msg, queueName)
+	})
+	if ctx.Err() != nil {
+		slog.Info("Notification consumer stopped", "queue", queueName)
+	} else {
+		slog.Warn("Notification consumer channel closed", "queue", queueName, "error", err)
 	}
+	return err
 }
 
 // handleNotificationMessage processes a message from a notification queue.
 // Dispatches by AMQP header event_type first, falls back to JSON body event_type.
+// Malformed messages are dead-lettered immediately; handler failures are settled by handleFailure.
 func (cm *ConsumerManager) handleNotificationMessage(ctx context.Context, msg amqp.Delivery, queueName string) {
 	slog.Info("Received notification message", "queue", queueName, "body", string(msg.Body))
 
@@ -266,7 +407,7 @@ func (cm *ConsumerManager) handleNotificationMessage(ctx context.Context, msg am
 
 	slog.Info("Dispatching notification event", "queue", queueName, "event_type", eventType)
 
-	handler, ok := cm.handlers[eventType]
+	handler, ok := cm.queueHandler(queueName, eventType)
 	if !ok {
 		slog.Warn("No handler registered for event type", "event_type", eventType, "queue", queueName)
 		msg.Nack(false, false)
@@ -281,84 +422,109 @@ func (cm *ConsumerManager) handleNotificationMessage(ctx context.Context, msg am
 		return
 	}
 
+	// Reject payloads that do not match their schema, upgrading older versions for the handler
+	payload, raw, err := cm.preparePayload(msg, eventType, payload, msg.Body)
+	if err != nil {
+		slog.Error("Notification payload failed validation", "event_type", eventType, "queue", queueName, "error", err)
+		cm.deadLetterInvalid(ctx, msg, queueName, err)
+		return
+	}
+
+	// Skip events that were already processed (e.g. redelivered after a reconnect)
+	eventID := extractEventID(payload, msg)
+	if processed, ok := cm.lookupProcessed(eventID); ok {
+		slog.Info("Duplicate event skipped", "event_id", eventID, "event_type", eventType, "queue", queueName, "message_id", processed.MessageID)
+		msg.Ack(false)
+		return
+	}
+
 	// Extract guild_id
 	guildID := extractGuildID(payload)
 
 	// Handle the event
-	_, err := handler.Handle(ctx, cm.bot, guildID, payload)
+	eventCtx := handlers.WithEventInfo(ctx, handlers.EventInfo{
+		EventType:     string(eventType),
+		EventID:       eventID,
+		CorrelationID: msg.CorrelationId,
+		Queue:         queueName,
+	})
+	data, err := cm.dispatch(eventCtx, handler, guildID, payload, raw)
 	if err != nil {
 		slog.Error("Notification handler failed", "event_type", eventType, "queue", queueName, "error", err)
-		msg.Nack(false, false)
+		cm.handleFailure(ctx, msg, queueName, err)
 		return
 	}
 
+	cm.rememberProcessed(eventID, eventType, data)
 	msg.Ack(false)
 	slog.Info("Notification event processed", "event_type", eventType, "queue", queueName)
 }
 
 // consumeLegacyQueue consumes from the legacy queue with request/response pattern.
-func (cm *ConsumerManager) consumeLegacyQueue(ctx context.Context, queueName string) error {
+// Handlers run with handlerCtx; ctx only controls consumption.
+func (cm *ConsumerManager) consumeLegacyQueue(ctx, handlerCtx context.Context, queueName string) error {
 	ch, err := cm.conn.Channel()
 	if err != nil {
 		return fmt.Errorf("failed to open channel for legacy queue %s: %w", queueName, err)
 	}
-	cm.channels = append(cm.channels, ch)
+	cm.trackChannel(ch)
 
-	err = ch.Qos(cm.prefetchCount, 0, false)
+	err = ch.Qos(cm.prefetchFor(queueName), 0, false)
 	if err != nil {
 		return fmt.Errorf("failed to set QoS for legacy queue %s: %w", queueName, err)
 	}
 
 	msgs, err := ch.Consume(
-		queueName, // queue
-		"",        // consumer
-		false,     // auto-ack
-		false,     // exclusive
-		false,     // no-local
-		false,     // no-wait
-		nil,       // args
+		queueName,              // queue
+		consumerTag(queueName), // consumer
+		false,                  // auto-ack
+		false,                  // exclusive
+		false,                  // no-local
+		false,                  // no-wait
+		nil,                    // args
 	)
 	if err != nil {
 		return fmt.Errorf("failed to register consumer for legacy queue %s: %w", queueName, err)
 	}
 
-	slog.Info("Started consuming legacy queue", "queue", queueName)
+	slog.Info("Started consuming legacy queue", "queue", queueName, "workers", cm.workersFor(queueName))
+	cm.status.setQueueState(queueName, QueueConsuming)
+	defer cm.status.setQueueState(queueName, QueueStopped)
 
-	for {
-		select {
-		case <-ctx.Done():
-			slog.Info("Legacy consumer stopped", "queue", queueName)
-			return ctx.Err()
-		case msg, ok := <-msgs:
-			if !ok {
-				slog.Warn("Legacy message channel closed", "queue", queueName)
-				return fmt.Errorf("message channel closed for legacy queue %s", queueName)
-			}
-			cm.handleLegacyMessage(ctx, msg)
-		}
+	err = cm.consumeWithWorkers(ctx, handlerCtx, ch, msgs, queueName, func(msg amqp.Delivery) {
+		cm.handleLegacyMessage(handlerCtx, msg, queueName)
+	})
+	if ctx.Err() != nil {
+		slog.Info("Legacy consumer stopped", "queue", queueName)
+	} else {
+		slog.Warn("Legacy consumer channel closed", "queue", queueName, "error", err)
 	}
+	return err
 }
 
 // handleLegacyMessage processes a message from the legacy queue (request/response pattern).
-func (cm *ConsumerManager) handleLegacyMessage(ctx context.Context, msg amqp.Delivery) {
+// An error response is only published once the message is dead-lettered, not on every retry.
+// Responses go to the AMQP reply_to queue when set, otherwise to the configured response queue.
+func (cm *ConsumerManager) handleLegacyMessage(ctx context.Context, msg amqp.Delivery, queueName string) {
 	slog.Info("Received legacy message", "body", string(msg.Body))
 
 	// Parse request message
 	var request RequestMessage
 	if err := json.Unmarshal(msg.Body, &request); err != nil {
 		slog.Error("Failed to unmarshal legacy request", "error", err)
-		cm.sendErrorResponse(ctx, "", fmt.Errorf("invalid message format: %w", err))
+		cm.sendErrorResponse(ctx, resolveReplyRoute(msg, &request), bot.NewInvalidPayloadError("invalid message format: %w", err))
 		msg.Nack(false, false)
 		return
 	}
 
+	route := resolveReplyRoute(msg, &request)
 	guildID := request.GetGuildID()
-	slog.Info("Processing legacy event", "correlation_id", request.CorrelationID, "guild_id", guildID, "event_type", request.EventType)
+	slog.Info("Processing legacy event", "correlation_id", route.correlationID, "reply_to", route.replyTo, "guild_id", guildID, "event_type", request.EventType)
 
 	// Validate guild_id
 	if guildID == "" {
 		slog.Error("Missing guild_id in legacy request")
-		cm.sendErrorResponse(ctx, request.CorrelationID, fmt.Errorf("guild_id is required"))
+		cm.sendErrorResponse(ctx, route, bot.NewValidationError("guild_id is required"))
 		msg.Nack(false, false)
 		return
 	}
@@ -367,13 +533,29 @@ func (cm *ConsumerManager) handleLegacyMessage(ctx context.Context, msg amqp.Del
 	handler, ok := cm.handlers[request.EventType]
 	if !ok {
 		slog.Error("Unsupported event type in legacy queue", "event_type", request.EventType)
-		cm.sendErrorResponse(ctx, request.CorrelationID, fmt.Errorf("unsupported event type: %s", request.EventType))
+		cm.sendErrorResponse(ctx, route, bot.NewError(bot.ErrorKindPermanent, bot.ErrCodeUnsupportedEvent, fmt.Errorf("unsupported event type: %s", request.EventType)))
 		msg.Nack(false, false)
 		return
 	}
 
-	// Prepare payload based on event type
+	// Replay the stored result for events that were already processed
+	eventID := request.EventID
+	if eventID == "" {
+		eventID = msg.MessageId
+	}
+	if processed, ok := cm.lookupProcessed(eventID); ok {
+		slog.Info("Duplicate legacy event skipped", "event_id", eventID, "correlation_id", route.correlationID, "message_id", processed.MessageID)
+		if err := cm.sendSuccessResponse(ctx, route, processed.Result); err != nil {
+			cm.handleResponseFailure(ctx, msg, queueName, route, err)
+			return
+		}
+		msg.Ack(false)
+		return
+	}
+
+	// Prepare payload based on event type. raw is the undecoded payload for handlers that decode it themselves.
 	payload := request.Payload
+	raw := rawRequestPayload(msg.Body)
 	if isApplicationEvent(request.EventType) {
 		payload = map[string]interface{}{
 			"event_type":              string(request.EventType),
@@ -384,37 +566,107 @@ func (cm *ConsumerManager) handleLegacyMessage(ctx context.Context, msg amqp.Del
 			"discord_text_channel_id": request.DiscordTextChannelID,
 			"data":                    request.Data,
 		}
+		raw = nil
 	} else if isTeamEvent(request.EventType) {
 		var fullPayload map[string]interface{}
 		if err := json.Unmarshal(msg.Body, &fullPayload); err != nil {
 			slog.Error("Failed to unmarshal team event payload", "error", err)
-			cm.sendErrorResponse(ctx, request.CorrelationID, fmt.Errorf("invalid team event payload: %w", err))
+			cm.sendErrorResponse(ctx, route, bot.NewInvalidPayloadError("invalid team event payload: %w", err))
 			msg.Nack(false, false)
 			return
 		}
 		payload = fullPayload
+		raw = msg.Body
+	}
+
+	// Reject payloads that do not match their schema, upgrading older versions for the handler
+	payload, raw, err := cm.preparePayload(msg, request.EventType, payload, raw)
+	if err != nil {
+		slog.Error("Legacy payload failed validation", "correlation_id", route.correlationID, "event_type", request.EventType, "error", err)
+		cm.sendErrorResponse(ctx, route, err)
+		cm.deadLetterInvalid(ctx, msg, queueName, err)
+		return
 	}
 
 	// Handle the event
-	data, err := handler.Handle(ctx, cm.bot, guildID, payload)
+	eventCtx := handlers.WithEventInfo(ctx, handlers.EventInfo{
+		EventType:     string(request.EventType),
+		EventID:       eventID,
+		CorrelationID: route.correlationID,
+		Queue:         queueName,
+	})
+	data, err := cm.dispatch(eventCtx, handler, guildID, payload, raw)
 	if err != nil {
-		slog.Error("Legacy handler failed", "correlation_id", request.CorrelationID, "error", err)
+		slog.Error("Legacy handler failed", "correlation_id", route.correlationID, "error", err)
 
-		if isRetriableError() {
-			slog.Info("Requeuing legacy message", "correlation_id", request.CorrelationID)
-			msg.Nack(false, true)
+		if cm.handleFailure(ctx, msg, queueName, err) {
 			return
 		}
 
-		cm.sendErrorResponse(ctx, request.CorrelationID, err)
-		msg.Nack(false, false)
+		cm.sendErrorResponse(ctx, route, err)
 		return
 	}
 
-	// Send success response
-	cm.sendSuccessResponse(ctx, request.CorrelationID, data)
+	// Ack the request only once the broker has confirmed the response
+	cm.rememberProcessed(eventID, request.EventType, data)
+	if err := cm.sendSuccessResponse(ctx, route, data); err != nil {
+		cm.handleResponseFailure(ctx, msg, queueName, route, err)
+		return
+	}
 	msg.Ack(false)
-	slog.Info("Legacy event processed successfully", "correlation_id", request.CorrelationID)
+	slog.Info("Legacy event processed successfully", "correlation_id", route.correlationID)
+}
+
+// dispatch calls handler with the payload. Handlers that decode JSON themselves get the raw
+// payload when it is available, so it is not decoded into a map and encoded again.
+func (cm *ConsumerManager) dispatch(ctx context.Context, handler handlers.Handler, guildID string, payload map[string]interface{}, raw json.RawMessage) (map[string]interface{}, error) {
+	if rawHandler, ok := handler.(handlers.RawHandler); ok && raw != nil {
+		return rawHandler.HandleRaw(ctx, cm.bot, guildID, raw)
+	}
+	return handler.Handle(ctx, cm.bot, guildID, payload)
+}
+
+// rawRequestPayload returns the undecoded payload field of a legacy request
+func rawRequestPayload(body []byte) json.RawMessage {
+	var request struct {
+		Payload json.RawMessage `json:"payload"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		return nil
+	}
+	return request.Payload
+}
+
+// replyRoute identifies where a legacy response is published and how it is correlated
+type replyRoute struct {
+	replyTo       string // queue to reply to; empty means the publisher's response queue
+	correlationID string
+}
+
+// resolveReplyRoute prefers the AMQP reply_to and correlation_id properties of the delivery,
+// falling back to the correlation_id in the JSON body for producers that do not set them.
+func resolveReplyRoute(msg amqp.Delivery, request *RequestMessage) replyRoute {
+	route := replyRoute{
+		replyTo:       msg.ReplyTo,
+		correlationID: msg.CorrelationId,
+	}
+	if route.correlationID == "" {
+		route.correlationID = request.CorrelationID
+	}
+	return route
+}
+
+// queueHandler returns the handler for eventType on a notification queue, following the
+// topology's event_type-to-handler mapping when the queue declares one
+func (cm *ConsumerManager) queueHandler(queueName string, eventType EventType) (handlers.Handler, bool) {
+	name := eventType
+	if q, ok := cm.queues[queueName]; ok {
+		if name, ok = q.handlerName(eventType); !ok {
+			return nil, false
+		}
+	}
+	handler, ok := cm.handlers[name]
+	return handler, ok
 }
 
 // resolveEventType extracts the event type from AMQP headers first, then falls back to JSON body.
@@ -439,6 +691,46 @@ func (cm *ConsumerManager) resolveEventType(msg amqp.Delivery) EventType {
 	return ""
 }
 
+// extractEventID extracts event_id from a payload map, falling back to the AMQP message ID.
+func extractEventID(payload map[string]interface{}, msg amqp.Delivery) string {
+	if id, ok := payload["event_id"].(string); ok && id != "" {
+		return id
+	}
+	return msg.MessageId
+}
+
+// lookupProcessed returns the stored outcome of an already processed event
+func (cm *ConsumerManager) lookupProcessed(eventID string) (*store.ProcessedEvent, bool) {
+	if cm.dedup == nil || eventID == "" {
+		return nil, false
+	}
+	processed, ok, err := cm.dedup.Get(eventID)
+	if err != nil {
+		// Fail open: a store error must not block processing
+		slog.Warn("Failed to look up event in dedup store", "event_id", eventID, "error", err)
+		return nil, false
+	}
+	return processed, ok
+}
+
+// rememberProcessed records a successfully processed event and the Discord message it produced
+func (cm *ConsumerManager) rememberProcessed(eventID string, eventType EventType, data map[string]interface{}) {
+	if cm.dedup == nil || eventID == "" {
+		return
+	}
+	messageID, _ := data["message_id"].(string)
+	err := cm.dedup.Put(store.ProcessedEvent{
+		EventID:     eventID,
+		EventType:   string(eventType),
+		MessageID:   messageID,
+		Result:      data,
+		ProcessedAt: time.Now(),
+	})
+	if err != nil {
+		slog.Warn("Failed to record event in dedup store", "event_id", eventID, "error", err)
+	}
+}
+
 // extractGuildID extracts guild_id from a payload map, trying discord_guild_id first.
 func extractGuildID(payload map[string]interface{}) string {
 	if gid, ok := payload["discord_guild_id"].(string); ok && gid != "" {
@@ -450,39 +742,54 @@ func extractGuildID(payload map[string]interface{}) string {
 	return ""
 }
 
-// sendSuccessResponse sends a success response via the publisher
-func (cm *ConsumerManager) sendSuccessResponse(ctx context.Context, correlationID string, data map[string]interface{}) {
+// sendSuccessResponse sends a success response via the publisher.
+// It returns nil once the broker has confirmed the response.
+func (cm *ConsumerManager) sendSuccessResponse(ctx context.Context, route replyRoute, data map[string]interface{}) error {
 	if cm.publisher == nil {
-		return
+		return nil
 	}
 	response := &ResponseMessage{
-		CorrelationID: correlationID,
+		CorrelationID: route.correlationID,
 		Success:       true,
 		Data:          data,
 	}
-	if err := cm.publisher.Publish(ctx, response); err != nil {
-		slog.Error("Failed to publish success response", "correlation_id", correlationID, "error", err)
-	}
+	return cm.publisher.PublishTo(ctx, route.replyTo, response)
 }
 
-// sendErrorResponse sends an error response via the publisher
-func (cm *ConsumerManager) sendErrorResponse(ctx context.Context, correlationID string, err error) {
+// sendErrorResponse sends an error response via the publisher, including the classified error code.
+// The request is dead-lettered either way, so a failed publish is only logged.
+func (cm *ConsumerManager) sendErrorResponse(ctx context.Context, route replyRoute, err error) {
 	if cm.publisher == nil {
 		return
 	}
+	_, code := bot.Classify(err)
 	response := &ResponseMessage{
-		CorrelationID: correlationID,
+		CorrelationID: route.correlationID,
 		Success:       false,
 		Error:         err.Error(),
+		ErrorCode:     string(code),
+	}
+	var validationErr *schema.ValidationError
+	if errors.As(err, &validationErr) {
+		response.ValidationErrors = validationErr.Violations
 	}
-	if pubErr := cm.publisher.Publish(ctx, response); pubErr != nil {
-		slog.Error("Failed to publish error response", "correlation_id", correlationID, "error", pubErr)
+	if pubErr := cm.publisher.PublishTo(ctx, route.replyTo, response); pubErr != nil {
+		slog.Error("Failed to publish error response", "correlation_id", route.correlationID, "error", pubErr)
 	}
 }
 
-// isRetriableError determines if an error is retriable
-func isRetriableError() bool {
-	return false
+// handleResponseFailure settles a processed legacy request whose response could not be confirmed.
+// Unroutable responses have no receiver, so the request is acked. Otherwise the request is
+// delay-retried; the dedup store then replays the stored result instead of handling it again.
+func (cm *ConsumerManager) handleResponseFailure(ctx context.Context, msg amqp.Delivery, queueName string, route replyRoute, err error) {
+	if errors.Is(err, ErrResponseUnroutable) {
+		slog.Warn("Response is unroutable, acking request", "correlation_id", route.correlationID, "error", err)
+		msg.Ack(false)
+		return
+	}
+
+	slog.Error("Failed to publish success response", "correlation_id", route.correlationID, "error", err)
+	cm.retryOrDeadLetter(ctx, msg, queueName)
 }
 
 // isApplicationEvent checks if the event type is an application event
@@ -524,8 +831,18 @@ func isContestTeamsReadyEvent(eventType EventType) bool {
 	return eventType == EventContestTeamsReady
 }
 
-// Close closes all channels managed by the ConsumerManager
+// trackChannel registers a consumer channel to be closed by Close
+func (cm *ConsumerManager) trackChannel(ch Channel) {
+	cm.channelsMu.Lock()
+	defer cm.channelsMu.Unlock()
+	cm.channels = append(cm.channels, ch)
+}
+
+// Close closes all channels managed by the ConsumerManager, including the republisher's
 func (cm *ConsumerManager) Close() error {
+	cm.channelsMu.Lock()
+	defer cm.channelsMu.Unlock()
+
 	var lastErr error
 	for _, ch := range cm.channels {
 		if ch != nil {
@@ -534,5 +851,13 @@ func (cm *ConsumerManager) Close() error {
 			}
 		}
 	}
+
+	cm.republisherMu.Lock()
+	defer cm.republisherMu.Unlock()
+	if cm.republisher != nil {
+		if err := cm.republisher.Close(); err != nil {
+			lastErr = err
+		}
+	}
 	return lastErr
 }
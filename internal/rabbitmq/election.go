@@ -0,0 +1,170 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// ReplicaRole is the role of this bot replica when running in HA mode
+type ReplicaRole string
+
+const (
+	RoleActive  ReplicaRole = "active"
+	RoleStandby ReplicaRole = "standby"
+)
+
+// ElectionQueueName is the single-active-consumer queue used to elect the active replica
+const ElectionQueueName = "bot.replica.election"
+
+// electionMarkerInterval is how often a standby replica makes sure an election marker exists
+const electionMarkerInterval = 30 * time.Second
+
+// haQueueArguments are added to every consumed bot queue in HA mode: a replicated quorum queue
+// where only one consumer at a time receives messages
+var haQueueArguments = amqp.Table{
+	"x-queue-type":             QueueTypeQuorum,
+	"x-single-active-consumer": true,
+}
+
+// replicatedQueueArguments are added in HA mode to the queues the bot does not consume (DLQs,
+// delay queues and the response queue), so no queue is lost with a single node. Quorum queues
+// reject x-max-priority and only honour x-message-ttl from RabbitMQ 3.10, so the delay queues
+// rely on the TTL alone.
+var replicatedQueueArguments = amqp.Table{
+	"x-queue-type": QueueTypeQuorum,
+}
+
+// SetHighAvailability enables HA mode. All bot queues are declared as quorum queues with
+// single active consumer, and Start only consumes once this replica has been elected active.
+// onRoleChange is called with every role change and may be nil. Must be called before SetupTopology.
+func (cm *ConsumerManager) SetHighAvailability(enabled bool, onRoleChange func(role ReplicaRole)) {
+	cm.highAvailability = enabled
+	cm.onRoleChange = onRoleChange
+}
+
+// queueArguments returns the arguments for a consumed bot queue, forcing quorum and single active consumer in HA mode
+func (cm *ConsumerManager) queueArguments(args amqp.Table) amqp.Table {
+	if !cm.highAvailability {
+		return args
+	}
+	return mergeArguments(args, haQueueArguments)
+}
+
+// replicatedArguments returns the arguments for a bot queue that is not consumed, forcing quorum in HA mode
+func (cm *ConsumerManager) replicatedArguments(args amqp.Table) amqp.Table {
+	if !cm.highAvailability {
+		return args
+	}
+	return mergeArguments(args, replicatedQueueArguments)
+}
+
+// mergeArguments returns a copy of args with overrides applied
+func mergeArguments(args, overrides amqp.Table) amqp.Table {
+	merged := amqp.Table{}
+	for k, v := range args {
+		merged[k] = v
+	}
+	for k, v := range overrides {
+		merged[k] = v
+	}
+	return merged
+}
+
+// setRole reports a role change
+func (cm *ConsumerManager) setRole(role ReplicaRole) {
+	slog.Info("Replica role changed", "role", role)
+	if cm.onRoleChange != nil {
+		cm.onRoleChange(role)
+	}
+}
+
+// acquireLeadership blocks until this replica is elected active or ctx is cancelled.
+//
+// The election queue holds a marker message. The broker delivers it only to the queue's single
+// active consumer, which keeps it unacked for as long as it lives; when its channel closes the
+// marker is requeued and delivered to the next consumer. The returned channel is closed when
+// leadership is lost.
+func (cm *ConsumerManager) acquireLeadership(ctx context.Context) (<-chan struct{}, error) {
+	ch, err := cm.conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open election channel: %w", err)
+	}
+	cm.trackChannel(ch)
+
+	if _, err := ch.QueueDeclare(ElectionQueueName, true, false, false, false, haQueueArguments); err != nil {
+		return nil, fmt.Errorf("failed to declare election queue: %w", err)
+	}
+
+	// Prefetch 2 so the active replica also receives duplicate markers and can discard them
+	if err := ch.Qos(2, 0, false); err != nil {
+		return nil, fmt.Errorf("failed to set QoS for election queue: %w", err)
+	}
+
+	msgs, err := ch.Consume(ElectionQueueName, consumerTag(ElectionQueueName), false, false, false, false, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume election queue: %w", err)
+	}
+
+	cm.setRole(RoleStandby)
+
+	ticker := time.NewTicker(electionMarkerInterval)
+	defer ticker.Stop()
+
+	if err := ensureElectionMarker(ctx, ch); err != nil {
+		return nil, err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+			// Recreate the marker if it was lost, e.g. after the queue was purged
+			if err := ensureElectionMarker(ctx, ch); err != nil {
+				return nil, err
+			}
+		case _, ok := <-msgs:
+			if !ok {
+				return nil, fmt.Errorf("election channel closed")
+			}
+
+			// Keep the marker unacked; discard duplicates that arrive later
+			lost := make(chan struct{})
+			go func() {
+				defer close(lost)
+				for duplicate := range msgs {
+					duplicate.Ack(false)
+				}
+			}()
+
+			cm.setRole(RoleActive)
+			return lost, nil
+		}
+	}
+}
+
+// ensureElectionMarker publishes an election marker if none is waiting in the queue.
+// A marker held by the active replica is not counted; the extra one is delivered to it and discarded.
+func ensureElectionMarker(ctx context.Context, ch Channel) error {
+	q, err := ch.QueueDeclarePassive(ElectionQueueName, true, false, false, false, haQueueArguments)
+	if err != nil {
+		return fmt.Errorf("failed to inspect election queue: %w", err)
+	}
+	if q.Messages > 0 {
+		return nil
+	}
+
+	err = ch.PublishWithContext(ctx, "", ElectionQueueName, false, false, amqp.Publishing{
+		DeliveryMode: amqp.Persistent,
+		ContentType:  "application/json",
+		Body:         []byte("{}"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish election marker: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package rabbitmq_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gamers-bot/internal/rabbitmq"
+	"github.com/gamers-bot/internal/rabbitmq/rabbitmqtest"
+)
+
+func TestHighAvailabilityReplicatesEveryQueue(t *testing.T) {
+	broker := rabbitmqtest.NewBroker()
+	manager := newTestManager(t, broker, &failingHandler{})
+	manager.SetRetryPolicy(rabbitmq.RetryPolicy{DeadLetterExchange: "test.dlx", MaxAttempts: 2, BaseDelay: time.Second})
+	manager.SetHighAvailability(true, nil)
+	if err := manager.SetupTopology(); err != nil {
+		t.Fatalf("SetupTopology: %v", err)
+	}
+	if err := manager.SetupLegacyQueue("test.commands", nil); err != nil {
+		t.Fatalf("SetupLegacyQueue: %v", err)
+	}
+
+	conn, err := broker.Dial("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	publisher, err := rabbitmq.NewPublisher(conn, "test.responses", true)
+	if err != nil {
+		t.Fatalf("NewPublisher: %v", err)
+	}
+	defer publisher.Close()
+
+	for _, name := range []string{testQueue, "test.commands"} {
+		args := broker.QueueArguments(name)
+		if args["x-queue-type"] != rabbitmq.QueueTypeQuorum || args["x-single-active-consumer"] != true {
+			t.Errorf("consumed queue %s declared with %v, want a single active consumer quorum queue", name, args)
+		}
+	}
+
+	for _, name := range []string{
+		rabbitmq.DeadLetterQueueName(testQueue),
+		testQueue + ".retry.1",
+		testQueue + ".retry.2",
+		rabbitmq.DeadLetterQueueName("test.commands"),
+		"test.commands.retry.1",
+		"test.responses",
+	} {
+		args := broker.QueueArguments(name)
+		if args["x-queue-type"] != rabbitmq.QueueTypeQuorum {
+			t.Errorf("queue %s declared with %v, want a quorum queue", name, args)
+		}
+		if _, ok := args["x-max-priority"]; ok {
+			t.Errorf("queue %s declared with x-max-priority, which quorum queues reject", name)
+		}
+	}
+
+	if ttl := broker.QueueArguments(testQueue + ".retry.2")["x-message-ttl"]; ttl != int64(2000) {
+		t.Errorf("x-message-ttl of the second delay queue = %v, want 2000", ttl)
+	}
+}
+
+func TestQueuesAreClassicWithoutHighAvailability(t *testing.T) {
+	broker := rabbitmqtest.NewBroker()
+	manager := newTestManager(t, broker, &failingHandler{})
+	if err := manager.SetupTopology(); err != nil {
+		t.Fatalf("SetupTopology: %v", err)
+	}
+
+	for _, name := range []string{testQueue, rabbitmq.DeadLetterQueueName(testQueue), testQueue + ".retry.1"} {
+		if queueType, ok := broker.QueueArguments(name)["x-queue-type"]; ok {
+			t.Errorf("queue %s declared with x-queue-type %v, want the server default", name, queueType)
+		}
+	}
+}
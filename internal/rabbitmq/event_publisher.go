@@ -0,0 +1,129 @@
+package rabbitmq
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/gamers-bot/internal/schema"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// EventSchemaVersion is the schema version of the events published by the bot
+const EventSchemaVersion = 1
+
+// EventPublisher publishes bot-originated domain events (button clicks, commands, ...) to the events exchange.
+// Every event is wrapped in the BaseEvent envelope (event_id, event_type, timestamp, schema_version) and routed
+// by its event type. Events are published like responses: as mandatory on a confirm-mode channel that is
+// reopened when it is lost, so PublishEvent only returns nil once a queue has taken the event.
+type EventPublisher struct {
+	publisher *Publisher
+	exchange  string
+}
+
+// NewEventPublisher creates a new EventPublisher and declares the events exchange
+func NewEventPublisher(conn Connection, exchange string) (*EventPublisher, error) {
+	publisher, err := newPublisher(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	err = publisher.channel.ExchangeDeclare(
+		exchange, // name
+		"topic",  // type
+		true,     // durable
+		false,    // auto-deleted
+		false,    // internal
+		false,    // no-wait
+		nil,      // arguments
+	)
+	if err != nil {
+		publisher.Close()
+		return nil, fmt.Errorf("failed to declare exchange %s: %w", exchange, err)
+	}
+
+	return &EventPublisher{
+		publisher: publisher,
+		exchange:  exchange,
+	}, nil
+}
+
+// PublishEvent publishes payload as an event of eventType and waits for the broker confirm. The payload
+// must encode to a JSON object; event_type is always set, and event_id and timestamp are generated unless
+// the payload provides them. The event type is used as routing key and event_type header. An event no queue
+// is bound for fails with ErrResponseUnroutable. It implements bot.EventPublisher.
+func (p *EventPublisher) PublishEvent(ctx context.Context, eventType, correlationID string, payload interface{}) error {
+	envelope, err := toEnvelope(payload)
+	if err != nil {
+		return err
+	}
+
+	eventID, _ := envelope["event_id"].(string)
+	if eventID == "" {
+		eventID = NewEventID()
+		envelope["event_id"] = eventID
+	}
+
+	now := time.Now().UTC()
+	if ts, _ := envelope["timestamp"].(string); ts == "" {
+		envelope["timestamp"] = now.Format(time.RFC3339)
+	}
+	envelope["event_type"] = eventType
+	envelope[schema.VersionField] = EventSchemaVersion
+
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	err = p.publisher.publish(ctx, p.exchange, eventType, amqp.Publishing{
+		Headers:       amqp.Table{"event_type": eventType, schema.VersionField: int32(EventSchemaVersion)},
+		ContentType:   "application/json",
+		DeliveryMode:  amqp.Persistent,
+		CorrelationId: correlationID,
+		MessageId:     eventID,
+		Timestamp:     now,
+		Type:          eventType,
+		Body:          body,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish event %s: %w", eventType, err)
+	}
+
+	slog.Debug("Published event", "event_type", eventType, "event_id", eventID, "exchange", p.exchange, "correlation_id", correlationID)
+	return nil
+}
+
+// Close closes the publisher channel
+func (p *EventPublisher) Close() error {
+	return p.publisher.Close()
+}
+
+// toEnvelope converts a payload into a JSON object map
+func toEnvelope(payload interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event payload: %w", err)
+	}
+
+	var envelope map[string]interface{}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("event payload must be a JSON object: %w", err)
+	}
+	if envelope == nil {
+		envelope = make(map[string]interface{})
+	}
+	return envelope, nil
+}
+
+// NewEventID returns a random RFC 4122 version 4 UUID, used as event and message ID
+func NewEventID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // variant 10
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,130 @@
+package rabbitmq_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/gamers-bot/internal/rabbitmq"
+	"github.com/gamers-bot/internal/rabbitmq/rabbitmqtest"
+)
+
+const (
+	testEventsExchange = "test.bot.events"
+	testCommandQueue   = "test.commands"
+	testCommandType    = "team.invite.accept_requested"
+)
+
+// trackingConnection remembers the channels opened on it
+type trackingConnection struct {
+	rabbitmq.Connection
+
+	mu       sync.Mutex
+	channels []rabbitmq.Channel
+}
+
+func (c *trackingConnection) Channel() (rabbitmq.Channel, error) {
+	ch, err := c.Connection.Channel()
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.channels = append(c.channels, ch)
+	return ch, nil
+}
+
+// lastChannel returns the channel opened last and the number of channels opened
+func (c *trackingConnection) lastChannel() (rabbitmq.Channel, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.channels[len(c.channels)-1], len(c.channels)
+}
+
+// newTestEventPublisher returns an event publisher on the broker and its connection
+func newTestEventPublisher(t *testing.T, broker *rabbitmqtest.Broker) (*rabbitmq.EventPublisher, *trackingConnection) {
+	t.Helper()
+	conn, err := broker.Dial("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	tracking := &trackingConnection{Connection: conn}
+	publisher, err := rabbitmq.NewEventPublisher(tracking, testEventsExchange)
+	if err != nil {
+		t.Fatalf("NewEventPublisher: %v", err)
+	}
+	t.Cleanup(func() { publisher.Close() })
+	return publisher, tracking
+}
+
+// bindCommandQueue declares testCommandQueue and binds it to the events exchange for testCommandType
+func bindCommandQueue(t *testing.T, broker *rabbitmqtest.Broker) {
+	t.Helper()
+	conn, err := broker.Dial("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	ch, err := conn.Channel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ch.Close()
+	if _, err := ch.QueueDeclare(testCommandQueue, true, false, false, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := ch.QueueBind(testCommandQueue, testCommandType, testEventsExchange, false, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPublishEventIsConfirmed(t *testing.T) {
+	broker := rabbitmqtest.NewBroker()
+	publisher, _ := newTestEventPublisher(t, broker)
+	bindCommandQueue(t, broker)
+
+	err := publisher.PublishEvent(context.Background(), testCommandType, "interaction-1", map[string]interface{}{"game_id": 42})
+	if err != nil {
+		t.Fatalf("PublishEvent: %v", err)
+	}
+
+	messages := broker.Messages(testCommandQueue)
+	if len(messages) != 1 {
+		t.Fatalf("%s has %d messages, want 1", testCommandQueue, len(messages))
+	}
+	if m := messages[0]; m.CorrelationId != "interaction-1" || m.Headers["event_type"] != testCommandType {
+		t.Errorf("message = %+v", m)
+	}
+}
+
+func TestPublishEventReportsUnroutableEvent(t *testing.T) {
+	broker := rabbitmqtest.NewBroker()
+	publisher, _ := newTestEventPublisher(t, broker)
+
+	err := publisher.PublishEvent(context.Background(), testCommandType, "interaction-1", map[string]interface{}{})
+	if !errors.Is(err, rabbitmq.ErrResponseUnroutable) {
+		t.Errorf("PublishEvent error = %v, want ErrResponseUnroutable", err)
+	}
+}
+
+func TestPublishEventReopensLostChannel(t *testing.T) {
+	broker := rabbitmqtest.NewBroker()
+	publisher, conn := newTestEventPublisher(t, broker)
+	bindCommandQueue(t, broker)
+
+	lost, _ := conn.lastChannel()
+	lost.Close()
+
+	if err := publisher.PublishEvent(context.Background(), testCommandType, "interaction-1", map[string]interface{}{}); err != nil {
+		t.Fatalf("PublishEvent after channel loss: %v", err)
+	}
+	if _, opened := conn.lastChannel(); opened != 2 {
+		t.Errorf("opened %d channels, want 2", opened)
+	}
+	if n := len(broker.Messages(testCommandQueue)); n != 1 {
+		t.Errorf("%s has %d messages, want 1", testCommandQueue, n)
+	}
+}
@@ -0,0 +1,8 @@
+package rabbitmq
+
+import "time"
+
+// Backoff exposes the reconnect delay of an attempt to the tests
+func (s *Supervisor) Backoff(attempt int) time.Duration {
+	return s.backoff(attempt)
+}
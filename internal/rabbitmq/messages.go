@@ -1,5 +1,7 @@
 package rabbitmq
 
+import "github.com/gamers-bot/internal/schema"
+
 // EventType represents the type of event being processed
 type EventType string
 
@@ -85,6 +87,7 @@ const (
 // Supports both legacy format (guild_id) and WAS format (discord_guild_id)
 type RequestMessage struct {
 	CorrelationID        string                 `json:"correlation_id"`
+	EventID              string                 `json:"event_id"`
 	GuildID              string                 `json:"guild_id"`
 	DiscordGuildID       string                 `json:"discord_guild_id"`
 	DiscordTextChannelID string                 `json:"discord_text_channel_id"`
@@ -110,4 +113,8 @@ type ResponseMessage struct {
 	Success       bool                   `json:"success"`
 	Data          map[string]interface{} `json:"data,omitempty"`
 	Error         string                 `json:"error,omitempty"`
+	ErrorCode     string                 `json:"error_code,omitempty"`
+
+	// ValidationErrors lists every schema violation when ErrorCode is VALIDATION_FAILED
+	ValidationErrors []schema.Violation `json:"validation_errors,omitempty"`
 }
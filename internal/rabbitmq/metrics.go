@@ -0,0 +1,65 @@
+package rabbitmq
+
+import (
+	"github.com/gamers-bot/internal/metrics"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// unknownEventType labels deliveries whose event type has no handler, so arbitrary
+// event types cannot blow up the number of series
+const unknownEventType = "unknown"
+
+// instrumentDeliveries wraps handle so every delivery is counted as consumed and its ack or nack is
+// recorded, wherever in the consumer it is settled
+func (cm *ConsumerManager) instrumentDeliveries(queueName string, handle func(amqp.Delivery)) func(amqp.Delivery) {
+	return func(msg amqp.Delivery) {
+		eventType := string(cm.resolveEventType(msg))
+		if _, ok := cm.handlers[EventType(eventType)]; !ok {
+			eventType = unknownEventType
+		}
+		cm.metrics.MessageConsumed(queueName, eventType)
+
+		msg.Acknowledger = &instrumentedAcknowledger{
+			next:      msg.Acknowledger,
+			metrics:   cm.metrics,
+			queue:     queueName,
+			eventType: eventType,
+		}
+		handle(msg)
+	}
+}
+
+// instrumentedAcknowledger records successful acks and nacks of a delivery
+type instrumentedAcknowledger struct {
+	next      amqp.Acknowledger
+	metrics   *metrics.Metrics
+	queue     string
+	eventType string
+}
+
+// Ack implements amqp.Acknowledger
+func (a *instrumentedAcknowledger) Ack(tag uint64, multiple bool) error {
+	err := a.next.Ack(tag, multiple)
+	if err == nil {
+		a.metrics.MessageAcked(a.queue, a.eventType)
+	}
+	return err
+}
+
+// Nack implements amqp.Acknowledger
+func (a *instrumentedAcknowledger) Nack(tag uint64, multiple, requeue bool) error {
+	err := a.next.Nack(tag, multiple, requeue)
+	if err == nil {
+		a.metrics.MessageNacked(a.queue, a.eventType, requeue)
+	}
+	return err
+}
+
+// Reject implements amqp.Acknowledger
+func (a *instrumentedAcknowledger) Reject(tag uint64, requeue bool) error {
+	err := a.next.Reject(tag, requeue)
+	if err == nil {
+		a.metrics.MessageNacked(a.queue, a.eventType, requeue)
+	}
+	return err
+}
@@ -3,77 +3,339 @@ package rabbitmq
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
-// Publisher publishes response messages to RabbitMQ
+const (
+	// publishConfirmTimeout bounds how long a publish waits for the broker confirm
+	publishConfirmTimeout = 5 * time.Second
+
+	// publishMaxAttempts is how often a publish is attempted when the channel is lost
+	publishMaxAttempts = 3
+)
+
+var (
+	// ErrResponseUnroutable is returned when the broker returns a mandatory response or event
+	// because no queue is bound for it (e.g. the response queue was deleted)
+	ErrResponseUnroutable = errors.New("response is unroutable")
+
+	// ErrPublisherBlocked is returned while the broker blocks publishing (resource alarm)
+	ErrPublisherBlocked = errors.New("publisher is blocked by the broker")
+
+	// errChannelLost marks a publish that failed because the channel was closed
+	errChannelLost = errors.New("publisher channel lost")
+)
+
+// Publisher publishes response messages to RabbitMQ.
+// The channel is in confirm mode and responses are published as mandatory, so Publish
+// only returns nil once the broker has taken responsibility for the response.
+// Publishes share the channel: each waits for its own confirm, which the client routes by delivery tag.
 type Publisher struct {
-	channel   *amqp.Channel
+	conn      Connection
 	queueName string
+
+	mu      sync.Mutex // guards channel and orders publishes on it
+	channel *confirmChannel
+
+	blocked atomic.Bool
+}
+
+// confirmChannel is a confirm-mode channel together with the publishes awaiting their confirm
+type confirmChannel struct {
+	Channel
+	returns chan amqp.Return
+
+	mu      sync.Mutex // guards pending and the draining of returns
+	pending []*pendingPublish
 }
 
-// NewPublisher creates a new Publisher
-func NewPublisher(conn *amqp.Connection, queueName string) (*Publisher, error) {
-	channel, err := conn.Channel()
+// pendingPublish is a publish awaiting its confirm, with the message the broker returned for it
+type pendingPublish struct {
+	exchange  string
+	key       string
+	messageID string
+	returned  *amqp.Return
+}
+
+// NewPublisher creates a new Publisher. replicated declares the response queue as a quorum
+// queue, as in HA mode.
+func NewPublisher(conn Connection, queueName string, replicated bool) (*Publisher, error) {
+	p, err := newPublisher(conn)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open channel: %w", err)
+		return nil, err
+	}
+	p.queueName = queueName
+
+	var args amqp.Table
+	if replicated {
+		args = replicatedQueueArguments
 	}
 
 	// Declare the response queue (durable)
-	_, err = channel.QueueDeclare(
+	_, err = p.channel.QueueDeclare(
 		queueName, // name
 		true,      // durable
 		false,     // delete when unused
 		false,     // exclusive
 		false,     // no-wait
-		nil,       // arguments
+		args,      // arguments
 	)
 	if err != nil {
-		err := channel.Close()
+		err := p.channel.Close()
 		if err != nil {
 			return nil, err
 		}
 		return nil, fmt.Errorf("failed to declare queue: %w", err)
 	}
 
-	return &Publisher{
-		channel:   channel,
-		queueName: queueName,
-	}, nil
+	return p, nil
 }
 
-// Publish publishes a response message to the response queue
+// newPublisher creates a Publisher with a confirm-mode channel on conn. It declares no queue,
+// so it can only publish with an explicit exchange and routing key.
+func newPublisher(conn Connection) (*Publisher, error) {
+	p := &Publisher{conn: conn}
+	if err := p.openChannel(); err != nil {
+		return nil, err
+	}
+
+	// Track connection.blocked so publishes fail fast instead of waiting for a confirm that will not come
+	blocks := conn.NotifyBlocked(make(chan amqp.Blocking, 1))
+	go func() {
+		for b := range blocks {
+			p.blocked.Store(b.Active)
+			if b.Active {
+				slog.Warn("RabbitMQ blocked publishing", "reason", b.Reason)
+			} else {
+				slog.Info("RabbitMQ unblocked publishing")
+			}
+		}
+	}()
+
+	return p, nil
+}
+
+// openChannel opens a new channel in confirm mode and registers the return listener.
+// Must be called with p.mu held (or before the publisher is shared).
+func (p *Publisher) openChannel() error {
+	channel, err := p.conn.Channel()
+	if err != nil {
+		return fmt.Errorf("failed to open channel: %w", err)
+	}
+
+	if err := channel.Confirm(false); err != nil {
+		channel.Close()
+		return fmt.Errorf("failed to enable confirm mode: %w", err)
+	}
+
+	p.channel = &confirmChannel{
+		Channel: channel,
+		returns: channel.NotifyReturn(make(chan amqp.Return, 64)),
+	}
+	return nil
+}
+
+// Publish publishes a response message to the response queue and waits for the broker confirm
 func (p *Publisher) Publish(ctx context.Context, response *ResponseMessage) error {
+	return p.PublishTo(ctx, "", response)
+}
+
+// PublishTo publishes a response message to queueName through the default exchange and waits
+// for the broker confirm. An empty queueName publishes to the response queue. Direct reply-to
+// (amq.rabbitmq.reply-to) names are supported as well.
+func (p *Publisher) PublishTo(ctx context.Context, queueName string, response *ResponseMessage) error {
+	if queueName == "" {
+		queueName = p.queueName
+	}
+
 	body, err := json.Marshal(response)
 	if err != nil {
 		return fmt.Errorf("failed to marshal response: %w", err)
 	}
 
-	err = p.channel.PublishWithContext(
+	err = p.publish(ctx, "", queueName, amqp.Publishing{
+		DeliveryMode:  amqp.Persistent,
+		ContentType:   "application/json",
+		CorrelationId: response.CorrelationID,
+		MessageId:     NewEventID(),
+		Body:          body,
+	})
+	if err != nil {
+		return err
+	}
+
+	slog.Debug("Published response", "queue", queueName, "correlation_id", response.CorrelationID, "success", response.Success)
+	return nil
+}
+
+// publish publishes msg as mandatory and waits for the confirm, reopening the channel
+// and trying again if it is lost in between
+func (p *Publisher) publish(ctx context.Context, exchange, key string, msg amqp.Publishing) error {
+	if p.blocked.Load() {
+		return ErrPublisherBlocked
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, publishConfirmTimeout)
+	defer cancel()
+
+	var lastErr error
+	for attempt := 1; attempt <= publishMaxAttempts; attempt++ {
+		err := p.publishOnce(ctx, exchange, key, msg)
+		if !errors.Is(err, errChannelLost) {
+			return err
+		}
+
+		lastErr = err
+		slog.Warn("Publisher channel lost, retrying publish", "routing_key", key, "attempt", attempt)
+	}
+
+	return fmt.Errorf("failed to publish message after %d attempts: %w", publishMaxAttempts, lastErr)
+}
+
+// publishOnce publishes msg on the current channel and waits for its confirm.
+// Only the publish itself holds p.mu; the confirm is awaited without it.
+func (p *Publisher) publishOnce(ctx context.Context, exchange, key string, msg amqp.Publishing) error {
+	ch, pending, confirm, err := p.send(ctx, exchange, key, msg)
+	if err != nil {
+		return err
+	}
+
+	acked, err := confirm.WaitContext(ctx)
+
+	// The broker sends basic.return before the confirm, so a return for this publish is already
+	// buffered or was routed to it by another publish
+	ret := ch.takeReturn(pending)
+	if err != nil {
+		return fmt.Errorf("failed to wait for publish confirm: %w", err)
+	}
+
+	if ret != nil {
+		slog.Warn("Message returned by broker",
+			"routing_key", ret.RoutingKey,
+			"reply_code", ret.ReplyCode,
+			"reply_text", ret.ReplyText,
+			"correlation_id", ret.CorrelationId,
+		)
+		return fmt.Errorf("%w: %s (%d)", ErrResponseUnroutable, ret.ReplyText, ret.ReplyCode)
+	}
+
+	if !acked {
+		if ch.IsClosed() {
+			return errChannelLost
+		}
+		return fmt.Errorf("publish was nacked by the broker")
+	}
+
+	return nil
+}
+
+// send publishes msg on the current channel, reopening it if it was closed, and registers the
+// publish so its return can be matched
+func (p *Publisher) send(ctx context.Context, exchange, key string, msg amqp.Publishing) (*confirmChannel, *pendingPublish, Confirmation, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.channel == nil || p.channel.IsClosed() {
+		if err := p.openChannel(); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to reopen publisher channel: %w", err)
+		}
+		slog.Info("Publisher channel reopened")
+	}
+	ch := p.channel
+
+	// Register before publishing, since another publish may drain the return first
+	pending := ch.register(exchange, key, msg.MessageId)
+
+	confirm, err := ch.PublishWithDeferredConfirmWithContext(
 		ctx,
-		"",          // exchange
-		p.queueName, // routing key
-		false,       // mandatory
-		false,       // immediate
-		amqp.Publishing{
-			DeliveryMode: amqp.Persistent,
-			ContentType:  "application/json",
-			Body:         body,
-		},
+		exchange, // exchange
+		key,      // routing key
+		true,     // mandatory
+		false,    // immediate
+		msg,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to publish message: %w", err)
+		ch.takeReturn(pending)
+		if ch.IsClosed() {
+			return nil, nil, nil, fmt.Errorf("%w: %v", errChannelLost, err)
+		}
+		return nil, nil, nil, fmt.Errorf("failed to publish message: %w", err)
 	}
 
-	slog.Debug("Published response", "correlation_id", response.CorrelationID, "success", response.Success)
-	return nil
+	return ch, pending, confirm, nil
+}
+
+// register adds a publish to the publishes awaiting their confirm
+func (ch *confirmChannel) register(exchange, key, messageID string) *pendingPublish {
+	pending := &pendingPublish{exchange: exchange, key: key, messageID: messageID}
+
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	ch.pending = append(ch.pending, pending)
+	return pending
+}
+
+// takeReturn drains buffered returns into the pending publishes they belong to, unregisters
+// pending and reports its return, if any
+func (ch *confirmChannel) takeReturn(pending *pendingPublish) *amqp.Return {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	ch.drainReturns()
+
+	for i, other := range ch.pending {
+		if other == pending {
+			ch.pending = append(ch.pending[:i], ch.pending[i+1:]...)
+			break
+		}
+	}
+	return pending.returned
+}
+
+// drainReturns matches buffered returns to pending publishes. Returns arrive in publish order,
+// so a return belongs to the oldest matching publish that has none yet. Must be called with ch.mu held.
+func (ch *confirmChannel) drainReturns() {
+	for {
+		select {
+		case ret, ok := <-ch.returns:
+			if !ok {
+				return
+			}
+			if !ch.routeReturn(ret) {
+				slog.Warn("Discarding stale returned message", "message_id", ret.MessageId, "reply_text", ret.ReplyText)
+			}
+		default:
+			return
+		}
+	}
+}
+
+// routeReturn attaches ret to the publish it belongs to and reports whether there was one
+func (ch *confirmChannel) routeReturn(ret amqp.Return) bool {
+	for _, pending := range ch.pending {
+		if pending.returned == nil &&
+			pending.exchange == ret.Exchange &&
+			pending.key == ret.RoutingKey &&
+			pending.messageID == ret.MessageId {
+			pending.returned = &ret
+			return true
+		}
+	}
+	return false
 }
 
 // Close closes the publisher channel
 func (p *Publisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
 	if p.channel != nil {
 		return p.channel.Close()
 	}
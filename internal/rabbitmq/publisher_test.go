@@ -0,0 +1,141 @@
+package rabbitmq_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gamers-bot/internal/rabbitmq"
+	"github.com/gamers-bot/internal/rabbitmq/rabbitmqtest"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const testResponseQueue = "test.responses"
+
+// gatedConnection holds back the confirms of publishes to one routing key until release is closed
+type gatedConnection struct {
+	rabbitmq.Connection
+	key     string
+	release chan struct{}
+}
+
+func (c *gatedConnection) Channel() (rabbitmq.Channel, error) {
+	ch, err := c.Connection.Channel()
+	if err != nil {
+		return nil, err
+	}
+	return &gatedChannel{Channel: ch, conn: c}, nil
+}
+
+type gatedChannel struct {
+	rabbitmq.Channel
+	conn *gatedConnection
+}
+
+func (ch *gatedChannel) PublishWithDeferredConfirmWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) (rabbitmq.Confirmation, error) {
+	confirm, err := ch.Channel.PublishWithDeferredConfirmWithContext(ctx, exchange, key, mandatory, immediate, msg)
+	if err != nil || key != ch.conn.key {
+		return confirm, err
+	}
+	return gatedConfirmation{Confirmation: confirm, release: ch.conn.release}, nil
+}
+
+type gatedConfirmation struct {
+	rabbitmq.Confirmation
+	release chan struct{}
+}
+
+func (c gatedConfirmation) WaitContext(ctx context.Context) (bool, error) {
+	select {
+	case <-c.release:
+		return c.Confirmation.WaitContext(ctx)
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+// newGatedPublisher returns a publisher whose publishes to slowKey wait for their confirm until release is closed
+func newGatedPublisher(t *testing.T, broker *rabbitmqtest.Broker, slowKey string) (*rabbitmq.Publisher, chan struct{}) {
+	t.Helper()
+	conn, err := broker.Dial("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	release := make(chan struct{})
+	publisher, err := rabbitmq.NewPublisher(&gatedConnection{Connection: conn, key: slowKey, release: release}, testResponseQueue, false)
+	if err != nil {
+		t.Fatalf("NewPublisher: %v", err)
+	}
+	t.Cleanup(func() { publisher.Close() })
+	return publisher, release
+}
+
+// publishAsync publishes a response to queueName in the background and returns its result
+func publishAsync(publisher *rabbitmq.Publisher, queueName string) <-chan error {
+	result := make(chan error, 1)
+	go func() {
+		result <- publisher.PublishTo(context.Background(), queueName, &rabbitmq.ResponseMessage{CorrelationID: queueName, Success: true})
+	}()
+	return result
+}
+
+func TestPublishDoesNotWaitForOtherConfirms(t *testing.T) {
+	broker := rabbitmqtest.NewBroker()
+	publisher, release := newGatedPublisher(t, broker, "test.slow")
+	defer close(release)
+
+	// Declare the slow queue through a second publisher so that the slow publish is routable
+	slowConn, err := broker.Dial("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer slowConn.Close()
+	slowQueue, err := rabbitmq.NewPublisher(slowConn, "test.slow", false)
+	if err != nil {
+		t.Fatalf("NewPublisher: %v", err)
+	}
+	defer slowQueue.Close()
+
+	slow := publishAsync(publisher, "test.slow")
+	waitFor(t, "slow publish to be sent", func() bool { return len(broker.Messages("test.slow")) == 1 })
+
+	select {
+	case err := <-publishAsync(publisher, testResponseQueue):
+		if err != nil {
+			t.Fatalf("PublishTo: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("publish waited for the confirm of another publish")
+	}
+
+	select {
+	case err := <-slow:
+		t.Fatalf("slow publish returned before its confirm: %v", err)
+	default:
+	}
+}
+
+func TestConcurrentPublishesGetTheirOwnReturns(t *testing.T) {
+	broker := rabbitmqtest.NewBroker()
+	publisher, release := newGatedPublisher(t, broker, "test.missing")
+
+	// The unroutable publish is returned while it waits for its confirm
+	unroutable := publishAsync(publisher, "test.missing")
+	time.Sleep(50 * time.Millisecond) // let it be sent first
+
+	// A routable publish drains the buffered return without taking or discarding it
+	if err := <-publishAsync(publisher, testResponseQueue); err != nil {
+		t.Fatalf("routable PublishTo: %v", err)
+	}
+	if n := len(broker.Messages(testResponseQueue)); n != 1 {
+		t.Errorf("%s has %d messages, want 1", testResponseQueue, n)
+	}
+
+	close(release)
+	if err := <-unroutable; !errors.Is(err, rabbitmq.ErrResponseUnroutable) {
+		t.Errorf("unroutable PublishTo error = %v, want ErrResponseUnroutable", err)
+	}
+}
@@ -0,0 +1,644 @@
+// Package rabbitmqtest provides an in-memory AMQP broker implementing rabbitmq.Connection, so the
+// consumers and publishers can run end to end without a RabbitMQ server.
+package rabbitmqtest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gamers-bot/internal/rabbitmq"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Exchange types supported by the broker
+const (
+	ExchangeDirect = "direct"
+	ExchangeFanout = "fanout"
+	ExchangeTopic  = "topic"
+)
+
+// Broker is an in-memory AMQP 0-9-1 broker. It supports direct, fanout and topic exchanges, the
+// default exchange, publisher confirms and mandatory returns, prefetch, ack/nack/reject with requeue,
+// and the queue arguments used by the bot: x-dead-letter-exchange, x-dead-letter-routing-key,
+// x-message-ttl, x-max-length and x-single-active-consumer. Nothing is persisted; every connection
+// shares the same exchanges and queues. It is safe for concurrent use.
+type Broker struct {
+	mu        sync.Mutex
+	exchanges map[string]*exchange
+	queues    map[string]*queue
+	conns     map[*Connection]struct{}
+	dialErr   error
+	nextID    int
+
+	// notify holds listener notifications queued while mu is held; unlock sends them
+	notify []func()
+}
+
+type exchange struct {
+	name     string
+	kind     string
+	bindings []binding
+}
+
+type binding struct {
+	queue string
+	key   string
+}
+
+type queue struct {
+	name      string
+	args      amqp.Table
+	ready     []*message
+	consumers []*consumer
+	next      int // round-robin position in consumers
+	unacked   int
+}
+
+type message struct {
+	exchange    string
+	routingKey  string
+	publishing  amqp.Publishing
+	redelivered bool
+	expiresAt   time.Time // zero if the message does not expire
+}
+
+// Message is a message waiting in a queue
+type Message struct {
+	amqp.Publishing
+	Exchange    string
+	RoutingKey  string
+	Redelivered bool
+}
+
+// NewBroker creates a broker with the default exchange and the amq.direct, amq.fanout and
+// amq.topic exchanges
+func NewBroker() *Broker {
+	b := &Broker{
+		exchanges: make(map[string]*exchange),
+		queues:    make(map[string]*queue),
+		conns:     make(map[*Connection]struct{}),
+	}
+	for name, kind := range map[string]string{
+		"amq.direct": ExchangeDirect,
+		"amq.fanout": ExchangeFanout,
+		"amq.topic":  ExchangeTopic,
+	} {
+		b.exchanges[name] = &exchange{name: name, kind: kind}
+	}
+	return b
+}
+
+// Dial opens a connection to the broker; url is ignored. It can be used as SupervisorConfig.Dial.
+func (b *Broker) Dial(url string) (rabbitmq.Connection, error) {
+	b.mu.Lock()
+	defer b.unlock()
+	if b.dialErr != nil {
+		return nil, b.dialErr
+	}
+	conn := &Connection{broker: b, channels: make(map[*Channel]struct{})}
+	b.conns[conn] = struct{}{}
+	return conn, nil
+}
+
+// SetDialError makes Dial fail with err until it is reset with nil
+func (b *Broker) SetDialError(err error) {
+	b.mu.Lock()
+	defer b.unlock()
+	b.dialErr = err
+}
+
+// CloseConnections closes every open connection with err, as if the broker went away.
+// A nil err closes them with a CONNECTION_FORCED error.
+func (b *Broker) CloseConnections(err *amqp.Error) {
+	if err == nil {
+		err = &amqp.Error{Code: amqp.ConnectionForced, Reason: "CONNECTION_FORCED - broker forced connection closure", Server: true}
+	}
+	b.mu.Lock()
+	defer b.unlock()
+	for conn := range b.conns {
+		b.closeConnection(conn, err)
+	}
+}
+
+// Block sends a connection.blocked notification to every open connection
+func (b *Broker) Block(reason string) {
+	b.notifyBlocked(amqp.Blocking{Active: true, Reason: reason})
+}
+
+// Unblock sends a connection.unblocked notification to every open connection
+func (b *Broker) Unblock() {
+	b.notifyBlocked(amqp.Blocking{Active: false})
+}
+
+func (b *Broker) notifyBlocked(blocking amqp.Blocking) {
+	b.mu.Lock()
+	defer b.unlock()
+	for conn := range b.conns {
+		for _, listener := range conn.blockListeners {
+			b.notify = append(b.notify, func() { listener <- blocking })
+		}
+	}
+}
+
+// Publish publishes msg as a client would. It fails only if the exchange does not exist;
+// unroutable messages are dropped.
+func (b *Broker) Publish(exchangeName, key string, msg amqp.Publishing) error {
+	b.mu.Lock()
+	defer b.unlock()
+	_, err := b.publish(exchangeName, key, msg)
+	return err
+}
+
+// Queues returns the names of all declared queues, sorted
+func (b *Broker) Queues() []string {
+	b.mu.Lock()
+	defer b.unlock()
+	names := make([]string, 0, len(b.queues))
+	for name := range b.queues {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// QueueArguments returns the arguments a queue was declared with, or nil if it does not exist
+func (b *Broker) QueueArguments(queueName string) amqp.Table {
+	b.mu.Lock()
+	defer b.unlock()
+	if q, ok := b.queues[queueName]; ok {
+		return copyTable(q.args)
+	}
+	return nil
+}
+
+// Messages returns the messages waiting in a queue, head first. Unacked messages are not included.
+func (b *Broker) Messages(queueName string) []Message {
+	b.mu.Lock()
+	defer b.unlock()
+	q, ok := b.queues[queueName]
+	if !ok {
+		return nil
+	}
+	messages := make([]Message, 0, len(q.ready))
+	for _, m := range q.ready {
+		pub := m.publishing
+		pub.Headers = copyTable(pub.Headers)
+		messages = append(messages, Message{
+			Publishing:  pub,
+			Exchange:    m.exchange,
+			RoutingKey:  m.routingKey,
+			Redelivered: m.redelivered,
+		})
+	}
+	return messages
+}
+
+// Unacked returns the number of messages of a queue delivered to consumers but not yet settled
+func (b *Broker) Unacked(queueName string) int {
+	b.mu.Lock()
+	defer b.unlock()
+	if q, ok := b.queues[queueName]; ok {
+		return q.unacked
+	}
+	return 0
+}
+
+// Consumers returns the number of consumers attached to a queue
+func (b *Broker) Consumers(queueName string) int {
+	b.mu.Lock()
+	defer b.unlock()
+	if q, ok := b.queues[queueName]; ok {
+		return len(q.consumers)
+	}
+	return 0
+}
+
+// Purge removes the messages waiting in a queue and returns how many were removed
+func (b *Broker) Purge(queueName string) int {
+	b.mu.Lock()
+	defer b.unlock()
+	q, ok := b.queues[queueName]
+	if !ok {
+		return 0
+	}
+	n := len(q.ready)
+	q.ready = nil
+	return n
+}
+
+// WaitForMessages blocks until at least n messages are waiting in a queue or ctx is done
+func (b *Broker) WaitForMessages(ctx context.Context, queueName string, n int) error {
+	ticker := time.NewTicker(5 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		if len(b.Messages(queueName)) >= n {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for %d messages in %s: %w", n, queueName, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// unlock releases mu and then sends the listener notifications queued while it was held,
+// so that slow listeners cannot block the broker
+func (b *Broker) unlock() {
+	notify := b.notify
+	b.notify = nil
+	b.mu.Unlock()
+	for _, fn := range notify {
+		fn()
+	}
+}
+
+// newName returns a server-generated name with the given prefix
+func (b *Broker) newName(prefix string) string {
+	b.nextID++
+	return prefix + strconv.Itoa(b.nextID)
+}
+
+// route returns the queues a message published to an exchange with a routing key is delivered to
+func (b *Broker) route(exchangeName, key string) ([]*queue, error) {
+	if exchangeName == "" {
+		if q, ok := b.queues[key]; ok {
+			return []*queue{q}, nil
+		}
+		return nil, nil
+	}
+
+	ex, ok := b.exchanges[exchangeName]
+	if !ok {
+		return nil, &amqp.Error{Code: amqp.NotFound, Reason: fmt.Sprintf("NOT_FOUND - no exchange '%s' in vhost '/'", exchangeName), Server: true}
+	}
+
+	var queues []*queue
+	for _, bind := range ex.bindings {
+		var match bool
+		switch ex.kind {
+		case ExchangeFanout:
+			match = true
+		case ExchangeTopic:
+			match = topicMatch(strings.Split(bind.key, "."), strings.Split(key, "."))
+		default:
+			match = bind.key == key
+		}
+		if q, ok := b.queues[bind.queue]; ok && match && !slices.Contains(queues, q) {
+			queues = append(queues, q)
+		}
+	}
+	return queues, nil
+}
+
+// topicMatch reports whether the words of a routing key match a binding pattern, where "*"
+// matches exactly one word and "#" matches zero or more words
+func topicMatch(pattern, key []string) bool {
+	if len(pattern) == 0 {
+		return len(key) == 0
+	}
+	switch pattern[0] {
+	case "#":
+		for i := 0; i <= len(key); i++ {
+			if topicMatch(pattern[1:], key[i:]) {
+				return true
+			}
+		}
+		return false
+	case "*":
+		return len(key) > 0 && topicMatch(pattern[1:], key[1:])
+	default:
+		return len(key) > 0 && pattern[0] == key[0] && topicMatch(pattern[1:], key[1:])
+	}
+}
+
+// publish routes msg and enqueues a copy in every matching queue. It reports whether the
+// message was routed to at least one queue.
+func (b *Broker) publish(exchangeName, key string, msg amqp.Publishing) (bool, error) {
+	queues, err := b.route(exchangeName, key)
+	if err != nil {
+		return false, err
+	}
+	for _, q := range queues {
+		b.enqueue(q, newMessage(exchangeName, key, msg))
+	}
+	return len(queues) > 0, nil
+}
+
+// newMessage copies msg, so later changes by the publisher do not affect the queued message
+func newMessage(exchangeName, key string, msg amqp.Publishing) *message {
+	msg.Headers = copyTable(msg.Headers)
+	msg.Body = bytes.Clone(msg.Body)
+	return &message{exchange: exchangeName, routingKey: key, publishing: msg}
+}
+
+// enqueue appends a message to a queue, applying its TTL and length limit, and delivers it
+func (b *Broker) enqueue(q *queue, m *message) {
+	if m.expiresAt.IsZero() {
+		if ttl, ok := messageTTL(q.args, m.publishing.Expiration); ok {
+			m.expiresAt = time.Now().Add(ttl)
+			time.AfterFunc(ttl, func() {
+				b.mu.Lock()
+				defer b.unlock()
+				b.dispatch(q)
+			})
+		}
+	}
+
+	q.ready = append(q.ready, m)
+	if maxLength, ok := intArg(q.args, "x-max-length"); ok {
+		for int64(len(q.ready)) > maxLength {
+			head := q.ready[0]
+			q.ready = q.ready[1:]
+			b.deadLetter(q, head, "maxlen")
+		}
+	}
+	b.dispatch(q)
+}
+
+// messageTTL returns the TTL of a message: the lower of the queue's x-message-ttl and the
+// message's own expiration
+func messageTTL(args amqp.Table, expiration string) (time.Duration, bool) {
+	ttl, ok := intArg(args, "x-message-ttl")
+	if ms, err := strconv.ParseInt(expiration, 10, 64); err == nil && (!ok || ms < ttl) {
+		ttl, ok = ms, true
+	}
+	return time.Duration(ttl) * time.Millisecond, ok
+}
+
+// requeue puts messages back at the head of their queue, in order, marked as redelivered
+func (b *Broker) requeue(q *queue, messages []*message) {
+	for _, m := range messages {
+		m.redelivered = true
+	}
+	q.ready = append(messages, q.ready...)
+}
+
+// expire dead-letters the expired messages at the head of a queue. As in RabbitMQ, only the head
+// is checked, so an expired message behind one that has not expired yet stays in the queue.
+func (b *Broker) expire(q *queue) {
+	now := time.Now()
+	for len(q.ready) > 0 {
+		head := q.ready[0]
+		if head.expiresAt.IsZero() || now.Before(head.expiresAt) {
+			return
+		}
+		q.ready = q.ready[1:]
+		b.deadLetter(q, head, "expired")
+	}
+}
+
+// deadLetter republishes a message removed from a queue to the queue's dead-letter exchange,
+// recording the reason in the x-death header. Without a dead-letter exchange the message is dropped.
+func (b *Broker) deadLetter(q *queue, m *message, reason string) {
+	dlx, ok := q.args["x-dead-letter-exchange"].(string)
+	if !ok {
+		return
+	}
+	key := m.routingKey
+	if k, ok := q.args["x-dead-letter-routing-key"].(string); ok {
+		key = k
+	}
+
+	pub := m.publishing
+	pub.Headers = withDeath(pub.Headers, q.name, reason, m)
+	pub.Expiration = ""
+	// Unroutable dead letters are dropped, as in RabbitMQ
+	_, _ = b.publish(dlx, key, pub)
+}
+
+// withDeath returns a copy of headers with the x-death entry for the queue and reason moved to
+// the front and its count incremented
+func withDeath(headers amqp.Table, queueName, reason string, m *message) amqp.Table {
+	deaths, _ := headers["x-death"].([]interface{})
+	count := int64(0)
+	rest := make([]interface{}, 0, len(deaths))
+	for _, d := range deaths {
+		if t, ok := d.(amqp.Table); ok && t["queue"] == queueName && t["reason"] == reason {
+			count, _ = t["count"].(int64)
+			continue
+		}
+		rest = append(rest, d)
+	}
+
+	death := amqp.Table{
+		"count":        count + 1,
+		"reason":       reason,
+		"queue":        queueName,
+		"time":         time.Now().Truncate(time.Second),
+		"exchange":     m.exchange,
+		"routing-keys": []interface{}{m.routingKey},
+	}
+	out := copyTable(headers)
+	if out == nil {
+		out = amqp.Table{}
+	}
+	out["x-death"] = append([]interface{}{death}, rest...)
+	return out
+}
+
+// dispatch expires messages and hands waiting messages to consumers with spare prefetch capacity
+func (b *Broker) dispatch(q *queue) {
+	for {
+		b.expire(q)
+		if len(q.ready) == 0 {
+			return
+		}
+		c := q.nextConsumer()
+		if c == nil {
+			return
+		}
+		m := q.ready[0]
+		q.ready = q.ready[1:]
+		c.deliver(m)
+	}
+}
+
+// nextConsumer returns the consumer to deliver the next message to, or nil if none can take it.
+// Single active consumer queues only deliver to their oldest consumer.
+func (q *queue) nextConsumer() *consumer {
+	if len(q.consumers) == 0 {
+		return nil
+	}
+	if singleActive, _ := q.args["x-single-active-consumer"].(bool); singleActive {
+		if c := q.consumers[0]; c.hasCapacity() {
+			return c
+		}
+		return nil
+	}
+	for i := range q.consumers {
+		idx := (q.next + i) % len(q.consumers)
+		if c := q.consumers[idx]; c.hasCapacity() {
+			q.next = (idx + 1) % len(q.consumers)
+			return c
+		}
+	}
+	return nil
+}
+
+// removeConsumer detaches a consumer from its queue
+func (q *queue) removeConsumer(c *consumer) {
+	if i := slices.Index(q.consumers, c); i >= 0 {
+		q.consumers = slices.Delete(q.consumers, i, i+1)
+		if q.next > i {
+			q.next--
+		}
+		if q.next >= len(q.consumers) {
+			q.next = 0
+		}
+	}
+}
+
+// info returns the queue as reported by queue.declare-ok
+func (q *queue) info() amqp.Queue {
+	return amqp.Queue{Name: q.name, Messages: len(q.ready), Consumers: len(q.consumers)}
+}
+
+// closeConnection closes a connection and its channels
+func (b *Broker) closeConnection(conn *Connection, err *amqp.Error) {
+	if conn.closed {
+		return
+	}
+	conn.closed = true
+	delete(b.conns, conn)
+	for ch := range conn.channels {
+		b.closeChannel(ch, err)
+	}
+
+	closeListeners, blockListeners := conn.closeListeners, conn.blockListeners
+	conn.closeListeners, conn.blockListeners = nil, nil
+	b.notify = append(b.notify, func() {
+		for _, listener := range closeListeners {
+			if err != nil {
+				listener <- err
+			}
+			close(listener)
+		}
+		for _, listener := range blockListeners {
+			close(listener)
+		}
+	})
+}
+
+// closeChannel closes a channel: its consumers are cancelled and its unacked messages requeued
+func (b *Broker) closeChannel(ch *Channel, err *amqp.Error) {
+	if ch.closed {
+		return
+	}
+	ch.closed = true
+	delete(ch.conn.channels, ch)
+
+	affected := make(map[*queue]bool)
+	for _, c := range ch.consumers {
+		c.queue.removeConsumer(c)
+		close(c.done)
+		affected[c.queue] = true
+	}
+	ch.consumers = nil
+
+	tags := make([]uint64, 0, len(ch.unacked))
+	for tag := range ch.unacked {
+		tags = append(tags, tag)
+	}
+	slices.Sort(tags)
+	requeued := make(map[*queue][]*message)
+	for _, tag := range tags {
+		d := ch.unacked[tag]
+		d.queue.unacked--
+		requeued[d.queue] = append(requeued[d.queue], d.message)
+	}
+	ch.unacked = nil
+	for q, messages := range requeued {
+		b.requeue(q, messages)
+		affected[q] = true
+	}
+	for q := range affected {
+		b.dispatch(q)
+	}
+
+	closeListeners, returnListeners := ch.closeListeners, ch.returnListeners
+	ch.closeListeners, ch.returnListeners = nil, nil
+	b.notify = append(b.notify, func() {
+		for _, listener := range closeListeners {
+			if err != nil {
+				listener <- err
+			}
+			close(listener)
+		}
+		for _, listener := range returnListeners {
+			close(listener)
+		}
+	})
+}
+
+// copyTable returns a shallow copy of t, or nil if t is nil
+func copyTable(t amqp.Table) amqp.Table {
+	if t == nil {
+		return nil
+	}
+	out := make(amqp.Table, len(t))
+	for k, v := range t {
+		out[k] = v
+	}
+	return out
+}
+
+// intArg reads an integer queue argument
+func intArg(args amqp.Table, key string) (int64, bool) {
+	return toInt64(args[key])
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	switch v := v.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// argsEquivalent reports whether the arguments of a redeclared queue match the existing ones.
+// Integers of different types are equal if their values are.
+func argsEquivalent(a, b amqp.Table) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, av := range a {
+		bv, ok := b[k]
+		if !ok {
+			return false
+		}
+		ai, aInt := toInt64(av)
+		bi, bInt := toInt64(bv)
+		if aInt && bInt {
+			if ai != bi {
+				return false
+			}
+			continue
+		}
+		if fmt.Sprint(av) != fmt.Sprint(bv) {
+			return false
+		}
+	}
+	return true
+}
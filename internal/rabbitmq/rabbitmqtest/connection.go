@@ -0,0 +1,584 @@
+package rabbitmqtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gamers-bot/internal/rabbitmq"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+var (
+	_ rabbitmq.Connection = (*Connection)(nil)
+	_ rabbitmq.Channel    = (*Channel)(nil)
+	_ amqp.Acknowledger   = (*Channel)(nil)
+)
+
+// Connection is a client connection to a Broker
+type Connection struct {
+	broker         *Broker
+	channels       map[*Channel]struct{}
+	closed         bool
+	closeListeners []chan *amqp.Error
+	blockListeners []chan amqp.Blocking
+}
+
+// Channel opens a channel
+func (c *Connection) Channel() (rabbitmq.Channel, error) {
+	b := c.broker
+	b.mu.Lock()
+	defer b.unlock()
+	if c.closed {
+		return nil, amqp.ErrClosed
+	}
+	ch := &Channel{
+		broker:    b,
+		conn:      c,
+		consumers: make(map[string]*consumer),
+		unacked:   make(map[uint64]*unackedDelivery),
+	}
+	c.channels[ch] = struct{}{}
+	return ch, nil
+}
+
+// NotifyClose registers a listener for the connection closing. A graceful close closes the
+// listener without sending an error.
+func (c *Connection) NotifyClose(receiver chan *amqp.Error) chan *amqp.Error {
+	c.broker.mu.Lock()
+	defer c.broker.unlock()
+	if c.closed {
+		close(receiver)
+	} else {
+		c.closeListeners = append(c.closeListeners, receiver)
+	}
+	return receiver
+}
+
+// NotifyBlocked registers a listener for connection.blocked and connection.unblocked, see Broker.Block
+func (c *Connection) NotifyBlocked(receiver chan amqp.Blocking) chan amqp.Blocking {
+	c.broker.mu.Lock()
+	defer c.broker.unlock()
+	if c.closed {
+		close(receiver)
+	} else {
+		c.blockListeners = append(c.blockListeners, receiver)
+	}
+	return receiver
+}
+
+// Close closes the connection and all its channels
+func (c *Connection) Close() error {
+	c.broker.mu.Lock()
+	defer c.broker.unlock()
+	if c.closed {
+		return amqp.ErrClosed
+	}
+	c.broker.closeConnection(c, nil)
+	return nil
+}
+
+// Channel is a channel on a Connection. It is the Acknowledger of the deliveries it receives.
+type Channel struct {
+	broker *Broker
+	conn   *Connection
+	closed bool
+
+	prefetch       int // per consumer, applied to consumers started afterwards
+	globalPrefetch int // per channel
+	confirm        bool
+
+	nextTag   uint64
+	consumers map[string]*consumer
+	unacked   map[uint64]*unackedDelivery
+
+	closeListeners  []chan *amqp.Error
+	returnListeners []chan amqp.Return
+}
+
+type unackedDelivery struct {
+	message  *message
+	queue    *queue
+	consumer *consumer
+}
+
+// consumer pushes the messages dispatched to it onto its delivery channel from its own goroutine,
+// so the broker never blocks on a slow client
+type consumer struct {
+	tag       string
+	queue     *queue
+	channel   *Channel
+	autoAck   bool
+	exclusive bool
+	prefetch  int
+	unacked   int
+
+	pending    []amqp.Delivery
+	deliveries chan amqp.Delivery
+	signal     chan struct{}
+	done       chan struct{}
+}
+
+// fail closes the channel with a channel exception and returns it
+func (ch *Channel) fail(code int, format string, args ...interface{}) error {
+	err := &amqp.Error{Code: code, Reason: fmt.Sprintf(format, args...), Server: true}
+	ch.broker.closeChannel(ch, err)
+	return err
+}
+
+// Qos sets the prefetch count of consumers started afterwards, or of the whole channel if global is set.
+// prefetchSize is ignored.
+func (ch *Channel) Qos(prefetchCount, prefetchSize int, global bool) error {
+	ch.broker.mu.Lock()
+	defer ch.broker.unlock()
+	if ch.closed {
+		return amqp.ErrClosed
+	}
+	if global {
+		ch.globalPrefetch = prefetchCount
+	} else {
+		ch.prefetch = prefetchCount
+	}
+	return nil
+}
+
+// Confirm puts the channel in confirm mode. Every publish is confirmed as soon as it is routed.
+func (ch *Channel) Confirm(noWait bool) error {
+	ch.broker.mu.Lock()
+	defer ch.broker.unlock()
+	if ch.closed {
+		return amqp.ErrClosed
+	}
+	ch.confirm = true
+	return nil
+}
+
+// ExchangeDeclare declares a direct, fanout or topic exchange
+func (ch *Channel) ExchangeDeclare(name, kind string, durable, autoDelete, internal, noWait bool, args amqp.Table) error {
+	b := ch.broker
+	b.mu.Lock()
+	defer b.unlock()
+	if ch.closed {
+		return amqp.ErrClosed
+	}
+	if ex, ok := b.exchanges[name]; ok {
+		if ex.kind != kind {
+			return ch.fail(amqp.PreconditionFailed, "PRECONDITION_FAILED - inequivalent arg 'type' for exchange '%s' in vhost '/': received '%s' but current is '%s'", name, kind, ex.kind)
+		}
+		return nil
+	}
+	if name == "" || strings.HasPrefix(name, "amq.") {
+		return ch.fail(amqp.AccessRefused, "ACCESS_REFUSED - exchange name '%s' contains reserved prefix 'amq.*'", name)
+	}
+	if kind != ExchangeDirect && kind != ExchangeFanout && kind != ExchangeTopic {
+		return ch.fail(amqp.CommandInvalid, "COMMAND_INVALID - unknown exchange type '%s'", kind)
+	}
+	b.exchanges[name] = &exchange{name: name, kind: kind}
+	return nil
+}
+
+// QueueDeclare declares a queue. An empty name declares a queue with a generated name.
+// Redeclaring a queue with different arguments fails with PRECONDITION_FAILED.
+func (ch *Channel) QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (amqp.Queue, error) {
+	b := ch.broker
+	b.mu.Lock()
+	defer b.unlock()
+	if ch.closed {
+		return amqp.Queue{}, amqp.ErrClosed
+	}
+	if name == "" {
+		name = b.newName("amq.gen-")
+	}
+	if q, ok := b.queues[name]; ok {
+		if !argsEquivalent(q.args, args) {
+			return amqp.Queue{}, ch.fail(amqp.PreconditionFailed, "PRECONDITION_FAILED - inequivalent args for queue '%s' in vhost '/'", name)
+		}
+		return q.info(), nil
+	}
+	q := &queue{name: name, args: copyTable(args)}
+	b.queues[name] = q
+	return q.info(), nil
+}
+
+// QueueDeclarePassive returns an existing queue; a missing queue fails with NOT_FOUND
+func (ch *Channel) QueueDeclarePassive(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (amqp.Queue, error) {
+	b := ch.broker
+	b.mu.Lock()
+	defer b.unlock()
+	if ch.closed {
+		return amqp.Queue{}, amqp.ErrClosed
+	}
+	q, ok := b.queues[name]
+	if !ok {
+		return amqp.Queue{}, ch.fail(amqp.NotFound, "NOT_FOUND - no queue '%s' in vhost '/'", name)
+	}
+	return q.info(), nil
+}
+
+// QueueBind binds a queue to an exchange
+func (ch *Channel) QueueBind(name, key, exchangeName string, noWait bool, args amqp.Table) error {
+	b := ch.broker
+	b.mu.Lock()
+	defer b.unlock()
+	if ch.closed {
+		return amqp.ErrClosed
+	}
+	if exchangeName == "" {
+		return ch.fail(amqp.AccessRefused, "ACCESS_REFUSED - operation not permitted on the default exchange")
+	}
+	if _, ok := b.queues[name]; !ok {
+		return ch.fail(amqp.NotFound, "NOT_FOUND - no queue '%s' in vhost '/'", name)
+	}
+	ex, ok := b.exchanges[exchangeName]
+	if !ok {
+		return ch.fail(amqp.NotFound, "NOT_FOUND - no exchange '%s' in vhost '/'", exchangeName)
+	}
+	bind := binding{queue: name, key: key}
+	if !slices.Contains(ex.bindings, bind) {
+		ex.bindings = append(ex.bindings, bind)
+	}
+	return nil
+}
+
+// Consume starts a consumer. The delivery channel is closed when the consumer is cancelled or
+// the channel closes. noLocal, noWait and args are ignored.
+func (ch *Channel) Consume(queueName, consumerTag string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error) {
+	b := ch.broker
+	b.mu.Lock()
+	defer b.unlock()
+	if ch.closed {
+		return nil, amqp.ErrClosed
+	}
+	q, ok := b.queues[queueName]
+	if !ok {
+		return nil, ch.fail(amqp.NotFound, "NOT_FOUND - no queue '%s' in vhost '/'", queueName)
+	}
+	if consumerTag == "" {
+		consumerTag = b.newName("ctag-")
+	}
+	if _, ok := ch.consumers[consumerTag]; ok {
+		return nil, ch.fail(amqp.NotAllowed, "NOT_ALLOWED - attempt to reuse consumer tag '%s'", consumerTag)
+	}
+	for _, other := range q.consumers {
+		if exclusive || other.exclusive {
+			return nil, ch.fail(amqp.AccessRefused, "ACCESS_REFUSED - queue '%s' in vhost '/' in exclusive use", queueName)
+		}
+	}
+
+	c := &consumer{
+		tag:        consumerTag,
+		queue:      q,
+		channel:    ch,
+		autoAck:    autoAck,
+		exclusive:  exclusive,
+		prefetch:   ch.prefetch,
+		deliveries: make(chan amqp.Delivery),
+		signal:     make(chan struct{}, 1),
+		done:       make(chan struct{}),
+	}
+	ch.consumers[consumerTag] = c
+	q.consumers = append(q.consumers, c)
+	go c.run(&b.mu)
+	b.dispatch(q)
+	return c.deliveries, nil
+}
+
+// Cancel stops a consumer. Its unacked deliveries stay unacked until settled or the channel closes.
+func (ch *Channel) Cancel(consumerTag string, noWait bool) error {
+	b := ch.broker
+	b.mu.Lock()
+	defer b.unlock()
+	if ch.closed {
+		return amqp.ErrClosed
+	}
+	c, ok := ch.consumers[consumerTag]
+	if !ok {
+		return nil
+	}
+	delete(ch.consumers, consumerTag)
+	c.queue.removeConsumer(c)
+	close(c.done)
+	b.dispatch(c.queue)
+	return nil
+}
+
+// PublishWithContext publishes a message. Unroutable mandatory messages are returned to the
+// NotifyReturn listeners; publishing to a missing exchange closes the channel with NOT_FOUND.
+// immediate is ignored.
+func (ch *Channel) PublishWithContext(ctx context.Context, exchangeName, key string, mandatory, immediate bool, msg amqp.Publishing) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	b := ch.broker
+	b.mu.Lock()
+	defer b.unlock()
+	if ch.closed {
+		return amqp.ErrClosed
+	}
+
+	routed, err := b.publish(exchangeName, key, msg)
+	if err != nil {
+		var amqpErr *amqp.Error
+		if errors.As(err, &amqpErr) {
+			b.closeChannel(ch, amqpErr)
+		}
+		return err
+	}
+	if !routed && mandatory {
+		ret := newReturn(exchangeName, key, msg)
+		for _, listener := range ch.returnListeners {
+			b.notify = append(b.notify, func() { listener <- ret })
+		}
+	}
+	return nil
+}
+
+// PublishWithDeferredConfirmWithContext publishes a message on a channel in confirm mode.
+// The returned confirmation is already acked; a return for the message is sent before it.
+func (ch *Channel) PublishWithDeferredConfirmWithContext(ctx context.Context, exchangeName, key string, mandatory, immediate bool, msg amqp.Publishing) (rabbitmq.Confirmation, error) {
+	ch.broker.mu.Lock()
+	confirm := ch.confirm
+	ch.broker.unlock()
+	if !confirm {
+		return nil, fmt.Errorf("channel is not in confirm mode")
+	}
+
+	if err := ch.PublishWithContext(ctx, exchangeName, key, mandatory, immediate, msg); err != nil {
+		return nil, err
+	}
+	return confirmation{acked: true}, nil
+}
+
+// confirmation is a publish confirm that has already arrived
+type confirmation struct {
+	acked bool
+}
+
+// WaitContext implements rabbitmq.Confirmation
+func (c confirmation) WaitContext(ctx context.Context) (bool, error) {
+	return c.acked, nil
+}
+
+// newReturn builds the basic.return for an unroutable message
+func newReturn(exchangeName, key string, msg amqp.Publishing) amqp.Return {
+	return amqp.Return{
+		ReplyCode:       amqp.NoRoute,
+		ReplyText:       "NO_ROUTE",
+		Exchange:        exchangeName,
+		RoutingKey:      key,
+		ContentType:     msg.ContentType,
+		ContentEncoding: msg.ContentEncoding,
+		Headers:         copyTable(msg.Headers),
+		DeliveryMode:    msg.DeliveryMode,
+		Priority:        msg.Priority,
+		CorrelationId:   msg.CorrelationId,
+		ReplyTo:         msg.ReplyTo,
+		Expiration:      msg.Expiration,
+		MessageId:       msg.MessageId,
+		Timestamp:       msg.Timestamp,
+		Type:            msg.Type,
+		UserId:          msg.UserId,
+		AppId:           msg.AppId,
+		Body:            msg.Body,
+	}
+}
+
+// NotifyClose registers a listener for the channel closing. A graceful close closes the
+// listener without sending an error.
+func (ch *Channel) NotifyClose(receiver chan *amqp.Error) chan *amqp.Error {
+	ch.broker.mu.Lock()
+	defer ch.broker.unlock()
+	if ch.closed {
+		close(receiver)
+	} else {
+		ch.closeListeners = append(ch.closeListeners, receiver)
+	}
+	return receiver
+}
+
+// NotifyReturn registers a listener for unroutable mandatory messages
+func (ch *Channel) NotifyReturn(receiver chan amqp.Return) chan amqp.Return {
+	ch.broker.mu.Lock()
+	defer ch.broker.unlock()
+	if ch.closed {
+		close(receiver)
+	} else {
+		ch.returnListeners = append(ch.returnListeners, receiver)
+	}
+	return receiver
+}
+
+// IsClosed reports whether the channel is closed
+func (ch *Channel) IsClosed() bool {
+	ch.broker.mu.Lock()
+	defer ch.broker.unlock()
+	return ch.closed
+}
+
+// Close closes the channel; its consumers are cancelled and its unacked messages requeued
+func (ch *Channel) Close() error {
+	ch.broker.mu.Lock()
+	defer ch.broker.unlock()
+	ch.broker.closeChannel(ch, nil)
+	return nil
+}
+
+// settlement is how a delivery is settled
+type settlement int
+
+const (
+	settleAck settlement = iota
+	settleRequeue
+	settleDeadLetter
+)
+
+// Ack implements amqp.Acknowledger
+func (ch *Channel) Ack(tag uint64, multiple bool) error {
+	return ch.settle(tag, multiple, settleAck)
+}
+
+// Nack implements amqp.Acknowledger. Messages that are not requeued are dead-lettered.
+func (ch *Channel) Nack(tag uint64, multiple bool, requeue bool) error {
+	if requeue {
+		return ch.settle(tag, multiple, settleRequeue)
+	}
+	return ch.settle(tag, multiple, settleDeadLetter)
+}
+
+// Reject implements amqp.Acknowledger
+func (ch *Channel) Reject(tag uint64, requeue bool) error {
+	return ch.Nack(tag, false, requeue)
+}
+
+// settle settles the delivery with the given tag or, with multiple, all deliveries up to and
+// including it (all of them for tag 0). An unknown tag closes the channel with
+// PRECONDITION_FAILED, as in RabbitMQ.
+func (ch *Channel) settle(tag uint64, multiple bool, how settlement) error {
+	b := ch.broker
+	b.mu.Lock()
+	defer b.unlock()
+	if ch.closed {
+		return amqp.ErrClosed
+	}
+
+	var tags []uint64
+	if multiple {
+		for t := range ch.unacked {
+			if tag == 0 || t <= tag {
+				tags = append(tags, t)
+			}
+		}
+		slices.Sort(tags)
+	} else if _, ok := ch.unacked[tag]; ok {
+		tags = []uint64{tag}
+	}
+	if len(tags) == 0 && (tag != 0 || !multiple) {
+		return ch.fail(amqp.PreconditionFailed, "PRECONDITION_FAILED - unknown delivery tag %d", tag)
+	}
+
+	affected := make(map[*queue]bool)
+	requeued := make(map[*queue][]*message)
+	for _, t := range tags {
+		d := ch.unacked[t]
+		delete(ch.unacked, t)
+		d.consumer.unacked--
+		d.queue.unacked--
+		affected[d.queue] = true
+
+		switch how {
+		case settleRequeue:
+			requeued[d.queue] = append(requeued[d.queue], d.message)
+		case settleDeadLetter:
+			b.deadLetter(d.queue, d.message, "rejected")
+		}
+	}
+	for q, messages := range requeued {
+		b.requeue(q, messages)
+	}
+	for q := range affected {
+		b.dispatch(q)
+	}
+	return nil
+}
+
+// hasCapacity reports whether the consumer's prefetch limits allow another delivery
+func (c *consumer) hasCapacity() bool {
+	if c.autoAck {
+		return true
+	}
+	if c.prefetch > 0 && c.unacked >= c.prefetch {
+		return false
+	}
+	ch := c.channel
+	return ch.globalPrefetch == 0 || len(ch.unacked) < ch.globalPrefetch
+}
+
+// deliver assigns a message to the consumer and wakes its goroutine
+func (c *consumer) deliver(m *message) {
+	ch := c.channel
+	ch.nextTag++
+	if !c.autoAck {
+		ch.unacked[ch.nextTag] = &unackedDelivery{message: m, queue: c.queue, consumer: c}
+		c.unacked++
+		c.queue.unacked++
+	}
+
+	pub := m.publishing
+	c.pending = append(c.pending, amqp.Delivery{
+		Acknowledger:    ch,
+		Headers:         copyTable(pub.Headers),
+		ContentType:     pub.ContentType,
+		ContentEncoding: pub.ContentEncoding,
+		DeliveryMode:    pub.DeliveryMode,
+		Priority:        pub.Priority,
+		CorrelationId:   pub.CorrelationId,
+		ReplyTo:         pub.ReplyTo,
+		Expiration:      pub.Expiration,
+		MessageId:       pub.MessageId,
+		Timestamp:       pub.Timestamp,
+		Type:            pub.Type,
+		UserId:          pub.UserId,
+		AppId:           pub.AppId,
+		ConsumerTag:     c.tag,
+		DeliveryTag:     ch.nextTag,
+		Redelivered:     m.redelivered,
+		Exchange:        m.exchange,
+		RoutingKey:      m.routingKey,
+		Body:            pub.Body,
+	})
+	select {
+	case c.signal <- struct{}{}:
+	default:
+	}
+}
+
+// run sends pending deliveries to the client until the consumer is cancelled
+func (c *consumer) run(mu *sync.Mutex) {
+	defer close(c.deliveries)
+	for {
+		select {
+		case <-c.signal:
+		case <-c.done:
+			return
+		}
+		for {
+			mu.Lock()
+			if len(c.pending) == 0 {
+				mu.Unlock()
+				break
+			}
+			d := c.pending[0]
+			c.pending = c.pending[1:]
+			mu.Unlock()
+
+			select {
+			case c.deliveries <- d:
+			case <-c.done:
+				return
+			}
+		}
+	}
+}
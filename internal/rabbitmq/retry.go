@@ -0,0 +1,266 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/gamers-bot/internal/bot"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// RetryCountHeader is the AMQP header that tracks how many times a delivery has been retried
+const RetryCountHeader = "x-retry-count"
+
+// RetryPolicy controls how failed deliveries are retried before being parked in a dead-letter queue.
+// Each attempt waits in its own delay queue (<queue>.retry.<n>) whose TTL doubles per attempt,
+// after which the broker dead-letters the message back to the source queue.
+type RetryPolicy struct {
+	DeadLetterExchange string
+	MaxAttempts        int
+	BaseDelay          time.Duration
+}
+
+// DefaultRetryPolicy returns the retry policy used when none is configured
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		DeadLetterExchange: "gamers.dlx",
+		MaxAttempts:        3,
+		BaseDelay:          5 * time.Second,
+	}
+}
+
+// Delay returns the backoff delay for the given attempt (1-based): BaseDelay * 2^(attempt-1)
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	return p.BaseDelay * time.Duration(1<<(attempt-1))
+}
+
+// DeadLetterQueueName returns the name of the DLQ for a queue
+func DeadLetterQueueName(queueName string) string {
+	return queueName + ".dlq"
+}
+
+// retryQueueName returns the name of the delay queue for a queue and attempt
+func retryQueueName(queueName string, attempt int) string {
+	return fmt.Sprintf("%s.retry.%d", queueName, attempt)
+}
+
+// declareDeadLetterExchange declares the dead-letter exchange shared by all bot queues
+func (cm *ConsumerManager) declareDeadLetterExchange(ch Channel) error {
+	return declareDirectExchange(ch, cm.retryPolicy.DeadLetterExchange)
+}
+
+// declareDirectExchange declares a durable direct exchange used for dead-lettering
+func declareDirectExchange(ch Channel, name string) error {
+	err := ch.ExchangeDeclare(
+		name,     // name
+		"direct", // type
+		true,     // durable
+		false,    // auto-deleted
+		false,    // internal
+		false,    // no-wait
+		nil,      // arguments
+	)
+	if err != nil {
+		return fmt.Errorf("failed to declare dead-letter exchange %s: %w", name, err)
+	}
+	return nil
+}
+
+// declareQueueWithRetry declares a work queue together with its DLQ and delay queues.
+// args are additional work queue arguments (queue type, length limit, TTL). dlx overrides the
+// dead-letter exchange of the retry policy; the retry policy's exchange must already be declared.
+func (cm *ConsumerManager) declareQueueWithRetry(ch Channel, queueName string, args amqp.Table, dlx string) error {
+	if dlx == "" {
+		dlx = cm.retryPolicy.DeadLetterExchange
+	} else if dlx != cm.retryPolicy.DeadLetterExchange {
+		if err := declareDirectExchange(ch, dlx); err != nil {
+			return err
+		}
+	}
+
+	// Declare the DLQ and bind it to the dead-letter exchange
+	dlqName := DeadLetterQueueName(queueName)
+	if _, err := ch.QueueDeclare(dlqName, true, false, false, false, cm.replicatedArguments(nil)); err != nil {
+		return fmt.Errorf("failed to declare dead-letter queue %s: %w", dlqName, err)
+	}
+	if err := ch.QueueBind(dlqName, queueName, dlx, false, nil); err != nil {
+		return fmt.Errorf("failed to bind dead-letter queue %s: %w", dlqName, err)
+	}
+
+	// Declare the work queue; rejected messages are dead-lettered to its DLQ
+	queueArgs := amqp.Table{
+		"x-dead-letter-exchange":    dlx,
+		"x-dead-letter-routing-key": queueName,
+	}
+	for k, v := range args {
+		queueArgs[k] = v
+	}
+	_, err := ch.QueueDeclare(
+		queueName, // name
+		true,      // durable
+		false,     // delete when unused
+		false,     // exclusive
+		false,     // no-wait
+		queueArgs, // arguments
+	)
+	if err != nil {
+		return fmt.Errorf("failed to declare queue %s: %w", queueName, err)
+	}
+
+	// Declare one delay queue per attempt; expired messages return to the work queue
+	for attempt := 1; attempt <= cm.retryPolicy.MaxAttempts; attempt++ {
+		name := retryQueueName(queueName, attempt)
+		_, err := ch.QueueDeclare(
+			name,  // name
+			true,  // durable
+			false, // delete when unused
+			false, // exclusive
+			false, // no-wait
+			cm.replicatedArguments(amqp.Table{
+				"x-message-ttl":             cm.retryPolicy.Delay(attempt).Milliseconds(),
+				"x-dead-letter-exchange":    "",
+				"x-dead-letter-routing-key": queueName,
+			}),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to declare retry queue %s: %w", name, err)
+		}
+	}
+
+	slog.Info("Queue declared with retry topology",
+		"queue", queueName,
+		"arguments", args,
+		"dlq", dlqName,
+		"max_attempts", cm.retryPolicy.MaxAttempts,
+		"base_delay", cm.retryPolicy.BaseDelay,
+	)
+	return nil
+}
+
+// handleFailure settles a delivery whose processing failed, based on the error classification:
+// canceled work is requeued, transient errors are delay-retried and permanent errors are dead-lettered.
+// It returns true if the message will be delivered again.
+func (cm *ConsumerManager) handleFailure(ctx context.Context, msg amqp.Delivery, queueName string, err error) bool {
+	kind, code := bot.Classify(err)
+	cm.status.RecordError(err)
+
+	switch kind {
+	case bot.ErrorKindCanceled:
+		slog.Info("Processing canceled, requeuing message", "queue", queueName, "error_code", code)
+		msg.Nack(false, true)
+		return true
+	case bot.ErrorKindTransient:
+		return cm.retryOrDeadLetter(ctx, msg, queueName)
+	default:
+		slog.Warn("Permanent error, dead-lettering message", "queue", queueName, "error_code", code, "error", err)
+		msg.Nack(false, false)
+		return false
+	}
+}
+
+// retryOrDeadLetter schedules a failed delivery for a delayed retry, or dead-letters it once
+// the retry budget is exhausted. The delivery is acked only once the broker has confirmed the
+// copy in the delay queue; otherwise it is requeued. It returns true if the message will be
+// delivered again.
+func (cm *ConsumerManager) retryOrDeadLetter(ctx context.Context, msg amqp.Delivery, queueName string) bool {
+	attempt := retryCount(msg.Headers) + 1
+	if attempt > cm.retryPolicy.MaxAttempts {
+		slog.Warn("Retry attempts exhausted, dead-lettering message",
+			"queue", queueName,
+			"attempts", attempt-1,
+		)
+		msg.Nack(false, false)
+		return false
+	}
+
+	headers := copyHeaders(msg.Headers)
+	headers[RetryCountHeader] = int32(attempt)
+
+	if err := cm.republish(ctx, "", retryQueueName(queueName, attempt), msg, headers); err != nil {
+		// Could not schedule the retry; put the original back so it is not lost
+		slog.Error("Failed to schedule retry, requeuing message", "queue", queueName, "error", err)
+		msg.Nack(false, true)
+		return true
+	}
+
+	msg.Ack(false)
+	slog.Info("Scheduled message for retry",
+		"queue", queueName,
+		"attempt", attempt,
+		"delay", cm.retryPolicy.Delay(attempt),
+	)
+	return true
+}
+
+// republish publishes a copy of msg with the given headers and waits for the broker confirm.
+// The copy is mandatory, so one that reaches no queue fails instead of being dropped.
+func (cm *ConsumerManager) republish(ctx context.Context, exchange, key string, msg amqp.Delivery, headers amqp.Table) error {
+	publisher, err := cm.getRepublisher()
+	if err != nil {
+		return err
+	}
+
+	return publisher.publish(ctx, exchange, key, amqp.Publishing{
+		Headers:       headers,
+		ContentType:   msg.ContentType,
+		DeliveryMode:  amqp.Persistent,
+		CorrelationId: msg.CorrelationId,
+		ReplyTo:       msg.ReplyTo,
+		MessageId:     msg.MessageId,
+		Type:          msg.Type,
+		Body:          msg.Body,
+	})
+}
+
+// getRepublisher returns the publisher of retry and dead-letter copies, opening it on first use
+func (cm *ConsumerManager) getRepublisher() (*Publisher, error) {
+	cm.republisherMu.Lock()
+	defer cm.republisherMu.Unlock()
+
+	if cm.republisher == nil {
+		publisher, err := newPublisher(cm.conn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open republisher: %w", err)
+		}
+		cm.republisher = publisher
+	}
+	return cm.republisher, nil
+}
+
+// copyHeaders returns a copy of headers that can be modified without touching the delivery
+func copyHeaders(headers amqp.Table) amqp.Table {
+	copied := make(amqp.Table, len(headers)+1)
+	for k, v := range headers {
+		copied[k] = v
+	}
+	return copied
+}
+
+// retryCount reads the x-retry-count header from a delivery
+func retryCount(headers amqp.Table) int {
+	switch v := headers[RetryCountHeader].(type) {
+	case int:
+		return v
+	case int8:
+		return int(v)
+	case int16:
+		return int(v)
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case uint8:
+		return int(v)
+	case uint16:
+		return int(v)
+	case uint32:
+		return int(v)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,171 @@
+package rabbitmq_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gamers-bot/internal/bot"
+	"github.com/gamers-bot/internal/handlers"
+	"github.com/gamers-bot/internal/rabbitmq"
+	"github.com/gamers-bot/internal/rabbitmq/rabbitmqtest"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	testExchange  = "test.events"
+	testQueue     = "test.work"
+	testEventType = rabbitmq.EventType("test.event")
+)
+
+// failingHandler fails the first failures calls with err and succeeds afterwards
+type failingHandler struct {
+	err      error
+	failures int32
+	calls    atomic.Int32
+}
+
+func (h *failingHandler) Handle(ctx context.Context, _ *bot.DiscordBot, _ string, _ map[string]interface{}) (map[string]interface{}, error) {
+	if h.calls.Add(1) <= h.failures {
+		return nil, h.err
+	}
+	return map[string]interface{}{}, nil
+}
+
+// newTestManager returns a manager consuming testQueue on the broker, with handler registered for testEventType
+func newTestManager(t *testing.T, broker *rabbitmqtest.Broker, handler handlers.Handler) *rabbitmq.ConsumerManager {
+	t.Helper()
+	conn, err := broker.Dial("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	topology := &rabbitmq.Topology{
+		Exchange: testExchange,
+		Queues:   []rabbitmq.QueueBinding{{QueueName: testQueue, RoutingKeys: []string{string(testEventType)}}},
+	}
+	manager := rabbitmq.NewConsumerManager(conn, testExchange, 1, topology, nil, nil)
+	manager.RegisterHandler(testEventType, handler)
+	return manager
+}
+
+// startManager runs the manager until the test ends and waits for its consumer
+func startManager(t *testing.T, broker *rabbitmqtest.Broker, manager *rabbitmq.ConsumerManager) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		manager.Start(ctx, "")
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+		manager.Close()
+	})
+
+	waitFor(t, "consumer to start", func() bool { return broker.Consumers(testQueue) > 0 })
+}
+
+// publishTestEvent publishes a test event to the test exchange
+func publishTestEvent(t *testing.T, broker *rabbitmqtest.Broker) {
+	t.Helper()
+	err := broker.Publish(testExchange, string(testEventType), amqp.Publishing{
+		Headers:   amqp.Table{"event_type": string(testEventType)},
+		MessageId: "msg-1",
+		Body:      []byte(`{"event_type":"test.event","event_id":"evt-1"}`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// waitFor polls cond until it holds or fails the test after a few seconds
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestTransientFailureIsScheduledForRetry(t *testing.T) {
+	broker := rabbitmqtest.NewBroker()
+	handler := &failingHandler{err: bot.NewError(bot.ErrorKindTransient, bot.ErrCodeDiscordUnavailable, errors.New("discord is down")), failures: 1}
+	manager := newTestManager(t, broker, handler)
+	manager.SetRetryPolicy(rabbitmq.RetryPolicy{DeadLetterExchange: "test.dlx", MaxAttempts: 1, BaseDelay: time.Hour})
+	if err := manager.SetupTopology(); err != nil {
+		t.Fatalf("SetupTopology: %v", err)
+	}
+	startManager(t, broker, manager)
+
+	publishTestEvent(t, broker)
+
+	retryQueue := testQueue + ".retry.1"
+	waitFor(t, "message in "+retryQueue, func() bool { return len(broker.Messages(retryQueue)) == 1 })
+	waitFor(t, "original to be acked", func() bool { return broker.Unacked(testQueue) == 0 })
+
+	retried := broker.Messages(retryQueue)[0]
+	if got := retried.Headers[rabbitmq.RetryCountHeader]; got != int32(1) {
+		t.Errorf("%s = %v, want 1", rabbitmq.RetryCountHeader, got)
+	}
+	if retried.MessageId != "msg-1" {
+		t.Errorf("retried message ID = %q, want msg-1", retried.MessageId)
+	}
+	if n := len(broker.Messages(testQueue)); n != 0 {
+		t.Errorf("%s has %d messages, want 0", testQueue, n)
+	}
+}
+
+func TestUnroutableRetryRequeuesMessage(t *testing.T) {
+	broker := rabbitmqtest.NewBroker()
+	handler := &failingHandler{err: bot.NewError(bot.ErrorKindTransient, bot.ErrCodeDiscordUnavailable, errors.New("discord is down")), failures: 1}
+	manager := newTestManager(t, broker, handler)
+
+	// Declare no delay queues, then retry as if they had been deleted
+	manager.SetRetryPolicy(rabbitmq.RetryPolicy{DeadLetterExchange: "test.dlx", MaxAttempts: 0})
+	if err := manager.SetupTopology(); err != nil {
+		t.Fatalf("SetupTopology: %v", err)
+	}
+	manager.SetRetryPolicy(rabbitmq.RetryPolicy{DeadLetterExchange: "test.dlx", MaxAttempts: 1, BaseDelay: time.Hour})
+	startManager(t, broker, manager)
+
+	publishTestEvent(t, broker)
+
+	// The retry copy is returned by the broker, so the original is requeued and handled again
+	waitFor(t, "second delivery", func() bool { return handler.calls.Load() >= 2 })
+	waitFor(t, "message to be acked", func() bool {
+		return broker.Unacked(testQueue) == 0 && len(broker.Messages(testQueue)) == 0
+	})
+	if n := len(broker.Messages(rabbitmq.DeadLetterQueueName(testQueue))); n != 0 {
+		t.Errorf("DLQ has %d messages, want 0", n)
+	}
+}
+
+func TestExhaustedRetriesAreDeadLettered(t *testing.T) {
+	broker := rabbitmqtest.NewBroker()
+	handler := &failingHandler{err: bot.NewError(bot.ErrorKindTransient, bot.ErrCodeDiscordUnavailable, errors.New("discord is down")), failures: 10}
+	manager := newTestManager(t, broker, handler)
+	manager.SetRetryPolicy(rabbitmq.RetryPolicy{DeadLetterExchange: "test.dlx", MaxAttempts: 2, BaseDelay: time.Millisecond})
+	if err := manager.SetupTopology(); err != nil {
+		t.Fatalf("SetupTopology: %v", err)
+	}
+	startManager(t, broker, manager)
+
+	publishTestEvent(t, broker)
+
+	dlq := rabbitmq.DeadLetterQueueName(testQueue)
+	waitFor(t, "message in "+dlq, func() bool { return len(broker.Messages(dlq)) == 1 })
+	if calls := handler.calls.Load(); calls != 3 {
+		t.Errorf("handler called %d times, want 3 (first attempt and 2 retries)", calls)
+	}
+	if got := broker.Messages(dlq)[0].Headers[rabbitmq.RetryCountHeader]; got != int32(2) {
+		t.Errorf("%s = %v, want 2", rabbitmq.RetryCountHeader, got)
+	}
+}
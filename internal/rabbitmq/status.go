@@ -0,0 +1,154 @@
+package rabbitmq
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// QueueState is the state of a queue consumer
+type QueueState string
+
+const (
+	// QueueConsuming means the consumer is attached and receiving deliveries
+	QueueConsuming QueueState = "consuming"
+	// QueueStopped means the consumer is not attached, e.g. while reconnecting
+	QueueStopped QueueState = "stopped"
+)
+
+// QueueStatus is the state of one queue consumer
+type QueueStatus struct {
+	Queue         string     `json:"queue"`
+	State         QueueState `json:"state"`
+	LastMessageAt *time.Time `json:"last_message_at,omitempty"`
+}
+
+// Status is a snapshot of the RabbitMQ connection and its consumers
+type Status struct {
+	Connected     bool          `json:"connected"`
+	Queues        []QueueStatus `json:"queues"`
+	LastMessageAt *time.Time    `json:"last_message_at,omitempty"`
+	LastError     string        `json:"last_error,omitempty"`
+	LastErrorAt   *time.Time    `json:"last_error_at,omitempty"`
+}
+
+// ConsumersRunning reports whether every known queue has an attached consumer
+func (s Status) ConsumersRunning() bool {
+	if len(s.Queues) == 0 {
+		return false
+	}
+	for _, q := range s.Queues {
+		if q.State != QueueConsuming {
+			return false
+		}
+	}
+	return true
+}
+
+// StatusTracker records the connection and consumer state across reconnects, for health checks.
+// All methods are safe to call on a nil *StatusTracker, which records nothing.
+type StatusTracker struct {
+	mu            sync.RWMutex
+	connected     bool
+	queues        map[string]*QueueStatus
+	lastMessageAt time.Time
+	lastError     string
+	lastErrorAt   time.Time
+}
+
+// NewStatusTracker creates an empty StatusTracker
+func NewStatusTracker() *StatusTracker {
+	return &StatusTracker{queues: make(map[string]*QueueStatus)}
+}
+
+// SetConnected records the connection state; err is recorded as the last error if set
+func (t *StatusTracker) SetConnected(connected bool, err error) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.connected = connected
+	t.mu.Unlock()
+	t.RecordError(err)
+}
+
+// RecordError records err as the last error; nil is ignored
+func (t *StatusTracker) RecordError(err error) {
+	if t == nil || err == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lastError = err.Error()
+	t.lastErrorAt = time.Now()
+}
+
+// setQueueState records the state of a queue consumer
+func (t *StatusTracker) setQueueState(queueName string, state QueueState) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	q, ok := t.queues[queueName]
+	if !ok {
+		q = &QueueStatus{Queue: queueName}
+		t.queues[queueName] = q
+	}
+	q.State = state
+}
+
+// messageReceived records a delivery received from a queue
+func (t *StatusTracker) messageReceived(queueName string) {
+	if t == nil {
+		return
+	}
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lastMessageAt = now
+	if q, ok := t.queues[queueName]; ok {
+		q.LastMessageAt = &now
+	}
+}
+
+// Status returns a snapshot of the tracked state, with queues sorted by name
+func (t *StatusTracker) Status() Status {
+	if t == nil {
+		return Status{}
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	status := Status{
+		Connected: t.connected,
+		Queues:    make([]QueueStatus, 0, len(t.queues)),
+		LastError: t.lastError,
+	}
+	for _, q := range t.queues {
+		status.Queues = append(status.Queues, *q)
+	}
+	slices.SortFunc(status.Queues, func(a, b QueueStatus) int {
+		return strings.Compare(a.Queue, b.Queue)
+	})
+	if !t.lastMessageAt.IsZero() {
+		lastMessageAt := t.lastMessageAt
+		status.LastMessageAt = &lastMessageAt
+	}
+	if !t.lastErrorAt.IsZero() {
+		lastErrorAt := t.lastErrorAt
+		status.LastErrorAt = &lastErrorAt
+	}
+	return status
+}
+
+// trackDeliveries wraps handle so every delivery updates the last message time of its queue
+func (cm *ConsumerManager) trackDeliveries(queueName string, handle func(amqp.Delivery)) func(amqp.Delivery) {
+	return func(msg amqp.Delivery) {
+		cm.status.messageReceived(queueName)
+		handle(msg)
+	}
+}
@@ -0,0 +1,204 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// ConnectionState is the state of the supervised RabbitMQ connection
+type ConnectionState int
+
+const (
+	StateDisconnected ConnectionState = iota
+	StateConnecting
+	StateConnected
+	StateStopped
+)
+
+// String returns the state name used in logs
+func (s ConnectionState) String() string {
+	switch s {
+	case StateDisconnected:
+		return "disconnected"
+	case StateConnecting:
+		return "connecting"
+	case StateConnected:
+		return "connected"
+	case StateStopped:
+		return "stopped"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(s))
+	}
+}
+
+// SessionFunc sets up everything that lives on a connection (publishers, topology, handlers,
+// consumers) and runs until ctx is cancelled or the session fails. It is called again on every
+// reconnect with a fresh connection. ctx is cancelled as soon as the connection closes.
+type SessionFunc func(ctx context.Context, conn Connection) error
+
+// SupervisorConfig configures reconnect behaviour
+type SupervisorConfig struct {
+	URL        string
+	MinBackoff time.Duration                        // first reconnect delay
+	MaxBackoff time.Duration                        // upper bound of the reconnect delay
+	Dial       func(url string) (Connection, error) // connects to URL; defaults to Dial
+}
+
+// Supervisor keeps a RabbitMQ connection alive. It dials, runs a session on the connection and
+// reconnects with jittered exponential backoff whenever the connection or the session fails.
+type Supervisor struct {
+	config        SupervisorConfig
+	session       SessionFunc
+	onStateChange func(state ConnectionState, err error)
+	done          chan struct{}
+}
+
+// NewSupervisor creates a new Supervisor. onStateChange may be nil.
+func NewSupervisor(config SupervisorConfig, session SessionFunc, onStateChange func(state ConnectionState, err error)) *Supervisor {
+	if config.MinBackoff <= 0 {
+		config.MinBackoff = time.Second
+	}
+	if config.MaxBackoff < config.MinBackoff {
+		config.MaxBackoff = config.MinBackoff
+	}
+	if config.Dial == nil {
+		config.Dial = Dial
+	}
+	return &Supervisor{
+		config:        config,
+		session:       session,
+		onStateChange: onStateChange,
+		done:          make(chan struct{}),
+	}
+}
+
+// Done is closed once Run has returned
+func (s *Supervisor) Done() <-chan struct{} {
+	return s.done
+}
+
+// Run connects and runs sessions until ctx is cancelled
+func (s *Supervisor) Run(ctx context.Context) {
+	defer close(s.done)
+	defer s.setState(StateStopped, nil)
+
+	attempt := 0
+	for ctx.Err() == nil {
+		s.setState(StateConnecting, nil)
+		slog.Info("Attempting to connect to RabbitMQ", "attempt", attempt+1)
+
+		conn, err := s.config.Dial(s.config.URL)
+		if err != nil {
+			s.setState(StateDisconnected, err)
+			attempt++
+			if !s.sleep(ctx, attempt) {
+				return
+			}
+			continue
+		}
+
+		s.setState(StateConnected, nil)
+		started := time.Now()
+		err = s.runSession(ctx, conn)
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		// A session that stayed up for a while starts the backoff over
+		if time.Since(started) > s.config.MaxBackoff {
+			attempt = 0
+		}
+		attempt++
+
+		if err == nil {
+			err = errors.New("connection lost")
+		}
+		s.setState(StateDisconnected, err)
+		if !s.sleep(ctx, attempt) {
+			return
+		}
+	}
+}
+
+// runSession runs the session on conn and closes conn afterwards.
+// The session context is cancelled when the connection closes, so the session cannot outlive it.
+func (s *Supervisor) runSession(ctx context.Context, conn Connection) error {
+	defer conn.Close()
+
+	sessionCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	closed := conn.NotifyClose(make(chan *amqp.Error, 1))
+	go func() {
+		select {
+		case amqpErr, ok := <-closed:
+			if ok && amqpErr != nil {
+				cancel(fmt.Errorf("connection closed: %w", amqpErr))
+			} else {
+				cancel(errors.New("connection closed"))
+			}
+		case <-sessionCtx.Done():
+		}
+	}()
+
+	err := s.session(sessionCtx, conn)
+
+	// Report why the connection went away rather than the consumer error it caused
+	if cause := context.Cause(sessionCtx); cause != nil && ctx.Err() == nil && !errors.Is(cause, context.Canceled) {
+		return cause
+	}
+	return err
+}
+
+// sleep waits for the backoff of the given attempt. It returns false if ctx is cancelled first.
+func (s *Supervisor) sleep(ctx context.Context, attempt int) bool {
+	delay := s.backoff(attempt)
+	slog.Info("Reconnecting to RabbitMQ", "delay", delay, "attempt", attempt)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// backoff returns a delay between MinBackoff and MinBackoff * 2^(attempt-1), capped at MaxBackoff.
+// Full jitter keeps several replicas from reconnecting in lockstep.
+func (s *Supervisor) backoff(attempt int) time.Duration {
+	ceiling := s.config.MaxBackoff
+	if attempt < 1 {
+		attempt = 1
+	}
+	if attempt <= 32 {
+		if d := s.config.MinBackoff << (attempt - 1); d > 0 && d < ceiling {
+			ceiling = d
+		}
+	}
+	if ceiling <= s.config.MinBackoff {
+		return s.config.MinBackoff
+	}
+	return s.config.MinBackoff + rand.N(ceiling-s.config.MinBackoff)
+}
+
+// setState reports a state transition
+func (s *Supervisor) setState(state ConnectionState, err error) {
+	if err != nil {
+		slog.Warn("RabbitMQ connection state changed", "state", state, "error", err)
+	} else {
+		slog.Info("RabbitMQ connection state changed", "state", state)
+	}
+	if s.onStateChange != nil {
+		s.onStateChange(state, err)
+	}
+}
@@ -0,0 +1,234 @@
+package rabbitmq_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gamers-bot/internal/rabbitmq"
+	"github.com/gamers-bot/internal/rabbitmq/rabbitmqtest"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// stateChange is a state reported by a supervisor
+type stateChange struct {
+	state rabbitmq.ConnectionState
+	err   error
+}
+
+// stateRecorder records the state changes reported by a supervisor
+type stateRecorder struct {
+	mu      sync.Mutex
+	changes []stateChange
+}
+
+func (r *stateRecorder) record(state rabbitmq.ConnectionState, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.changes = append(r.changes, stateChange{state, err})
+}
+
+// states returns the states reported so far
+func (r *stateRecorder) states() []rabbitmq.ConnectionState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	states := make([]rabbitmq.ConnectionState, len(r.changes))
+	for i, c := range r.changes {
+		states[i] = c.state
+	}
+	return states
+}
+
+// count returns how often state was reported
+func (r *stateRecorder) count(state rabbitmq.ConnectionState) int {
+	n := 0
+	for _, s := range r.states() {
+		if s == state {
+			n++
+		}
+	}
+	return n
+}
+
+// errorsOf returns the errors reported with state
+func (r *stateRecorder) errorsOf(state rabbitmq.ConnectionState) []error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var errs []error
+	for _, c := range r.changes {
+		if c.state == state {
+			errs = append(errs, c.err)
+		}
+	}
+	return errs
+}
+
+// runSupervisor runs a supervisor of session, connecting with dial, until the test ends. It returns
+// the recorder of its state changes and a function stopping it.
+func runSupervisor(t *testing.T, dial func(string) (rabbitmq.Connection, error), session rabbitmq.SessionFunc) (*stateRecorder, func()) {
+	t.Helper()
+	recorder := &stateRecorder{}
+	supervisor := rabbitmq.NewSupervisor(
+		rabbitmq.SupervisorConfig{MinBackoff: 10 * time.Millisecond, MaxBackoff: 40 * time.Millisecond, Dial: dial},
+		session,
+		recorder.record,
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	go supervisor.Run(ctx)
+
+	stop := func() {
+		cancel()
+		select {
+		case <-supervisor.Done():
+		case <-time.After(5 * time.Second):
+			t.Fatal("supervisor did not stop")
+		}
+	}
+	t.Cleanup(stop)
+	return recorder, stop
+}
+
+// blockingSession counts the sessions started and runs each until its context is done
+func blockingSession(sessions *atomic.Int32) rabbitmq.SessionFunc {
+	return func(ctx context.Context, _ rabbitmq.Connection) error {
+		sessions.Add(1)
+		<-ctx.Done()
+		return ctx.Err()
+	}
+}
+
+func TestSupervisorReconnectsAfterConnectionLoss(t *testing.T) {
+	broker := rabbitmqtest.NewBroker()
+	var sessions atomic.Int32
+	recorder, stop := runSupervisor(t, broker.Dial, blockingSession(&sessions))
+
+	waitFor(t, "first session", func() bool { return sessions.Load() == 1 })
+	broker.CloseConnections(nil)
+	waitFor(t, "second session", func() bool { return sessions.Load() == 2 })
+	stop()
+
+	want := []rabbitmq.ConnectionState{
+		rabbitmq.StateConnecting, rabbitmq.StateConnected,
+		rabbitmq.StateDisconnected,
+		rabbitmq.StateConnecting, rabbitmq.StateConnected,
+		rabbitmq.StateStopped,
+	}
+	if got := recorder.states(); !slices.Equal(got, want) {
+		t.Fatalf("states = %v, want %v", got, want)
+	}
+	var amqpErr *amqp.Error
+	if errs := recorder.errorsOf(rabbitmq.StateDisconnected); !errors.As(errs[0], &amqpErr) || amqpErr.Code != amqp.ConnectionForced {
+		t.Errorf("disconnected with %v, want the CONNECTION_FORCED close error", errs[0])
+	}
+}
+
+func TestSupervisorReconnectsAfterSessionFailure(t *testing.T) {
+	broker := rabbitmqtest.NewBroker()
+	errTopology := errors.New("topology mismatch")
+	var sessions atomic.Int32
+	failed := make(chan rabbitmq.Connection, 1)
+	recorder, _ := runSupervisor(t, broker.Dial, func(ctx context.Context, conn rabbitmq.Connection) error {
+		if sessions.Add(1) == 1 {
+			failed <- conn
+			return errTopology
+		}
+		<-ctx.Done()
+		return nil
+	})
+
+	waitFor(t, "second session", func() bool { return sessions.Load() == 2 })
+	if errs := recorder.errorsOf(rabbitmq.StateDisconnected); len(errs) != 1 || !errors.Is(errs[0], errTopology) {
+		t.Errorf("disconnected with %v, want the session error", errs)
+	}
+	// The connection of the failed session is closed before reconnecting
+	if _, err := (<-failed).Channel(); err == nil {
+		t.Error("connection of the failed session is still open")
+	}
+}
+
+func TestSupervisorRetriesFailedDialsWithBackoff(t *testing.T) {
+	broker := rabbitmqtest.NewBroker()
+	errRefused := errors.New("connection refused")
+	broker.SetDialError(errRefused)
+
+	var mu sync.Mutex
+	var dials []time.Time
+	dial := func(url string) (rabbitmq.Connection, error) {
+		mu.Lock()
+		dials = append(dials, time.Now())
+		if len(dials) == 4 {
+			broker.SetDialError(nil)
+		}
+		mu.Unlock()
+		return broker.Dial(url)
+	}
+	var sessions atomic.Int32
+	recorder, _ := runSupervisor(t, dial, blockingSession(&sessions))
+
+	waitFor(t, "session after failed dials", func() bool { return sessions.Load() == 1 })
+
+	if n := recorder.count(rabbitmq.StateDisconnected); n != 3 {
+		t.Errorf("reported %d disconnects, want one per failed dial (3)", n)
+	}
+	for _, err := range recorder.errorsOf(rabbitmq.StateDisconnected) {
+		if !errors.Is(err, errRefused) {
+			t.Errorf("disconnected with %v, want the dial error", err)
+		}
+	}
+	if n := recorder.count(rabbitmq.StateConnected); n != 1 {
+		t.Errorf("reported %d connects, want 1", n)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for i := 1; i < len(dials); i++ {
+		if gap := dials[i].Sub(dials[i-1]); gap < 10*time.Millisecond {
+			t.Errorf("dial %d came %v after the previous one, want at least MinBackoff", i+1, gap)
+		}
+	}
+}
+
+func TestSupervisorBackoffStaysWithinBounds(t *testing.T) {
+	const minBackoff, maxBackoff = 100 * time.Millisecond, time.Second
+	supervisor := rabbitmq.NewSupervisor(rabbitmq.SupervisorConfig{MinBackoff: minBackoff, MaxBackoff: maxBackoff}, nil, nil)
+
+	tests := []struct {
+		attempt int
+		ceiling time.Duration
+	}{
+		{0, minBackoff},
+		{1, minBackoff},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, maxBackoff},
+		{40, maxBackoff}, // the shift would overflow
+		{1000, maxBackoff},
+	}
+	for _, tt := range tests {
+		seen := make(map[time.Duration]bool)
+		for i := 0; i < 200; i++ {
+			d := supervisor.Backoff(tt.attempt)
+			if d < minBackoff || d > tt.ceiling {
+				t.Fatalf("Backoff(%d) = %v, want between %v and %v", tt.attempt, d, minBackoff, tt.ceiling)
+			}
+			seen[d] = true
+		}
+		// Above the first attempt the delay is jittered
+		if tt.ceiling > minBackoff && len(seen) < 2 {
+			t.Errorf("Backoff(%d) always returned %v, want jitter", tt.attempt, supervisor.Backoff(tt.attempt))
+		}
+	}
+}
+
+func TestSupervisorDefaultsBackoffBounds(t *testing.T) {
+	supervisor := rabbitmq.NewSupervisor(rabbitmq.SupervisorConfig{MaxBackoff: time.Millisecond}, nil, nil)
+	// MinBackoff defaults to 1s and MaxBackoff is raised to it
+	if d := supervisor.Backoff(3); d != time.Second {
+		t.Errorf("Backoff(3) = %v, want 1s", d)
+	}
+}
@@ -1,40 +1,215 @@
 package rabbitmq
 
-// QueueBinding defines a queue name and its routing key bindings
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Queue types supported in the topology file
+const (
+	QueueTypeClassic = "classic"
+	QueueTypeQuorum  = "quorum"
+)
+
+// Topology declares the exchange and the notification queues the bot consumes from.
+// It is loaded from a JSON file (RABBITMQ_TOPOLOGY_FILE) or built by DefaultTopology.
+type Topology struct {
+	// Exchange the queues are bound to; empty means RABBITMQ_EXCHANGE
+	Exchange string         `json:"exchange,omitempty"`
+	Queues   []QueueBinding `json:"queues"`
+}
+
+// QueueBinding defines a queue, its routing key bindings, queue arguments and handled event types
 type QueueBinding struct {
-	QueueName   string
-	RoutingKeys []string
+	QueueName   string   `json:"name"`
+	RoutingKeys []string `json:"routing_keys"`
+
+	// Queue arguments
+	Type               string   `json:"type,omitempty"`                 // "classic" (default) or "quorum"
+	MaxLength          int      `json:"max_length,omitempty"`           // x-max-length; 0 means unlimited
+	MessageTTL         Duration `json:"message_ttl,omitempty"`          // x-message-ttl; 0 means none
+	DeadLetterExchange string   `json:"dead_letter_exchange,omitempty"` // overrides RABBITMQ_DLX_EXCHANGE
+
+	// Handlers maps event types accepted on this queue to the name of the registered handler
+	// that processes them. An empty handler name means the handler registered for the event type.
+	// If no handlers are listed, every registered handler is used.
+	Handlers map[EventType]EventType `json:"handlers,omitempty"`
+}
+
+// Duration is a time.Duration that is written as a string such as "30s" in JSON
+type Duration time.Duration
+
+// UnmarshalJSON parses a duration string
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"30s\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalJSON formats the duration as a string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
 }
 
-// DefaultQueueBindings returns the 4 new queue bindings for the gamers.events exchange
-func DefaultQueueBindings() []QueueBinding {
-	return []QueueBinding{
-		{
-			QueueName: "bot.contest.notifications",
-			RoutingKeys: []string{
-				"contest.#",
+// DefaultTopology returns the built-in queue bindings for the gamers.events exchange.
+// contestRoutingKey binds the contest notification queue (RABBITMQ_ROUTING_KEY).
+func DefaultTopology(contestRoutingKey string) *Topology {
+	if contestRoutingKey == "" {
+		contestRoutingKey = "contest.#"
+	}
+	return &Topology{
+		Queues: []QueueBinding{
+			{
+				QueueName: "bot.contest.notifications",
+				RoutingKeys: []string{
+					contestRoutingKey,
+				},
 			},
-		},
-		{
-			QueueName: "bot.team.notifications",
-			RoutingKeys: []string{
-				"game.team.#",
+			{
+				QueueName: "bot.team.notifications",
+				RoutingKeys: []string{
+					"game.team.#",
+				},
 			},
-		},
-		{
-			QueueName: "bot.game.notifications",
-			RoutingKeys: []string{
-				"game.scheduled",
-				"game.activated",
-				"game.match.*",
-				"game.finished",
+			{
+				QueueName: "bot.game.notifications",
+				RoutingKeys: []string{
+					"game.scheduled",
+					"game.activated",
+					"game.match.*",
+					"game.finished",
+				},
 			},
-		},
-		{
-			QueueName: "bot.contest.teams.ready",
-			RoutingKeys: []string{
-				"game.contest.teams.ready",
+			{
+				QueueName: "bot.contest.teams.ready",
+				RoutingKeys: []string{
+					"game.contest.teams.ready",
+				},
 			},
 		},
 	}
 }
+
+// LoadTopology reads and validates a topology file
+func LoadTopology(path string) (*Topology, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read topology file %s: %w", path, err)
+	}
+
+	var topology Topology
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&topology); err != nil {
+		return nil, fmt.Errorf("failed to parse topology file %s: %w", path, err)
+	}
+
+	if err := topology.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid topology file %s: %w", path, err)
+	}
+	return &topology, nil
+}
+
+// Validate checks the topology for structural errors
+func (t *Topology) Validate() error {
+	if len(t.Queues) == 0 {
+		return errors.New("at least one queue is required")
+	}
+
+	var errs []error
+	seen := make(map[string]bool)
+	for i, q := range t.Queues {
+		name := q.QueueName
+		if name == "" {
+			errs = append(errs, fmt.Errorf("queues[%d]: name is required", i))
+			name = fmt.Sprintf("queues[%d]", i)
+		} else if seen[name] {
+			errs = append(errs, fmt.Errorf("queue %s: declared more than once", name))
+		}
+		seen[name] = true
+
+		if len(q.RoutingKeys) == 0 {
+			errs = append(errs, fmt.Errorf("queue %s: at least one routing key is required", name))
+		}
+		for _, rk := range q.RoutingKeys {
+			if rk == "" {
+				errs = append(errs, fmt.Errorf("queue %s: routing keys must not be empty", name))
+			}
+		}
+		switch q.Type {
+		case "", QueueTypeClassic, QueueTypeQuorum:
+		default:
+			errs = append(errs, fmt.Errorf("queue %s: type must be %s or %s", name, QueueTypeClassic, QueueTypeQuorum))
+		}
+		if q.MaxLength < 0 {
+			errs = append(errs, fmt.Errorf("queue %s: max_length must not be negative", name))
+		}
+		if q.MessageTTL < 0 {
+			errs = append(errs, fmt.Errorf("queue %s: message_ttl must not be negative", name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateHandlers checks that every handler referenced by the topology is registered
+func (t *Topology) ValidateHandlers(registered map[EventType]bool) error {
+	var errs []error
+	for _, q := range t.Queues {
+		for _, eventType := range slices.Sorted(maps.Keys(q.Handlers)) {
+			handler := q.Handlers[eventType]
+			if handler == "" {
+				handler = eventType
+			}
+			if !registered[handler] {
+				errs = append(errs, fmt.Errorf("queue %s: event %s refers to unknown handler %s", q.QueueName, eventType, handler))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// arguments returns the queue arguments declared for the queue, without dead-lettering
+func (q QueueBinding) arguments() amqp.Table {
+	args := amqp.Table{}
+	if q.Type != "" {
+		args["x-queue-type"] = q.Type
+	}
+	if q.MaxLength > 0 {
+		args["x-max-length"] = int64(q.MaxLength)
+	}
+	if q.MessageTTL > 0 {
+		args["x-message-ttl"] = time.Duration(q.MessageTTL).Milliseconds()
+	}
+	return args
+}
+
+// handlerName returns the handler that processes eventType on this queue, or false if the
+// queue does not accept eventType
+func (q QueueBinding) handlerName(eventType EventType) (EventType, bool) {
+	if len(q.Handlers) == 0 {
+		return eventType, true
+	}
+	handler, ok := q.Handlers[eventType]
+	if !ok {
+		return "", false
+	}
+	if handler == "" {
+		handler = eventType
+	}
+	return handler, true
+}
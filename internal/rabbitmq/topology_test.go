@@ -0,0 +1,90 @@
+package rabbitmq_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/gamers-bot/internal/rabbitmq"
+	"github.com/gamers-bot/internal/rabbitmq/rabbitmqtest"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestSetupTopologyDeclaresTopologyFileQueues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "topology.json")
+	err := os.WriteFile(path, []byte(`{
+		"exchange": "custom.events",
+		"queues": [
+			{"name": "custom.games", "routing_keys": ["game.#"], "max_length": 2},
+			{"name": "custom.contests", "routing_keys": ["contest.created"]}
+		]
+	}`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	topology, err := rabbitmq.LoadTopology(path)
+	if err != nil {
+		t.Fatalf("LoadTopology: %v", err)
+	}
+
+	broker := rabbitmqtest.NewBroker()
+	conn, err := broker.Dial("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	manager := rabbitmq.NewConsumerManager(conn, "gamers.events", 1, topology, nil, nil)
+	if err := manager.SetupTopology(); err != nil {
+		t.Fatalf("SetupTopology: %v", err)
+	}
+
+	queues := broker.Queues()
+	for _, name := range []string{"custom.games", "custom.games.dlq", "custom.games.retry.1", "custom.contests", "custom.contests.dlq"} {
+		if !slices.Contains(queues, name) {
+			t.Errorf("queue %s not declared; declared %v", name, queues)
+		}
+	}
+	if slices.Contains(queues, "bot.game.notifications") {
+		t.Errorf("built-in topology declared alongside the topology file: %v", queues)
+	}
+
+	// Bindings use the topology exchange and routing keys, and the queue arguments apply
+	for _, key := range []string{"game.scheduled", "game.finished", "game.activated"} {
+		if err := broker.Publish("custom.events", key, amqp.Publishing{Body: []byte(key)}); err != nil {
+			t.Fatalf("publish %s: %v", key, err)
+		}
+	}
+	if err := broker.Publish("custom.events", "contest.created", amqp.Publishing{Body: []byte("contest")}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := len(broker.Messages("custom.games")); got != 2 {
+		t.Errorf("custom.games has %d messages, want 2 (x-max-length)", got)
+	}
+	if got := len(broker.Messages("custom.contests")); got != 1 {
+		t.Errorf("custom.contests has %d messages, want 1", got)
+	}
+}
+
+func TestNewConsumerManagerDefaultsToBuiltInTopology(t *testing.T) {
+	broker := rabbitmqtest.NewBroker()
+	conn, err := broker.Dial("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	manager := rabbitmq.NewConsumerManager(conn, "gamers.events", 1, nil, nil, nil)
+	if err := manager.SetupTopology(); err != nil {
+		t.Fatalf("SetupTopology: %v", err)
+	}
+
+	queues := broker.Queues()
+	for _, q := range rabbitmq.DefaultTopology("").Queues {
+		if !slices.Contains(queues, q.QueueName) {
+			t.Errorf("queue %s not declared; declared %v", q.QueueName, queues)
+		}
+	}
+}
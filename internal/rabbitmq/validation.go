@@ -0,0 +1,105 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+
+	"github.com/gamers-bot/internal/bot"
+	"github.com/gamers-bot/internal/schema"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// ValidationReportHeader is the AMQP header holding the JSON validation report of a
+// dead-lettered payload that did not match its schema
+const ValidationReportHeader = "x-validation-report"
+
+// SetSchemaRegistry enables payload validation against the registry's schemas before dispatch.
+// Payloads of older schema versions are upgraded to the latest version for the handler.
+func (cm *ConsumerManager) SetSchemaRegistry(registry *schema.Registry) {
+	cm.schemas = registry
+}
+
+// preparePayload validates payload against the schema of eventType and the version declared by
+// the delivery, and returns it upgraded to the latest version. raw is the undecoded payload; it is
+// returned as nil once the payload has been upgraded, as it no longer matches. Without a registry
+// the payload is returned unchanged. Invalid payloads return a permanent validation error wrapping
+// a *schema.ValidationError.
+func (cm *ConsumerManager) preparePayload(msg amqp.Delivery, eventType EventType, payload map[string]interface{}, raw json.RawMessage) (map[string]interface{}, json.RawMessage, error) {
+	if cm.schemas == nil {
+		return payload, raw, nil
+	}
+
+	version, err := resolveSchemaVersion(msg)
+	if err != nil {
+		return nil, nil, bot.NewError(bot.ErrorKindPermanent, bot.ErrCodeValidation, &schema.ValidationError{
+			EventType:  string(eventType),
+			Violations: []schema.Violation{{Path: "/" + schema.VersionField, Message: err.Error()}},
+		})
+	}
+
+	prepared, err := cm.schemas.Prepare(string(eventType), version, payload)
+	if err != nil {
+		return nil, nil, bot.NewError(bot.ErrorKindPermanent, bot.ErrCodeValidation, err)
+	}
+	if max(version, 1) < cm.schemas.Latest(string(eventType)) {
+		raw = nil
+	}
+	return prepared, raw, nil
+}
+
+// resolveSchemaVersion reads the schema version from the AMQP header first, then from the JSON body.
+// It returns 0 if neither declares one.
+func resolveSchemaVersion(msg amqp.Delivery) (int, error) {
+	if v, ok := msg.Headers[schema.VersionField]; ok {
+		return schema.ParseVersion(v)
+	}
+
+	var body struct {
+		SchemaVersion interface{} `json:"schema_version"`
+	}
+	if err := json.Unmarshal(msg.Body, &body); err != nil {
+		return 0, nil
+	}
+	return schema.ParseVersion(body.SchemaVersion)
+}
+
+// deadLetterInvalid dead-letters a delivery whose payload failed validation, attaching the
+// validation report as a header so it can be inspected in the DLQ. The delivery is acked once the
+// broker has confirmed the copy; if it cannot be published the delivery is rejected and
+// dead-lettered by the broker without the report.
+func (cm *ConsumerManager) deadLetterInvalid(ctx context.Context, msg amqp.Delivery, queueName string, err error) {
+	var validationErr *schema.ValidationError
+	if !errors.As(err, &validationErr) {
+		msg.Nack(false, false)
+		return
+	}
+
+	report, marshalErr := json.Marshal(validationErr)
+	if marshalErr != nil {
+		msg.Nack(false, false)
+		return
+	}
+
+	headers := copyHeaders(msg.Headers)
+	headers[ValidationReportHeader] = string(report)
+
+	// The routing key is the one of the queue's DLQ binding
+	if pubErr := cm.republish(ctx, cm.deadLetterExchangeFor(queueName), queueName, msg, headers); pubErr != nil {
+		slog.Error("Failed to dead-letter invalid payload with report", "queue", queueName, "error", pubErr)
+		msg.Nack(false, false)
+		return
+	}
+
+	msg.Ack(false)
+	slog.Warn("Invalid payload dead-lettered", "queue", queueName, "dlq", DeadLetterQueueName(queueName), "error", err)
+}
+
+// deadLetterExchangeFor returns the dead-letter exchange of a queue
+func (cm *ConsumerManager) deadLetterExchangeFor(queueName string) string {
+	if q, ok := cm.queues[queueName]; ok && q.DeadLetterExchange != "" {
+		return q.DeadLetterExchange
+	}
+	return cm.retryPolicy.DeadLetterExchange
+}
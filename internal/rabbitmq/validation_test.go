@@ -0,0 +1,57 @@
+package rabbitmq_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gamers-bot/internal/rabbitmq"
+	"github.com/gamers-bot/internal/rabbitmq/rabbitmqtest"
+	"github.com/gamers-bot/internal/schema"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestInvalidPayloadIsDeadLetteredWithReport(t *testing.T) {
+	broker := rabbitmqtest.NewBroker()
+	handler := &failingHandler{}
+	manager := newTestManager(t, broker, handler)
+	registry, err := schema.NewRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager.SetSchemaRegistry(registry)
+	if err := manager.SetupTopology(); err != nil {
+		t.Fatalf("SetupTopology: %v", err)
+	}
+	startManager(t, broker, manager)
+
+	err = broker.Publish(testExchange, string(testEventType), amqp.Publishing{
+		Headers:   amqp.Table{"event_type": string(testEventType), schema.VersionField: "latest"},
+		MessageId: "msg-1",
+		Body:      []byte(`{"event_type":"test.event","event_id":"evt-1"}`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dlq := rabbitmq.DeadLetterQueueName(testQueue)
+	waitFor(t, "message in "+dlq, func() bool { return len(broker.Messages(dlq)) == 1 })
+	waitFor(t, "original to be acked", func() bool { return broker.Unacked(testQueue) == 0 })
+
+	report, ok := broker.Messages(dlq)[0].Headers[rabbitmq.ValidationReportHeader].(string)
+	if !ok {
+		t.Fatalf("dead-lettered message has no %s header", rabbitmq.ValidationReportHeader)
+	}
+	var validationErr schema.ValidationError
+	if err := json.Unmarshal([]byte(report), &validationErr); err != nil {
+		t.Fatalf("invalid validation report %q: %v", report, err)
+	}
+	if validationErr.EventType != string(testEventType) || len(validationErr.Violations) == 0 {
+		t.Errorf("validation report = %+v, want violations for %s", validationErr, testEventType)
+	}
+	if calls := handler.calls.Load(); calls != 0 {
+		t.Errorf("handler called %d times, want 0", calls)
+	}
+	if n := len(broker.Messages(testQueue)); n != 0 {
+		t.Errorf("%s has %d messages, want 0", testQueue, n)
+	}
+}
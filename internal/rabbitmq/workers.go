@@ -0,0 +1,186 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"sync"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// partitionKeyFields are the payload fields used to keep related events in order, most specific first
+var partitionKeyFields = []string{"game_id", "team_id", "contest_id", "discord_guild_id", "guild_id"}
+
+// workerPool processes deliveries of a single queue concurrently.
+// Deliveries with the same partition key always go to the same worker, so they are handled in order.
+type workerPool struct {
+	queues []chan amqp.Delivery
+	wg     sync.WaitGroup
+	next   int // round-robin position for deliveries without a partition key
+}
+
+// newWorkerPool starts size workers calling handle. Each worker buffers up to buffer deliveries.
+func newWorkerPool(size, buffer int, handle func(amqp.Delivery)) *workerPool {
+	if size < 1 {
+		size = 1
+	}
+
+	wp := &workerPool{queues: make([]chan amqp.Delivery, size)}
+	for i := range wp.queues {
+		wp.queues[i] = make(chan amqp.Delivery, buffer)
+		wp.wg.Add(1)
+		go func(queue <-chan amqp.Delivery) {
+			defer wp.wg.Done()
+			for msg := range queue {
+				handle(msg)
+			}
+		}(wp.queues[i])
+	}
+	return wp
+}
+
+// dispatch hands a delivery to the worker owning key. It returns false if ctx is done first.
+func (wp *workerPool) dispatch(ctx context.Context, key string, msg amqp.Delivery) bool {
+	select {
+	case wp.queues[wp.workerFor(key)] <- msg:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// workerFor returns the worker index for a partition key
+func (wp *workerPool) workerFor(key string) int {
+	if key == "" {
+		i := wp.next
+		wp.next = (wp.next + 1) % len(wp.queues)
+		return i
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(len(wp.queues)))
+}
+
+// stop lets the workers finish their buffered deliveries and waits for them to exit
+func (wp *workerPool) stop() {
+	for _, queue := range wp.queues {
+		close(queue)
+	}
+	wp.wg.Wait()
+}
+
+// consumeWithWorkers feeds deliveries to a worker pool until ctx is cancelled or the channel closes.
+// handle is called from the worker goroutines; acks are per delivery, so they are safe to issue concurrently.
+// On cancellation the consumer is cancelled and deliveries already received are still handed to the workers,
+// which are waited for before returning. Deliveries that cannot be dispatched before handlerCtx is done
+// stay unacked and are redelivered once the channel closes.
+func (cm *ConsumerManager) consumeWithWorkers(ctx, handlerCtx context.Context, ch Channel, msgs <-chan amqp.Delivery, queueName string, handle func(amqp.Delivery)) error {
+	if cm.metrics != nil {
+		handle = cm.instrumentDeliveries(queueName, handle)
+	}
+	if cm.status != nil {
+		handle = cm.trackDeliveries(queueName, handle)
+	}
+	pool := newWorkerPool(cm.workersFor(queueName), cm.prefetchFor(queueName), handle)
+	defer pool.stop()
+
+	closed := ch.NotifyClose(make(chan *amqp.Error, 1))
+
+	for {
+		select {
+		case <-ctx.Done():
+			cm.drainConsumer(handlerCtx, ch, msgs, queueName, pool)
+			return ctx.Err()
+		case amqpErr, ok := <-closed:
+			if ok && amqpErr != nil {
+				return fmt.Errorf("channel closed for queue %s: %w", queueName, amqpErr)
+			}
+			return fmt.Errorf("channel closed for queue %s", queueName)
+		case msg, ok := <-msgs:
+			if !ok {
+				return fmt.Errorf("message channel closed for queue %s", queueName)
+			}
+			if !pool.dispatch(handlerCtx, partitionKey(msg), msg) {
+				return handlerCtx.Err()
+			}
+		}
+	}
+}
+
+// drainConsumer cancels the consumer so no new deliveries arrive and dispatches the ones
+// already received until the delivery channel closes
+func (cm *ConsumerManager) drainConsumer(handlerCtx context.Context, ch Channel, msgs <-chan amqp.Delivery, queueName string, pool *workerPool) {
+	if err := ch.Cancel(consumerTag(queueName), false); err != nil {
+		slog.Warn("Failed to cancel consumer", "queue", queueName, "error", err)
+		return
+	}
+
+	drained := 0
+	for msg := range msgs {
+		if !pool.dispatch(handlerCtx, partitionKey(msg), msg) {
+			return
+		}
+		drained++
+	}
+	slog.Info("Consumer cancelled, draining in-flight deliveries", "queue", queueName, "received_after_cancel", drained)
+}
+
+// consumerTag returns the consumer tag used for a queue, so the consumer can be cancelled by name
+func consumerTag(queueName string) string {
+	return "gamers-bot." + queueName
+}
+
+// workersFor returns the worker pool size for a queue
+func (cm *ConsumerManager) workersFor(queueName string) int {
+	if n, ok := cm.queueWorkers[queueName]; ok && n > 0 {
+		return n
+	}
+	if cm.workers > 0 {
+		return cm.workers
+	}
+	return 1
+}
+
+// prefetchFor returns the prefetch count for a queue, raised so that every worker can be kept busy
+func (cm *ConsumerManager) prefetchFor(queueName string) int {
+	return max(cm.prefetchCount, cm.workersFor(queueName))
+}
+
+// partitionKey returns the ordering key of a delivery, e.g. "game_id:42".
+// Deliveries without any of the partitionKeyFields return "" and are spread round-robin.
+func partitionKey(msg amqp.Delivery) string {
+	var body map[string]interface{}
+	if err := json.Unmarshal(msg.Body, &body); err != nil {
+		return ""
+	}
+
+	// Legacy requests may carry the identifiers inside payload
+	payload, _ := body["payload"].(map[string]interface{})
+
+	for _, field := range partitionKeyFields {
+		for _, source := range []map[string]interface{}{body, payload} {
+			if key := formatKeyValue(source[field]); key != "" {
+				return field + ":" + key
+			}
+		}
+	}
+	return ""
+}
+
+// formatKeyValue formats a JSON identifier, ignoring empty and zero values
+func formatKeyValue(v interface{}) string {
+	switch value := v.(type) {
+	case string:
+		return value
+	case float64:
+		if value == 0 {
+			return ""
+		}
+		return fmt.Sprintf("%.0f", value)
+	default:
+		return ""
+	}
+}
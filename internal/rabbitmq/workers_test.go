@@ -0,0 +1,202 @@
+package rabbitmq_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gamers-bot/internal/bot"
+	"github.com/gamers-bot/internal/rabbitmq"
+	"github.com/gamers-bot/internal/rabbitmq/rabbitmqtest"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// orderingHandler records the sequence numbers it handles per partition key and how many
+// calls run at once. Events with an even sequence number take delay to handle, so the next
+// event of their key would overtake them on another worker. Events with "fail" set are
+// rejected as invalid.
+type orderingHandler struct {
+	delay time.Duration
+
+	mu          sync.Mutex
+	handled     map[string][]int // sequence numbers by key, in the order they were handled
+	events      map[string]int   // calls by event_id
+	inFlight    int
+	maxInFlight int
+}
+
+func newOrderingHandler(delay time.Duration) *orderingHandler {
+	return &orderingHandler{delay: delay, handled: make(map[string][]int), events: make(map[string]int)}
+}
+
+func (h *orderingHandler) Handle(ctx context.Context, _ *bot.DiscordBot, _ string, payload map[string]interface{}) (map[string]interface{}, error) {
+	h.mu.Lock()
+	h.inFlight++
+	h.maxInFlight = max(h.maxInFlight, h.inFlight)
+	h.mu.Unlock()
+
+	seq := int(payload["seq"].(float64))
+	if seq%2 == 0 {
+		time.Sleep(h.delay)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.inFlight--
+	key := fmt.Sprintf("game_id=%v team_id=%v", payload["game_id"], payload["team_id"])
+	h.handled[key] = append(h.handled[key], seq)
+	h.events[payload["event_id"].(string)]++
+
+	if payload["fail"] == true {
+		return nil, bot.NewValidationError("rejected by the test")
+	}
+	return map[string]interface{}{}, nil
+}
+
+// calls returns the number of handler calls so far
+func (h *orderingHandler) calls() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	n := 0
+	for _, seqs := range h.handled {
+		n += len(seqs)
+	}
+	return n
+}
+
+// startWorkers runs a manager with the given number of workers on testQueue
+func startWorkers(t *testing.T, broker *rabbitmqtest.Broker, handler *orderingHandler, workers int) {
+	t.Helper()
+	manager := newTestManager(t, broker, handler)
+	manager.SetWorkers(workers)
+	manager.SetRetryPolicy(rabbitmq.RetryPolicy{DeadLetterExchange: "test.dlx", MaxAttempts: 0})
+	if err := manager.SetupTopology(); err != nil {
+		t.Fatalf("SetupTopology: %v", err)
+	}
+	startManager(t, broker, manager)
+}
+
+// publishKeyedEvent publishes a test event with the given payload fields
+func publishKeyedEvent(t *testing.T, broker *rabbitmqtest.Broker, fields map[string]interface{}) {
+	t.Helper()
+	event := map[string]interface{}{"event_type": string(testEventType)}
+	for k, v := range fields {
+		event[k] = v
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = broker.Publish(testExchange, string(testEventType), amqp.Publishing{
+		Headers: amqp.Table{"event_type": string(testEventType)},
+		Body:    body,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// waitSettled waits until the handler was called n times and testQueue has nothing left to settle
+func waitSettled(t *testing.T, broker *rabbitmqtest.Broker, handler *orderingHandler, n int) {
+	t.Helper()
+	waitFor(t, fmt.Sprintf("%d handler calls", n), func() bool { return handler.calls() >= n })
+	waitFor(t, "deliveries to be settled", func() bool {
+		return broker.Unacked(testQueue) == 0 && len(broker.Messages(testQueue)) == 0
+	})
+}
+
+func TestWorkersKeepEventsOfAKeyInOrder(t *testing.T) {
+	broker := rabbitmqtest.NewBroker()
+	handler := newOrderingHandler(5 * time.Millisecond)
+	startWorkers(t, broker, handler, 4)
+
+	// Interleave runs of events of several games and teams, so several events of a key are in flight at once
+	const perKey, run = 20, 4
+	keys := []map[string]interface{}{{"game_id": 1}, {"game_id": 2}, {"game_id": 3}, {"team_id": 1}, {"team_id": 2}}
+	for first := 0; first < perKey; first += run {
+		for i, key := range keys {
+			for seq := first; seq < first+run; seq++ {
+				fields := map[string]interface{}{"seq": seq, "event_id": fmt.Sprintf("evt-%d-%d", i, seq)}
+				for k, v := range key {
+					fields[k] = v
+				}
+				publishKeyedEvent(t, broker, fields)
+			}
+		}
+	}
+	waitSettled(t, broker, handler, perKey*len(keys))
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	if len(handler.handled) != len(keys) {
+		t.Fatalf("handled %d keys, want %d: %v", len(handler.handled), len(keys), handler.handled)
+	}
+	for key, seqs := range handler.handled {
+		for i, seq := range seqs {
+			if seq != i {
+				t.Errorf("%s handled in order %v, want 0..%d", key, seqs, perKey-1)
+				break
+			}
+		}
+	}
+}
+
+func TestWorkersHandleDifferentKeysConcurrently(t *testing.T) {
+	const workers = 4
+	broker := rabbitmqtest.NewBroker()
+	handler := newOrderingHandler(20 * time.Millisecond)
+	startWorkers(t, broker, handler, workers)
+
+	for game := 1; game <= 16; game++ {
+		publishKeyedEvent(t, broker, map[string]interface{}{"game_id": game, "seq": 0, "event_id": fmt.Sprintf("evt-%d", game)})
+	}
+	waitSettled(t, broker, handler, 16)
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	if handler.maxInFlight < 2 || handler.maxInFlight > workers {
+		t.Errorf("at most %d handlers ran at once, want between 2 and %d", handler.maxInFlight, workers)
+	}
+}
+
+func TestWorkersSettleEveryDeliveryOnce(t *testing.T) {
+	broker := rabbitmqtest.NewBroker()
+	handler := newOrderingHandler(0)
+	startWorkers(t, broker, handler, 8)
+
+	const events = 200
+	failed := 0
+	for i := 0; i < events; i++ {
+		fail := i%3 == 0
+		if fail {
+			failed++
+		}
+		publishKeyedEvent(t, broker, map[string]interface{}{
+			"game_id":  i%10 + 1,
+			"seq":      i / 10,
+			"event_id": fmt.Sprintf("evt-%d", i),
+			"fail":     fail,
+		})
+	}
+	waitSettled(t, broker, handler, events)
+
+	dlq := rabbitmq.DeadLetterQueueName(testQueue)
+	waitFor(t, "rejected events in "+dlq, func() bool { return len(broker.Messages(dlq)) >= failed })
+	if n := len(broker.Messages(dlq)); n != failed {
+		t.Errorf("%s has %d messages, want %d", dlq, n, failed)
+	}
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	if len(handler.events) != events {
+		t.Errorf("handled %d distinct events, want %d", len(handler.events), events)
+	}
+	for id, calls := range handler.events {
+		if calls != 1 {
+			t.Errorf("%s handled %d times, want once", id, calls)
+		}
+	}
+}
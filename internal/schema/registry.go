@@ -0,0 +1,199 @@
+package schema
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// VersionField is the envelope field and AMQP header carrying the payload schema version.
+// Payloads without it are treated as version 1.
+const VersionField = "schema_version"
+
+//go:embed schemas/*.json
+var embedded embed.FS
+
+// schemaFileName matches embedded schema files: <event_type>.v<version>.json
+var schemaFileName = regexp.MustCompile(`^(.+)\.v([0-9]+)\.json$`)
+
+// Upgrader converts a payload of one schema version into the shape of the next version
+type Upgrader func(payload map[string]interface{}) (map[string]interface{}, error)
+
+// Registry holds the payload schemas of every event type and version, and the upgraders
+// that convert older payloads to the latest version before they are dispatched.
+type Registry struct {
+	schemas  map[string]map[int]*Schema
+	latest   map[string]int
+	upgrades map[string]map[int]Upgrader
+}
+
+// NewRegistry loads the schemas embedded in the binary and registers the built-in upgraders
+func NewRegistry() (*Registry, error) {
+	r := &Registry{
+		schemas:  make(map[string]map[int]*Schema),
+		latest:   make(map[string]int),
+		upgrades: make(map[string]map[int]Upgrader),
+	}
+
+	entries, err := fs.ReadDir(embedded, "schemas")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list embedded schemas: %w", err)
+	}
+	for _, entry := range entries {
+		match := schemaFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("unexpected schema file name %s", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[2])
+
+		data, err := embedded.ReadFile(path.Join("schemas", entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read schema %s: %w", entry.Name(), err)
+		}
+		s, err := Parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse schema %s: %w", entry.Name(), err)
+		}
+		r.add(match[1], version, s)
+	}
+
+	registerUpgrades(r)
+
+	// Every version below the latest needs a way forward
+	for eventType, latest := range r.latest {
+		for v := 1; v < latest; v++ {
+			if r.upgrades[eventType][v] == nil {
+				return nil, fmt.Errorf("schema %s has version %d but no upgrade from v%d", eventType, latest, v)
+			}
+		}
+	}
+	return r, nil
+}
+
+// add registers the schema of an event type version
+func (r *Registry) add(eventType string, version int, s *Schema) {
+	if r.schemas[eventType] == nil {
+		r.schemas[eventType] = make(map[int]*Schema)
+	}
+	r.schemas[eventType][version] = s
+	r.latest[eventType] = max(r.latest[eventType], version)
+}
+
+// RegisterUpgrade registers the upgrader from version from to from+1 of an event type
+func (r *Registry) RegisterUpgrade(eventType string, from int, upgrade Upgrader) {
+	if r.upgrades[eventType] == nil {
+		r.upgrades[eventType] = make(map[int]Upgrader)
+	}
+	r.upgrades[eventType][from] = upgrade
+}
+
+// Has reports whether a schema is registered for eventType
+func (r *Registry) Has(eventType string) bool {
+	return r.latest[eventType] > 0
+}
+
+// Latest returns the latest schema version of eventType, or 0 if it has no schema
+func (r *Registry) Latest(eventType string) int {
+	return r.latest[eventType]
+}
+
+// Prepare validates a payload of the given schema version and upgrades it to the latest version.
+// A version of 0 means the payload did not declare one and is treated as version 1.
+// The payload is validated against its own version first, so reports refer to the fields the
+// producer sent, and the upgraded payload is validated again against the latest version.
+// Event types without a schema are returned unchanged. Failures are returned as *ValidationError.
+func (r *Registry) Prepare(eventType string, version int, payload map[string]interface{}) (map[string]interface{}, error) {
+	latest := r.latest[eventType]
+	if latest == 0 {
+		return payload, nil
+	}
+	if version == 0 {
+		version = 1
+	}
+
+	s, ok := r.schemas[eventType][version]
+	if !ok {
+		return nil, &ValidationError{
+			EventType: eventType,
+			Version:   version,
+			Violations: []Violation{{
+				Path:    "/" + VersionField,
+				Message: fmt.Sprintf("unsupported schema version %d (latest is %d)", version, latest),
+			}},
+		}
+	}
+	if violations := s.Validate(payload); len(violations) > 0 {
+		return nil, &ValidationError{EventType: eventType, Version: version, Violations: violations}
+	}
+	if version == latest {
+		return payload, nil
+	}
+
+	upgraded := payload
+	for v := version; v < latest; v++ {
+		var err error
+		upgraded, err = r.upgrades[eventType][v](upgraded)
+		if err != nil {
+			return nil, &ValidationError{
+				EventType:  eventType,
+				Version:    version,
+				Violations: []Violation{{Path: "", Message: fmt.Sprintf("cannot upgrade from v%d: %v", v, err)}},
+			}
+		}
+	}
+	upgraded[VersionField] = latest
+
+	if violations := r.schemas[eventType][latest].Validate(upgraded); len(violations) > 0 {
+		return nil, &ValidationError{EventType: eventType, Version: version, UpgradedTo: latest, Violations: violations}
+	}
+	return upgraded, nil
+}
+
+// ValidationError reports why a payload does not match its schema
+type ValidationError struct {
+	EventType  string      `json:"event_type"`
+	Version    int         `json:"schema_version"`
+	UpgradedTo int         `json:"upgraded_to,omitempty"` // set if the payload failed after being upgraded
+	Violations []Violation `json:"violations"`
+}
+
+// Error lists every violation
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		parts[i] = v.String()
+	}
+	schemaName := fmt.Sprintf("%s v%d", e.EventType, e.Version)
+	if e.UpgradedTo > 0 {
+		schemaName += fmt.Sprintf(" (upgraded to v%d)", e.UpgradedTo)
+	}
+	return fmt.Sprintf("payload does not match schema %s: %s", schemaName, strings.Join(parts, "; "))
+}
+
+// ParseVersion parses a schema version from an AMQP header or JSON field value.
+// It returns 0 if the value is absent.
+func ParseVersion(value interface{}) (int, error) {
+	switch v := value.(type) {
+	case nil:
+		return 0, nil
+	case string:
+		if v == "" {
+			return 0, nil
+		}
+		n, err := strconv.Atoi(strings.TrimPrefix(v, "v"))
+		if err != nil || n < 1 {
+			return 0, fmt.Errorf("invalid %s %q", VersionField, v)
+		}
+		return n, nil
+	default:
+		n, ok := toFloat(value)
+		if !ok || n < 1 || n != float64(int(n)) {
+			return 0, fmt.Errorf("invalid %s %v", VersionField, value)
+		}
+		return int(n), nil
+	}
+}
@@ -0,0 +1,176 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    int
+		wantErr bool
+	}{
+		{"absent", nil, 0, false},
+		{"empty string", "", 0, false},
+		{"string", "2", 2, false},
+		{"prefixed string", "v2", 2, false},
+		{"JSON number", float64(2), 2, false},
+		{"AMQP integer header", int32(3), 3, false},
+		{"json.Number", json.Number("1"), 1, false},
+		{"zero", float64(0), 0, true},
+		{"negative", -1, 0, true},
+		{"zero string", "0", 0, true},
+		{"fraction", 1.5, 0, true},
+		{"not a number", "latest", 0, true},
+		{"bare prefix", "v", 0, true},
+		{"boolean", true, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseVersion(tt.value)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("ParseVersion(%#v) = %d, %v; want %d, error %v", tt.value, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewRegistryLoadsEmbeddedSchemas(t *testing.T) {
+	r, err := NewRegistry()
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+
+	tests := []struct {
+		eventType string
+		latest    int
+	}{
+		{"game.scheduled", 2},
+		{"game.finished", 2},
+		{"team.invite.sent", 1},
+		{"SEND_MESSAGE", 1},
+		{"game.paused", 0},
+	}
+	for _, tt := range tests {
+		if got := r.Latest(tt.eventType); got != tt.latest {
+			t.Errorf("Latest(%s) = %d, want %d", tt.eventType, got, tt.latest)
+		}
+		if got := r.Has(tt.eventType); got != (tt.latest > 0) {
+			t.Errorf("Has(%s) = %v", tt.eventType, got)
+		}
+	}
+}
+
+func TestPrepare(t *testing.T) {
+	r, err := NewRegistry()
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+
+	const (
+		v1Payload = `{"game_id": 7, "discord_text_channel_id": "1", "data": {"contest_title": "Cup", "detected_count": "3", "required_count": 10}}`
+		upgraded  = `{"game_id": 7, "discord_text_channel_id": "1", "schema_version": 2, "data": {"game_name": "Cup", "detected_count": 3, "required_count": 10}}`
+	)
+
+	tests := []struct {
+		name      string
+		eventType string
+		version   int
+		payload   string
+		want      string   // expected payload; empty if Prepare fails
+		wantErr   []string // expected violations
+		upgraded  int      // expected ValidationError.UpgradedTo
+	}{
+		{"v1 upgraded to v2", "game.match.detecting", 1, v1Payload, upgraded, nil, 0},
+		{"undeclared version treated as v1", "game.match.detecting", 0, v1Payload, upgraded, nil, 0},
+		{"latest version unchanged", "game.match.detecting", 2, upgraded, upgraded, nil, 0},
+		{"event type without schema unchanged", "game.paused", 5, `{"game_id": "x"}`, `{"game_id": "x"}`, nil, 0},
+		{
+			"unsupported version", "game.match.detecting", 3, upgraded, "",
+			[]string{"/schema_version: unsupported schema version 3 (latest is 2)"}, 0,
+		},
+		{
+			"invalid v1 payload reported against v1", "game.match.detecting", 1, `{"discord_text_channel_id": "1"}`, "",
+			[]string{"/game_id: is required"}, 0,
+		},
+		{
+			"invalid after upgrade", "game.match.detecting", 1, `{"game_id": 7, "discord_text_channel_id": "1", "data": {"detected_count": "many"}}`, "",
+			[]string{"/data/detected_count: expected integer, got string"}, 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := decode(t, tt.payload).(map[string]interface{})
+			got, err := r.Prepare(tt.eventType, tt.version, payload)
+
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("Prepare: %v", err)
+				}
+				if want := decode(t, tt.want); !reflect.DeepEqual(normalize(t, got), want) {
+					t.Errorf("Prepare = %v, want %v", got, want)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Prepare error = %v, want a *ValidationError", err)
+			}
+			var violations []string
+			for _, v := range validationErr.Violations {
+				violations = append(violations, v.String())
+			}
+			if !reflect.DeepEqual(violations, tt.wantErr) || validationErr.UpgradedTo != tt.upgraded {
+				t.Errorf("violations = %q upgraded to %d, want %q upgraded to %d", violations, validationErr.UpgradedTo, tt.wantErr, tt.upgraded)
+			}
+		})
+	}
+}
+
+func TestPrepareDoesNotModifyPayload(t *testing.T) {
+	r, err := NewRegistry()
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	document := `{"game_id": 7, "discord_text_channel_id": "1", "data": {"start_time": "2026-01-01T00:00:00Z"}}`
+	payload := decode(t, document).(map[string]interface{})
+
+	if _, err := r.Prepare("game.scheduled", 1, payload); err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+	if want := decode(t, document); !reflect.DeepEqual(payload, want) {
+		t.Errorf("payload after Prepare = %v, want %v", payload, want)
+	}
+}
+
+func TestValidationErrorMessage(t *testing.T) {
+	err := &ValidationError{
+		EventType:  "game.finished",
+		Version:    1,
+		UpgradedTo: 2,
+		Violations: []Violation{{Path: "/data/result", Message: "expected string, got integer"}, {Path: "", Message: "expected object, got array"}},
+	}
+	want := "payload does not match schema game.finished v1 (upgraded to v2): /data/result: expected string, got integer; /: expected object, got array"
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if strings.Contains((&ValidationError{EventType: "x", Version: 1}).Error(), "upgraded") {
+		t.Error("Error() mentions an upgrade that did not happen")
+	}
+}
+
+// normalize round-trips a payload through JSON so upgraded integers compare equal to decoded ones
+func normalize(t *testing.T, payload map[string]interface{}) interface{} {
+	t.Helper()
+	data, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return decode(t, string(data))
+}
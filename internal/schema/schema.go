@@ -0,0 +1,271 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Schema is the subset of JSON Schema used for event payloads: type, properties, required,
+// items, enum, minLength, pattern, minimum and minItems. Unknown keywords are rejected when
+// a schema is parsed, so a schema cannot silently rely on a keyword that is not enforced.
+type Schema struct {
+	Meta        string             `json:"$schema,omitempty"`
+	ID          string             `json:"$id,omitempty"`
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Type        Types              `json:"type,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Enum        []interface{}      `json:"enum,omitempty"`
+	MinLength   *int               `json:"minLength,omitempty"`
+	Pattern     string             `json:"pattern,omitempty"`
+	Minimum     *float64           `json:"minimum,omitempty"`
+	MinItems    *int               `json:"minItems,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// Types is the "type" keyword, written either as a single type name or as a list
+type Types []string
+
+// UnmarshalJSON accepts "string" as well as ["string", "null"]
+func (t *Types) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = Types{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("type must be a string or a list of strings: %w", err)
+	}
+	*t = list
+	return nil
+}
+
+// knownTypes are the JSON Schema type names understood by the validator
+var knownTypes = []string{"object", "array", "string", "integer", "number", "boolean", "null"}
+
+// Parse decodes a schema document and compiles its patterns
+func Parse(data []byte) (*Schema, error) {
+	var s Schema
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&s); err != nil {
+		return nil, err
+	}
+	if err := s.compile(""); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// compile checks type names and compiles patterns of s and its subschemas
+func (s *Schema) compile(path string) error {
+	for _, t := range s.Type {
+		if !slices.Contains(knownTypes, t) {
+			return fmt.Errorf("%s: unknown type %q", displayPath(path), t)
+		}
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("%s: invalid pattern: %w", displayPath(path), err)
+		}
+		s.pattern = re
+	}
+	for name, prop := range s.Properties {
+		if err := prop.compile(path + "/" + name); err != nil {
+			return err
+		}
+	}
+	if s.Items != nil {
+		return s.Items.compile(path + "/items")
+	}
+	return nil
+}
+
+// Violation is a single validation failure. Path is a JSON Pointer to the offending value.
+type Violation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// String formats the violation as "path: message"
+func (v Violation) String() string {
+	return displayPath(v.Path) + ": " + v.Message
+}
+
+// Validate checks value against the schema and returns every violation found.
+// value is a decoded JSON document; Go integer types are accepted as JSON numbers.
+func (s *Schema) Validate(value interface{}) []Violation {
+	var violations []Violation
+	s.validate("", value, &violations)
+	return violations
+}
+
+// validate appends the violations of value at path
+func (s *Schema) validate(path string, value interface{}, violations *[]Violation) {
+	report := func(format string, args ...interface{}) {
+		*violations = append(*violations, Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(s.Type) > 0 && !slices.ContainsFunc(s.Type, func(t string) bool { return hasType(value, t) }) {
+		report("expected %s, got %s", strings.Join(s.Type, " or "), typeName(value))
+		return
+	}
+
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(e interface{}) bool { return equal(e, value) }) {
+		report("must be one of %s", formatEnum(s.Enum))
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				*violations = append(*violations, Violation{Path: path + "/" + name, Message: "is required"})
+			}
+		}
+		for _, name := range sortedKeys(s.Properties) {
+			if prop, ok := v[name]; ok {
+				s.Properties[name].validate(path+"/"+name, prop, violations)
+			}
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			report("must contain at least %d item(s)", *s.MinItems)
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(fmt.Sprintf("%s/%d", path, i), item, violations)
+			}
+		}
+	case string:
+		if s.MinLength != nil && len([]rune(v)) < *s.MinLength {
+			if *s.MinLength == 1 {
+				report("must not be empty")
+			} else {
+				report("must be at least %d characters long", *s.MinLength)
+			}
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			report("must match pattern %s", s.Pattern)
+		}
+	default:
+		if n, ok := toFloat(value); ok && s.Minimum != nil && n < *s.Minimum {
+			report("must be at least %v", *s.Minimum)
+		}
+	}
+}
+
+// hasType reports whether value is of the JSON Schema type t
+func hasType(value interface{}, t string) bool {
+	switch t {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	case "number":
+		_, ok := toFloat(value)
+		return ok
+	case "integer":
+		n, ok := toFloat(value)
+		return ok && n == math.Trunc(n)
+	default:
+		return false
+	}
+}
+
+// typeName returns the JSON type name of value for error messages
+func typeName(value interface{}) string {
+	for _, t := range []string{"null", "object", "array", "string", "boolean", "integer", "number"} {
+		if hasType(value, t) {
+			return t
+		}
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// toFloat converts a JSON number, or a Go integer placed in a payload map, to float64
+func toFloat(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// equal compares an enum value with a payload value
+func equal(a, b interface{}) bool {
+	if x, ok := toFloat(a); ok {
+		y, ok := toFloat(b)
+		return ok && x == y
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// formatEnum formats enum values as a JSON list
+func formatEnum(values []interface{}) string {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Sprint(values)
+	}
+	return string(data)
+}
+
+// sortedKeys returns the property names in a stable order so reports are deterministic
+func sortedKeys(properties map[string]*Schema) []string {
+	keys := make([]string, 0, len(properties))
+	for k := range properties {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// displayPath returns path, or "/" for the document root
+func displayPath(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return path
+}
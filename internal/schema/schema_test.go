@@ -0,0 +1,108 @@
+package schema
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+const testSchema = `{
+	"type": "object",
+	"properties": {
+		"game_id": {"type": "integer", "minimum": 1},
+		"status": {"type": "string", "enum": ["ACTIVE", "FINISHED"]},
+		"channel_id": {"type": "string", "minLength": 1, "pattern": "^[0-9]+$"},
+		"note": {"type": ["string", "null"]},
+		"team": {
+			"type": "object",
+			"properties": {
+				"name": {"type": "string", "minLength": 3},
+				"members": {"type": "array", "minItems": 1, "items": {"type": "integer"}}
+			},
+			"required": ["name"]
+		}
+	},
+	"required": ["game_id", "channel_id"]
+}`
+
+// decode decodes a JSON document the way payloads are decoded before validation
+func decode(t *testing.T, document string) interface{} {
+	t.Helper()
+	var value interface{}
+	if err := json.Unmarshal([]byte(document), &value); err != nil {
+		t.Fatalf("invalid test document %s: %v", document, err)
+	}
+	return value
+}
+
+func TestValidate(t *testing.T) {
+	s, err := Parse([]byte(testSchema))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		payload string
+		want    []string
+	}{
+		{"valid", `{"game_id": 1, "channel_id": "123", "status": "ACTIVE", "note": null, "team": {"name": "abc", "members": [1, 2]}}`, nil},
+		{"missing required fields", `{}`, []string{"/game_id: is required", "/channel_id: is required"}},
+		{"string instead of integer", `{"game_id": "1", "channel_id": "1"}`, []string{"/game_id: expected integer, got string"}},
+		{"fraction instead of integer", `{"game_id": 1.5, "channel_id": "1"}`, []string{"/game_id: expected integer, got number"}},
+		{"below minimum", `{"game_id": 0, "channel_id": "1"}`, []string{"/game_id: must be at least 1"}},
+		{"not in enum", `{"game_id": 1, "channel_id": "1", "status": "PAUSED"}`, []string{`/status: must be one of ["ACTIVE","FINISHED"]`}},
+		{"empty string", `{"game_id": 1, "channel_id": ""}`, []string{"/channel_id: must not be empty", "/channel_id: must match pattern ^[0-9]+$"}},
+		{"pattern mismatch", `{"game_id": 1, "channel_id": "general"}`, []string{"/channel_id: must match pattern ^[0-9]+$"}},
+		{"none of several types", `{"game_id": 1, "channel_id": "1", "note": 5}`, []string{"/note: expected string or null, got integer"}},
+		{"nested required field", `{"game_id": 1, "channel_id": "1", "team": {}}`, []string{"/team/name: is required"}},
+		{"nested constraints", `{"game_id": 1, "channel_id": "1", "team": {"name": "ab", "members": []}}`, []string{"/team/members: must contain at least 1 item(s)", "/team/name: must be at least 3 characters long"}},
+		{"array item type", `{"game_id": 1, "channel_id": "1", "team": {"name": "abc", "members": [1, "2"]}}`, []string{"/team/members/1: expected integer, got string"}},
+		{"document of the wrong type", `[]`, []string{"/: expected object, got array"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, v := range s.Validate(decode(t, tt.payload)) {
+				got = append(got, v.String())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Validate(%s) = %q, want %q", tt.payload, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateAcceptsGoIntegers(t *testing.T) {
+	s, err := Parse([]byte(testSchema))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	payload := map[string]interface{}{
+		"game_id":    int64(7),
+		"channel_id": "1",
+		"team":       map[string]interface{}{"name": "abc", "members": []interface{}{1, int32(2)}},
+	}
+	if violations := s.Validate(payload); len(violations) > 0 {
+		t.Errorf("Validate = %v, want no violations", violations)
+	}
+}
+
+func TestParseRejectsInvalidSchemas(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+	}{
+		{"unknown keyword", `{"type": "object", "maxLength": 3}`},
+		{"unknown type", `{"type": "object", "properties": {"id": {"type": "uuid"}}}`},
+		{"invalid pattern", `{"type": "object", "properties": {"id": {"type": "string", "pattern": "("}}}`},
+		{"type of the wrong kind", `{"type": 1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tt.schema)); err == nil {
+				t.Errorf("Parse(%s) succeeded, want an error", tt.schema)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package schema
+
+import "strconv"
+
+// gameEventTypes are the game lifecycle events whose data moved to fixed keys in v2
+var gameEventTypes = []string{
+	"game.scheduled",
+	"game.activated",
+	"game.match.detecting",
+	"game.match.detected",
+	"game.match.failed",
+	"game.finished",
+}
+
+// gameDataAliases maps the v1 alias of a game data field to its v2 name
+var gameDataAliases = map[string]string{
+	"contest_title":  "game_name",
+	"start_time":     "scheduled_at",
+	"failure_reason": "reason",
+	"winner":         "winner_team_name",
+	"score":          "result",
+}
+
+// gameDataCounts are game data fields that v1 allowed as numeric strings and v2 requires as integers
+var gameDataCounts = []string{"detected_count", "required_count"}
+
+// registerUpgrades registers the built-in upgraders
+func registerUpgrades(r *Registry) {
+	for _, eventType := range gameEventTypes {
+		r.RegisterUpgrade(eventType, 1, upgradeGameEventV1)
+	}
+}
+
+// upgradeGameEventV1 converts a v1 game event to v2. v1 data was free-form: several fields had
+// aliases and counts could be strings. v2 uses a single name per field and integer counts.
+func upgradeGameEventV1(payload map[string]interface{}) (map[string]interface{}, error) {
+	upgraded := make(map[string]interface{}, len(payload))
+	for k, v := range payload {
+		upgraded[k] = v
+	}
+
+	// data is required in v2
+	source, _ := payload["data"].(map[string]interface{})
+	data := make(map[string]interface{}, len(source))
+	for k, v := range source {
+		if _, ok := gameDataAliases[k]; !ok {
+			data[k] = v
+		}
+	}
+	// Like the v1 handlers, an alias is only used when the v2 name is missing or empty
+	for alias, name := range gameDataAliases {
+		v, ok := source[alias]
+		if !ok {
+			continue
+		}
+		if current, exists := data[name]; !exists || current == "" {
+			data[name] = v
+		}
+	}
+
+	for _, key := range gameDataCounts {
+		s, ok := data[key].(string)
+		if !ok {
+			continue
+		}
+		// Non-numeric strings are left as they are and reported by the v2 schema
+		if n, err := strconv.Atoi(s); err == nil {
+			data[key] = float64(n)
+		}
+	}
+
+	upgraded["data"] = data
+	return upgraded, nil
+}
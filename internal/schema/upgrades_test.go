@@ -0,0 +1,61 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUpgradeGameEventV1(t *testing.T) {
+	tests := []struct {
+		name string
+		data interface{}
+		want map[string]interface{}
+	}{
+		{"no data", nil, map[string]interface{}{}},
+		{
+			"aliases renamed",
+			map[string]interface{}{"contest_title": "Cup", "start_time": "2026-01-01T00:00:00Z", "failure_reason": "timeout", "winner": "A", "score": "2-1"},
+			map[string]interface{}{"game_name": "Cup", "scheduled_at": "2026-01-01T00:00:00Z", "reason": "timeout", "winner_team_name": "A", "result": "2-1"},
+		},
+		{
+			"v2 name wins over its alias",
+			map[string]interface{}{"game_name": "Final", "contest_title": "Cup"},
+			map[string]interface{}{"game_name": "Final"},
+		},
+		{
+			"empty v2 name replaced by its alias",
+			map[string]interface{}{"game_name": "", "contest_title": "Cup"},
+			map[string]interface{}{"game_name": "Cup"},
+		},
+		{
+			"numeric counts converted",
+			map[string]interface{}{"detected_count": "3", "required_count": float64(10)},
+			map[string]interface{}{"detected_count": float64(3), "required_count": float64(10)},
+		},
+		{
+			"non-numeric count left for the schema to report",
+			map[string]interface{}{"detected_count": "many"},
+			map[string]interface{}{"detected_count": "many"},
+		},
+		{
+			"other fields kept",
+			map[string]interface{}{"match_id": "m-1", "teams": []interface{}{"A", "B"}},
+			map[string]interface{}{"match_id": "m-1", "teams": []interface{}{"A", "B"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := map[string]interface{}{"game_id": float64(7), "data": tt.data}
+			got, err := upgradeGameEventV1(payload)
+			if err != nil {
+				t.Fatalf("upgradeGameEventV1: %v", err)
+			}
+			if got["game_id"] != float64(7) {
+				t.Errorf("game_id = %v, want it kept", got["game_id"])
+			}
+			if !reflect.DeepEqual(got["data"], tt.want) {
+				t.Errorf("data = %v, want %v", got["data"], tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/gamers-bot/internal/bot"
+	"github.com/gamers-bot/internal/rabbitmq"
+)
+
+// Status is the JSON document served at /status
+type Status struct {
+	Ready    bool            `json:"ready"`
+	Discord  DiscordStatus   `json:"discord"`
+	RabbitMQ *RabbitMQStatus `json:"rabbitmq,omitempty"` // nil when RabbitMQ is not configured
+}
+
+// DiscordStatus is the state of the Discord gateway connection
+type DiscordStatus struct {
+	Ready            bool               `json:"ready"`
+	GatewayLatencyMS int64              `json:"gateway_latency_ms"`
+	Guilds           []bot.GuildSummary `json:"guilds"`
+}
+
+// RabbitMQStatus is the state of the RabbitMQ connection and its consumers
+type RabbitMQStatus struct {
+	rabbitmq.Status
+	ConsumersRunning bool   `json:"consumers_running"`
+	ReplicaRole      string `json:"replica_role,omitempty"` // "active" or "standby" in HA mode
+}
+
+// NewStatus returns the status of the bot and whether it is ready. The bot is ready once the
+// Discord gateway is up and, with RabbitMQ configured, every consumer is attached. A standby
+// replica in HA mode does not consume by design and is ready while it is connected.
+// rmq is nil when RabbitMQ is not configured.
+func NewStatus(discord DiscordStatus, rmq *RabbitMQStatus) Status {
+	status := Status{Ready: discord.Ready, Discord: discord}
+	if rmq != nil {
+		rmq.ConsumersRunning = rmq.Status.ConsumersRunning()
+		standby := rmq.ReplicaRole == string(rabbitmq.RoleStandby) && rmq.Connected
+		status.Ready = status.Ready && (rmq.ConsumersRunning || standby)
+		status.RabbitMQ = rmq
+	}
+	return status
+}
+
+// HandleHealth registers the health endpoints. status is called on every request:
+//   - /healthz always succeeds while the process serves HTTP
+//   - /readyz succeeds while Status.Ready is true and fails with 503 otherwise
+//   - /status returns the Status as JSON
+func (s *Server) HandleHealth(status func() Status) {
+	s.Handle("GET /healthz", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	}))
+
+	s.Handle("GET /readyz", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if !status().Ready {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "not ready"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ready"})
+	}))
+
+	s.Handle("GET /status", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, status())
+	}))
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Warn("Failed to write HTTP response", "error", err)
+	}
+}
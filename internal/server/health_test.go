@@ -0,0 +1,151 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gamers-bot/internal/bot"
+	"github.com/gamers-bot/internal/rabbitmq"
+)
+
+// get serves a GET request for path and returns the response
+func get(s *Server, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	s.httpServer.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec
+}
+
+// body returns the response body without the trailing newline of the JSON encoder
+func body(rec *httptest.ResponseRecorder) string {
+	return string(bytes.TrimSpace(rec.Body.Bytes()))
+}
+
+// newHealthServer returns a server whose health endpoints report status
+func newHealthServer(status Status) *Server {
+	s := New("127.0.0.1:0")
+	s.HandleHealth(func() Status { return status })
+	return s
+}
+
+// queues returns a RabbitMQ status with one queue per state
+func queues(connected bool, states ...rabbitmq.QueueState) rabbitmq.Status {
+	status := rabbitmq.Status{Connected: connected, Queues: []rabbitmq.QueueStatus{}}
+	for i, state := range states {
+		status.Queues = append(status.Queues, rabbitmq.QueueStatus{Queue: "queue-" + string(rune('a'+i)), State: state})
+	}
+	return status
+}
+
+func TestReadiness(t *testing.T) {
+	discordUp := DiscordStatus{Ready: true}
+	discordDown := DiscordStatus{Ready: false}
+
+	tests := []struct {
+		name      string
+		discord   DiscordStatus
+		rmq       *RabbitMQStatus
+		wantReady bool
+	}{
+		{"Discord up without RabbitMQ", discordUp, nil, true},
+		{"Discord disconnected without RabbitMQ", discordDown, nil, false},
+		{"every consumer running", discordUp, &RabbitMQStatus{Status: queues(true, rabbitmq.QueueConsuming, rabbitmq.QueueConsuming)}, true},
+		{"Discord disconnected with consumers running", discordDown, &RabbitMQStatus{Status: queues(true, rabbitmq.QueueConsuming)}, false},
+		{"one consumer down", discordUp, &RabbitMQStatus{Status: queues(true, rabbitmq.QueueConsuming, rabbitmq.QueueStopped)}, false},
+		{"consumers not started yet", discordUp, &RabbitMQStatus{Status: queues(true)}, false},
+		{"RabbitMQ disconnected", discordUp, &RabbitMQStatus{Status: queues(false, rabbitmq.QueueStopped)}, false},
+		{"connected standby replica", discordUp, &RabbitMQStatus{Status: queues(true, rabbitmq.QueueStopped), ReplicaRole: "standby"}, true},
+		{"disconnected standby replica", discordUp, &RabbitMQStatus{Status: queues(false, rabbitmq.QueueStopped), ReplicaRole: "standby"}, false},
+		{"active replica with a consumer down", discordUp, &RabbitMQStatus{Status: queues(true, rabbitmq.QueueStopped), ReplicaRole: "active"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newHealthServer(NewStatus(tt.discord, tt.rmq))
+
+			wantCode, wantBody := http.StatusServiceUnavailable, `{"status":"not ready"}`
+			if tt.wantReady {
+				wantCode, wantBody = http.StatusOK, `{"status":"ready"}`
+			}
+			rec := get(s, "/readyz")
+			if rec.Code != wantCode || body(rec) != wantBody {
+				t.Errorf("/readyz = %d %s, want %d %s", rec.Code, rec.Body, wantCode, wantBody)
+			}
+
+			// Liveness does not depend on readiness
+			if rec := get(s, "/healthz"); rec.Code != http.StatusOK || body(rec) != `{"status":"ok"}` {
+				t.Errorf("/healthz = %d %s, want 200 {\"status\":\"ok\"}", rec.Code, rec.Body)
+			}
+		})
+	}
+}
+
+func TestStatusJSON(t *testing.T) {
+	lastMessage := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	discord := DiscordStatus{
+		Ready:            true,
+		GatewayLatencyMS: 42,
+		Guilds:           []bot.GuildSummary{{ID: "1", Name: "GAMERS"}},
+	}
+
+	tests := []struct {
+		name string
+		rmq  *RabbitMQStatus
+		want string
+	}{
+		{
+			"without RabbitMQ", nil,
+			`{"ready":true,"discord":{"ready":true,"gateway_latency_ms":42,"guilds":[{"id":"1","name":"GAMERS"}]}}`,
+		},
+		{
+			"with RabbitMQ",
+			&RabbitMQStatus{
+				Status: rabbitmq.Status{
+					Connected:     true,
+					Queues:        []rabbitmq.QueueStatus{{Queue: "bot.game.notifications", State: rabbitmq.QueueConsuming, LastMessageAt: &lastMessage}},
+					LastMessageAt: &lastMessage,
+				},
+				ReplicaRole: "active",
+			},
+			`{"ready":true,"discord":{"ready":true,"gateway_latency_ms":42,"guilds":[{"id":"1","name":"GAMERS"}]},` +
+				`"rabbitmq":{"connected":true,"queues":[{"queue":"bot.game.notifications","state":"consuming","last_message_at":"2026-10-01T12:00:00Z"}],` +
+				`"last_message_at":"2026-10-01T12:00:00Z","consumers_running":true,"replica_role":"active"}}`,
+		},
+		{
+			"with a RabbitMQ error",
+			&RabbitMQStatus{Status: rabbitmq.Status{Queues: []rabbitmq.QueueStatus{}, LastError: "connection refused", LastErrorAt: &lastMessage}},
+			`{"ready":false,"discord":{"ready":true,"gateway_latency_ms":42,"guilds":[{"id":"1","name":"GAMERS"}]},` +
+				`"rabbitmq":{"connected":false,"queues":[],"last_error":"connection refused","last_error_at":"2026-10-01T12:00:00Z","consumers_running":false}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := get(newHealthServer(NewStatus(discord, tt.rmq)), "/status")
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("/status = %d, want 200", rec.Code)
+			}
+			if got := rec.Header().Get("Content-Type"); got != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", got)
+			}
+			var want bytes.Buffer
+			if err := json.Compact(&want, []byte(tt.want)); err != nil {
+				t.Fatal(err)
+			}
+			if got := body(rec); got != want.String() {
+				t.Errorf("/status =\n%s\nwant\n%s", got, want.String())
+			}
+		})
+	}
+}
+
+func TestHealthEndpointsOnlyServeGET(t *testing.T) {
+	s := newHealthServer(NewStatus(DiscordStatus{Ready: true}, nil))
+	rec := httptest.NewRecorder()
+	s.httpServer.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/readyz", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST /readyz = %d, want 405", rec.Code)
+	}
+}
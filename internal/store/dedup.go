@@ -0,0 +1,23 @@
+package store
+
+import "time"
+
+// ProcessedEvent records the outcome of an event the bot has already handled
+type ProcessedEvent struct {
+	EventID     string                 `json:"event_id"`
+	EventType   string                 `json:"event_type"`
+	MessageID   string                 `json:"message_id,omitempty"`
+	Result      map[string]interface{} `json:"result,omitempty"`
+	ProcessedAt time.Time              `json:"processed_at"`
+}
+
+// DedupStore remembers processed events by event_id so redelivered messages are not handled twice.
+// Entries older than the store's retention window are treated as unseen.
+type DedupStore interface {
+	// Get returns the processed event for eventID, or false if it has not been seen within the retention window
+	Get(eventID string) (*ProcessedEvent, bool, error)
+	// Put records a processed event
+	Put(event ProcessedEvent) error
+	// Close releases resources held by the store
+	Close() error
+}
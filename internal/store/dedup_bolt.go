@@ -0,0 +1,159 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var processedEventsBucket = []byte("processed_events")
+
+// pruneInterval is how often expired entries are removed from the file-backed store
+const pruneInterval = time.Hour
+
+// BoltDedupStore is a file-backed DedupStore that survives restarts.
+// Expired entries are pruned on open and periodically afterwards.
+type BoltDedupStore struct {
+	db        *bolt.DB
+	retention time.Duration
+	stop      chan struct{}
+}
+
+// NewBoltDedupStore opens (or creates) a dedup database at path
+func NewBoltDedupStore(path string, retention time.Duration) (*BoltDedupStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create dedup store directory: %w", err)
+	}
+
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dedup store %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(processedEventsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create dedup bucket: %w", err)
+	}
+
+	s := &BoltDedupStore{
+		db:        db,
+		retention: retention,
+		stop:      make(chan struct{}),
+	}
+
+	if err := s.prune(); err != nil {
+		slog.Warn("Failed to prune dedup store", "error", err)
+	}
+	go s.pruneLoop()
+
+	return s, nil
+}
+
+// Get returns the processed event for eventID if it is still within the retention window
+func (s *BoltDedupStore) Get(eventID string) (*ProcessedEvent, bool, error) {
+	var event *ProcessedEvent
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(processedEventsBucket).Get([]byte(eventID))
+		if data == nil {
+			return nil
+		}
+		var e ProcessedEvent
+		if err := json.Unmarshal(data, &e); err != nil {
+			return fmt.Errorf("failed to unmarshal processed event %s: %w", eventID, err)
+		}
+		event = &e
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	if event == nil || time.Since(event.ProcessedAt) > s.retention {
+		return nil, false, nil
+	}
+	return event, true, nil
+}
+
+// Put records a processed event
+func (s *BoltDedupStore) Put(event ProcessedEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal processed event: %w", err)
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(processedEventsBucket).Put([]byte(event.EventID), data)
+	})
+}
+
+// Close stops pruning and closes the database
+func (s *BoltDedupStore) Close() error {
+	close(s.stop)
+	return s.db.Close()
+}
+
+// pruneLoop periodically removes expired entries until the store is closed
+func (s *BoltDedupStore) pruneLoop() {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if err := s.prune(); err != nil {
+				slog.Warn("Failed to prune dedup store", "error", err)
+			}
+		}
+	}
+}
+
+// prune deletes entries older than the retention window
+func (s *BoltDedupStore) prune() error {
+	cutoff := time.Now().Add(-s.retention)
+	pruned := 0
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(processedEventsBucket)
+
+		// Collect first: deleting while iterating with a cursor skips entries
+		var expired [][]byte
+		err := bucket.ForEach(func(k, v []byte) error {
+			var e ProcessedEvent
+			if err := json.Unmarshal(v, &e); err != nil || e.ProcessedAt.Before(cutoff) {
+				expired = append(expired, append([]byte(nil), k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, k := range expired {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		pruned = len(expired)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if pruned > 0 {
+		slog.Info("Pruned expired dedup entries", "count", pruned)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// countProcessedEvents returns the number of entries stored, expired or not
+func countProcessedEvents(t *testing.T, s *BoltDedupStore) int {
+	t.Helper()
+	n := 0
+	err := s.db.View(func(tx *bolt.Tx) error {
+		n = tx.Bucket(processedEventsBucket).Stats().KeyN
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestBoltDedupStoreSurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dedup", "dedup.db")
+	s, err := NewBoltDedupStore(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := ProcessedEvent{
+		EventID:     "e1",
+		EventType:   "SEND_MESSAGE",
+		MessageID:   "m1",
+		Result:      map[string]interface{}{"message_id": "123"},
+		ProcessedAt: time.Now(),
+	}
+	if err := s.Put(event); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = NewBoltDedupStore(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	got, found, err := s.Get("e1")
+	if err != nil || !found {
+		t.Fatalf("Get(e1) after reopen = found %v, err %v; want it found", found, err)
+	}
+	if got.EventType != event.EventType || got.MessageID != event.MessageID || got.Result["message_id"] != "123" {
+		t.Errorf("Get(e1) after reopen = %+v, want %+v", got, event)
+	}
+	if _, found, _ := s.Get("e2"); found {
+		t.Error("Get(e2) found an event that was never stored")
+	}
+}
+
+func TestBoltDedupStoreExpiresEntries(t *testing.T) {
+	s, err := NewBoltDedupStore(filepath.Join(t.TempDir(), "dedup.db"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	for _, e := range []ProcessedEvent{
+		{EventID: "expired", ProcessedAt: time.Now().Add(-2 * time.Hour)},
+		{EventID: "recent", ProcessedAt: time.Now().Add(-30 * time.Minute)},
+	} {
+		if err := s.Put(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Expired entries are unseen even before they are pruned
+	if _, found, err := s.Get("expired"); err != nil || found {
+		t.Errorf("Get(expired) = found %v, err %v; want it treated as unseen", found, err)
+	}
+	if _, found, err := s.Get("recent"); err != nil || !found {
+		t.Errorf("Get(recent) = found %v, err %v; want it found", found, err)
+	}
+}
+
+func TestBoltDedupStorePrunesExpiredEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dedup.db")
+	s, err := NewBoltDedupStore(path, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range []ProcessedEvent{
+		{EventID: "expired", ProcessedAt: time.Now().Add(-48 * time.Hour)},
+		{EventID: "recent", ProcessedAt: time.Now()},
+	} {
+		if err := s.Put(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.prune(); err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+
+	if n := countProcessedEvents(t, s); n != 1 {
+		t.Errorf("store holds %d entries after prune, want 1", n)
+	}
+	if _, found, err := s.Get("recent"); err != nil || !found {
+		t.Errorf("Get(recent) = found %v, err %v; want the recent entry kept", found, err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Entries that expired while the bot was down are pruned on open
+	s, err = NewBoltDedupStore(path, time.Nanosecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if n := countProcessedEvents(t, s); n != 0 {
+		t.Errorf("store holds %d entries after reopen, want them pruned on open", n)
+	}
+}
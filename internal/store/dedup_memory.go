@@ -0,0 +1,75 @@
+package store
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// MemoryDedupStore is an in-memory LRU DedupStore. It holds at most capacity entries
+// and forgets everything on restart.
+type MemoryDedupStore struct {
+	mu        sync.Mutex
+	capacity  int
+	retention time.Duration
+	order     *list.List // front = most recently used
+	entries   map[string]*list.Element
+}
+
+// NewMemoryDedupStore creates a new MemoryDedupStore
+func NewMemoryDedupStore(capacity int, retention time.Duration) *MemoryDedupStore {
+	return &MemoryDedupStore{
+		capacity:  capacity,
+		retention: retention,
+		order:     list.New(),
+		entries:   make(map[string]*list.Element),
+	}
+}
+
+// Get returns the processed event for eventID if it is still within the retention window
+func (s *MemoryDedupStore) Get(eventID string) (*ProcessedEvent, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.entries[eventID]
+	if !ok {
+		return nil, false, nil
+	}
+
+	event := elem.Value.(ProcessedEvent)
+	if time.Since(event.ProcessedAt) > s.retention {
+		s.order.Remove(elem)
+		delete(s.entries, eventID)
+		return nil, false, nil
+	}
+
+	s.order.MoveToFront(elem)
+	return &event, true, nil
+}
+
+// Put records a processed event, evicting the least recently used entry when full
+func (s *MemoryDedupStore) Put(event ProcessedEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.entries[event.EventID]; ok {
+		elem.Value = event
+		s.order.MoveToFront(elem)
+		return nil
+	}
+
+	s.entries[event.EventID] = s.order.PushFront(event)
+
+	for s.order.Len() > s.capacity {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(ProcessedEvent).EventID)
+	}
+
+	return nil
+}
+
+// Close is a no-op for the in-memory store
+func (s *MemoryDedupStore) Close() error {
+	return nil
+}
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMemoryDedupStoreEvictsLeastRecentlyUsed(t *testing.T) {
+	s := NewMemoryDedupStore(3, time.Hour)
+	for i := 1; i <= 3; i++ {
+		if err := s.Put(ProcessedEvent{EventID: fmt.Sprintf("e%d", i), ProcessedAt: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Reading e1 makes e2 the least recently used entry
+	if _, found, _ := s.Get("e1"); !found {
+		t.Fatal("e1 not found")
+	}
+	if err := s.Put(ProcessedEvent{EventID: "e4", ProcessedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	for id, want := range map[string]bool{"e1": true, "e2": false, "e3": true, "e4": true} {
+		if _, found, err := s.Get(id); err != nil || found != want {
+			t.Errorf("Get(%s) = found %v, err %v; want found %v", id, found, err, want)
+		}
+	}
+	if n := s.order.Len(); n != 3 {
+		t.Errorf("store holds %d entries, want the capacity of 3", n)
+	}
+}
+
+func TestMemoryDedupStoreUpdatesExistingEntry(t *testing.T) {
+	s := NewMemoryDedupStore(2, time.Hour)
+	for _, e := range []ProcessedEvent{
+		{EventID: "e1", MessageID: "m1", ProcessedAt: time.Now()},
+		{EventID: "e2", ProcessedAt: time.Now()},
+		{EventID: "e1", MessageID: "m2", ProcessedAt: time.Now()},
+		{EventID: "e3", ProcessedAt: time.Now()},
+	} {
+		if err := s.Put(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Putting e1 again refreshed it, so e2 was evicted for e3
+	e, found, err := s.Get("e1")
+	if err != nil || !found || e.MessageID != "m2" {
+		t.Errorf("Get(e1) = %+v, found %v, err %v; want the updated entry", e, found, err)
+	}
+	if _, found, _ := s.Get("e2"); found {
+		t.Error("e2 not evicted")
+	}
+}
+
+func TestMemoryDedupStoreExpiresEntries(t *testing.T) {
+	s := NewMemoryDedupStore(10, time.Hour)
+	for _, e := range []ProcessedEvent{
+		{EventID: "expired", ProcessedAt: time.Now().Add(-2 * time.Hour)},
+		{EventID: "recent", ProcessedAt: time.Now().Add(-30 * time.Minute)},
+	} {
+		if err := s.Put(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, found, err := s.Get("expired"); err != nil || found {
+		t.Errorf("Get(expired) = found %v, err %v; want it treated as unseen", found, err)
+	}
+	if _, ok := s.entries["expired"]; ok {
+		t.Error("expired entry still held after Get")
+	}
+	if _, found, err := s.Get("recent"); err != nil || !found {
+		t.Errorf("Get(recent) = found %v, err %v; want it found", found, err)
+	}
+}
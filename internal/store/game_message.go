@@ -0,0 +1,27 @@
+package store
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// GameMessage records the living status message posted for a game
+type GameMessage struct {
+	GameID    int64           `json:"game_id"`
+	ChannelID string          `json:"channel_id"`
+	MessageID string          `json:"message_id"`
+	Stage     string          `json:"stage"`
+	State     json.RawMessage `json:"state,omitempty"` // last rendered game state, owned by the caller
+	EventAt   time.Time       `json:"event_at"`        // timestamp of the latest event applied, if it had one
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// GameMessageStore maps game IDs to their status message so it can be edited in place
+type GameMessageStore interface {
+	// Get returns the status message for a game, or false if none has been posted
+	Get(gameID int64) (*GameMessage, bool, error)
+	// Put creates or replaces the status message record for a game
+	Put(message GameMessage) error
+	// Close releases resources held by the store
+	Close() error
+}
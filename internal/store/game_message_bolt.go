@@ -0,0 +1,160 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var gameMessagesBucket = []byte("game_messages")
+
+// BoltGameMessageStore is a file-backed GameMessageStore so status messages can still be
+// edited after a restart. Records of games without events for longer than the retention
+// window are pruned on open and periodically afterwards.
+type BoltGameMessageStore struct {
+	db        *bolt.DB
+	retention time.Duration
+	stop      chan struct{}
+}
+
+// NewBoltGameMessageStore opens (or creates) a game message database at path
+func NewBoltGameMessageStore(path string, retention time.Duration) (*BoltGameMessageStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create game message store directory: %w", err)
+	}
+
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open game message store %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(gameMessagesBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create game message bucket: %w", err)
+	}
+
+	s := &BoltGameMessageStore{
+		db:        db,
+		retention: retention,
+		stop:      make(chan struct{}),
+	}
+
+	if err := s.prune(); err != nil {
+		slog.Warn("Failed to prune game message store", "error", err)
+	}
+	go s.pruneLoop()
+
+	return s, nil
+}
+
+// Get returns the status message for a game
+func (s *BoltGameMessageStore) Get(gameID int64) (*GameMessage, bool, error) {
+	var message *GameMessage
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(gameMessagesBucket).Get(gameMessageKey(gameID))
+		if data == nil {
+			return nil
+		}
+		var m GameMessage
+		if err := json.Unmarshal(data, &m); err != nil {
+			return fmt.Errorf("failed to unmarshal game message %d: %w", gameID, err)
+		}
+		message = &m
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return message, message != nil, nil
+}
+
+// Put creates or replaces the status message record for a game
+func (s *BoltGameMessageStore) Put(message GameMessage) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal game message: %w", err)
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(gameMessagesBucket).Put(gameMessageKey(message.GameID), data)
+	})
+}
+
+// Close stops pruning and closes the database
+func (s *BoltGameMessageStore) Close() error {
+	close(s.stop)
+	return s.db.Close()
+}
+
+// pruneLoop periodically removes expired records until the store is closed
+func (s *BoltGameMessageStore) pruneLoop() {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if err := s.prune(); err != nil {
+				slog.Warn("Failed to prune game message store", "error", err)
+			}
+		}
+	}
+}
+
+// prune deletes records not updated within the retention window
+func (s *BoltGameMessageStore) prune() error {
+	cutoff := time.Now().Add(-s.retention)
+	pruned := 0
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(gameMessagesBucket)
+
+		// Collect first: deleting while iterating with a cursor skips entries
+		var expired [][]byte
+		err := bucket.ForEach(func(k, v []byte) error {
+			var m GameMessage
+			if err := json.Unmarshal(v, &m); err != nil || m.UpdatedAt.Before(cutoff) {
+				expired = append(expired, append([]byte(nil), k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, k := range expired {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		pruned = len(expired)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if pruned > 0 {
+		slog.Info("Pruned expired game status messages", "count", pruned)
+	}
+	return nil
+}
+
+// gameMessageKey returns the bucket key for a game ID
+func gameMessageKey(gameID int64) []byte {
+	return []byte(strconv.FormatInt(gameID, 10))
+}
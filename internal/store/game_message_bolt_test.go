@@ -0,0 +1,50 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBoltGameMessageStorePrunesExpiredRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "games.db")
+	s, err := NewBoltGameMessageStore(path, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []GameMessage{
+		{GameID: 1, ChannelID: "c", MessageID: "m1", Stage: "FINISHED", UpdatedAt: time.Now().Add(-48 * time.Hour)},
+		{GameID: 2, ChannelID: "c", MessageID: "m2", Stage: "ACTIVATED", UpdatedAt: time.Now()},
+	} {
+		if err := s.Put(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.prune(); err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+
+	if _, found, err := s.Get(1); err != nil || found {
+		t.Errorf("Get(1) = found %v, err %v; want the expired record pruned", found, err)
+	}
+	m, found, err := s.Get(2)
+	if err != nil || !found {
+		t.Fatalf("Get(2) = found %v, err %v; want the recent record kept", found, err)
+	}
+	if m.MessageID != "m2" {
+		t.Errorf("Get(2).MessageID = %q, want m2", m.MessageID)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Records that expired while the bot was down are pruned on open
+	s, err = NewBoltGameMessageStore(path, time.Nanosecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if _, found, _ := s.Get(2); found {
+		t.Error("record not pruned on open")
+	}
+}
@@ -0,0 +1,42 @@
+package store
+
+import "sync"
+
+// MemoryGameMessageStore is an in-memory GameMessageStore. Records are lost on restart.
+type MemoryGameMessageStore struct {
+	mu       sync.RWMutex
+	messages map[int64]GameMessage
+}
+
+// NewMemoryGameMessageStore creates a new MemoryGameMessageStore
+func NewMemoryGameMessageStore() *MemoryGameMessageStore {
+	return &MemoryGameMessageStore{
+		messages: make(map[int64]GameMessage),
+	}
+}
+
+// Get returns the status message for a game
+func (s *MemoryGameMessageStore) Get(gameID int64) (*GameMessage, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	message, ok := s.messages[gameID]
+	if !ok {
+		return nil, false, nil
+	}
+	return &message, true, nil
+}
+
+// Put creates or replaces the status message record for a game
+func (s *MemoryGameMessageStore) Put(message GameMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messages[message.GameID] = message
+	return nil
+}
+
+// Close is a no-op for the in-memory store
+func (s *MemoryGameMessageStore) Close() error {
+	return nil
+}